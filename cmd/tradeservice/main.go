@@ -19,6 +19,7 @@ func main() {
 	}
 
 	sloger := logger.SetupLogger(cfg.Server.EnvType)
+	slog.SetDefault(sloger)
 
 	sloger.Info("starting TradeService")
 
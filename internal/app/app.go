@@ -4,70 +4,223 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"path/filepath"
+	"sync"
 	"time"
 	"tradeservice/internal/config"
+	"tradeservice/internal/events"
+	"tradeservice/internal/metrics"
+	apikeyshandler "tradeservice/internal/server/handler/apikeys"
 	categorieshandler "tradeservice/internal/server/handler/categories"
+	healthhandler "tradeservice/internal/server/handler/health"
+	inventoryhandler "tradeservice/internal/server/handler/inventory"
+	metricshandler "tradeservice/internal/server/handler/metrics"
+	ordershandler "tradeservice/internal/server/handler/orders"
 	productshandler "tradeservice/internal/server/handler/products"
+	webhookshandler "tradeservice/internal/server/handler/webhooks"
+	"tradeservice/internal/server/middleware"
+	"tradeservice/internal/server/policy"
 	srv "tradeservice/internal/server/server"
+	"tradeservice/internal/services/apikeys"
 	"tradeservice/internal/services/categories"
+	"tradeservice/internal/services/health"
+	"tradeservice/internal/services/inventory"
+	"tradeservice/internal/services/orders"
 	"tradeservice/internal/services/product"
+	"tradeservice/internal/services/webhooks"
 	"tradeservice/internal/storage"
+	"tradeservice/internal/storage/cache"
+	"tradeservice/internal/storage/memory"
 	"tradeservice/internal/storage/postgres"
+	"tradeservice/internal/storage/sqlite"
+	"tradeservice/internal/tracing"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 type App struct {
-	server *srv.Server
-	logger *slog.Logger
-	db     *postgres.Storage
-	cfg    *config.AppConfig
+	server          *srv.Server
+	logger          *slog.Logger
+	db              storage.SQLBackend // nil for the memory driver
+	cfg             *config.AppConfig
+	probes          *health.Probes
+	shutdownTracing func(context.Context) error
+	// startWorkers starts the background loops, e.g. the event dispatcher, and stopWorkers stops them
+	// and waits for them to return.
+	startWorkers func()
+	stopWorkers  func(ctx context.Context) error
 }
 
 func New(logger *slog.Logger, cfg *config.AppConfig) (*App, error) {
-	db, err := postgres.New(cfg.DB)
+	auth, err := middleware.JWTAuth(cfg.Auth)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't establish db connection %w", err)
+		return nil, fmt.Errorf("couldn't configure auth %w", err)
 	}
 
-	categoryStorage, err := postgres.NewCategories(db)
+	ipRateLimit, err := middleware.IPRateLimit(cfg.Server.RateLimit)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't create categories %w", err)
+		return nil, fmt.Errorf("couldn't configure rate limits %w", err)
 	}
 
-	productStorage, err := postgres.NewProducts(db)
+	rateLimit, err := middleware.RateLimit(cfg.Server.RateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't configure rate limits %w", err)
+	}
+
+	authorizer := policy.AllowAll()
+	if cfg.Auth.Enabled {
+		authorizer, err = policy.Load(cfg.Auth.PolicyFile)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't load policy %w", err)
+		}
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't configure tracing %w", err)
+	}
+
+	appMetrics := metrics.New()
+	probes := health.New(cfg.Server.HealthTimeout)
+
+	var (
+		repos  storage.Repositories
+		db     storage.SQLBackend
+		pg     *postgres.Storage
+		closer storage.Closer
+	)
+
+	switch cfg.DB.Driver {
+	case storage.DriverPostgres:
+		pgStorage, pgRepos, err := openPostgres(cfg.DB, appMetrics)
+		if err != nil {
+			return nil, err
+		}
+
+		db, pg, repos = pgStorage, pgStorage, pgRepos
+	case storage.DriverSQLite:
+		lite, liteRepos, err := openSQLite(cfg.DB, appMetrics)
+		if err != nil {
+			return nil, err
+		}
+
+		db, repos = lite, liteRepos
+	case storage.DriverMemory:
+		mem := memory.New()
+		repos = openMemory(mem)
+		closer = mem
+	default:
+		return nil, fmt.Errorf("%w %q", storage.ErrDriver, cfg.DB.Driver)
+	}
+
+	if db != nil {
+		closer = db
+
+		probes.AddCheck("database", db.Ping)
+
+		checkMigrations, err := storage.NewMigrationCheck(db, migrationPath(cfg))
+		if err != nil {
+			return nil, err
+		}
+
+		probes.AddCheck("migrations", checkMigrations)
+	}
+
+	var (
+		webhookSinks []events.Sink
+		workers      []func(context.Context)
+	)
+
+	// Webhook deliveries are queued by a sink of the dispatcher, so they share its outbox and retries.
+	if cfg.Webhooks.Enabled {
+		deliverer, err := events.NewWebhookDeliverer(repos.Webhooks, cfg.Webhooks, appMetrics.WebhooksDelivered,
+			appMetrics.WebhookDeliveryFailures)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't configure webhook deliverer %w", err)
+		}
+
+		webhookSinks = append(webhookSinks, events.NewWebhookSink(repos.Webhooks))
+		workers = append(workers, deliverer.Run)
+	}
+
+	dispatcher, err := newDispatcher(repos.Outbox, cfg.Events, logger, appMetrics, webhookSinks...)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't create products %w", err)
+		return nil, err
+	}
+
+	workers = append(workers, dispatcher.Run)
+
+	if cfg.Cache.Enabled {
+		var catalog *cache.Cache
+
+		catalog, repos, err = withCache(repos, cfg.Cache, appMetrics)
+		if err != nil {
+			return nil, err
+		}
+
+		if pg != nil {
+			workers = append(workers, purgeOnNotify(pg, catalog))
+		}
 	}
 
-	categoryManager := categories.New(categoryStorage)
-	productManager := product.New(productStorage)
+	categoryManager := categories.New(repos.Categories, repos.Products, appMetrics.CategoriesDeleted)
+	productManager := product.New(repos.Products, appMetrics.ProductsCreated)
+	inventoryManager := inventory.New(repos.Inventory)
+	orderManager := orders.New(repos.Orders, repos.Products)
+	apiKeyManager := apikeys.New(repos.APIKeys, policy.Scopes)
+	webhookManager := webhooks.New(repos.Webhooks, cfg.Webhooks.AllowPrivateHosts)
+
+	categoryHandler := categorieshandler.NewCategoriesHandler(categoryManager, authorizer)
+	productHandler := productshandler.NewProductHandler(productManager, authorizer)
+	inventoryHandler := inventoryhandler.NewInventoryHandler(inventoryManager, authorizer)
+	orderHandler := ordershandler.NewOrderHandler(orderManager, authorizer)
+	apiKeyHandler := apikeyshandler.NewAPIKeyHandler(apiKeyManager, authorizer)
+	webhookHandler := webhookshandler.NewWebhookHandler(webhookManager, authorizer)
 
-	categoryHandler := categorieshandler.NewCategoriesHandler(categoryManager, logger)
-	productHandler := productshandler.NewProductHandler(productManager, logger)
+	healthHandler := healthhandler.NewHealthHandler(probes)
+	metricsHandler := metricshandler.NewMetricsHandler(appMetrics.Handler(), authorizer)
 
-	server := srv.New(logger, &cfg.Server, db, categoryHandler, productHandler)
+	apiKeyAuth := middleware.APIKeyAuth(apiKeyManager)
+
+	startWorkers, stopWorkers := background(workers...)
+
+	server := srv.New(logger, &cfg.Server, appMetrics, ipRateLimit, apiKeyAuth, auth, rateLimit, closer,
+		categoryHandler, productHandler, inventoryHandler, orderHandler, apiKeyHandler, webhookHandler,
+		healthHandler, metricsHandler)
 
 	return &App{
-		server: server,
-		logger: logger,
-		db:     db,
-		cfg:    cfg,
+		server:          server,
+		logger:          logger,
+		db:              db,
+		cfg:             cfg,
+		probes:          probes,
+		shutdownTracing: shutdownTracing,
+		startWorkers:    startWorkers,
+		stopWorkers:     stopWorkers,
 	}, nil
 }
 
 func (a App) Run() {
 	a.logger.Info("Starting app...")
-	err := storage.RunMigration(a.db, a.logger, a.cfg.Server.MigrationPath)
 
-	if err != nil {
-		a.logger.Error("couldn't run migrations %w", slog.Any("error_details", err))
+	if a.db != nil {
+		err := storage.RunMigration(a.db, a.logger, migrationPath(a.cfg))
+
+		if err != nil {
+			a.logger.Error("couldn't run migrations %w", slog.Any("error_details", err))
+		}
 	}
 
+	a.startWorkers()
+
 	a.server.Run()
 }
 
 func (a App) Stop(ctx context.Context, shutdownTimeout time.Duration) {
 	a.logger.Info("Stopping app...")
 
+	a.probes.Shutdown()
+
 	timeout := shutdownTimeout
 
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, timeout)
@@ -76,7 +229,7 @@ func (a App) Stop(ctx context.Context, shutdownTimeout time.Duration) {
 
 	doneCh := make(chan error)
 	go func() {
-		doneCh <- a.server.Stop(ctxWithTimeout)
+		doneCh <- a.shutdown(ctxWithTimeout)
 	}()
 
 	select {
@@ -85,9 +238,244 @@ func (a App) Stop(ctx context.Context, shutdownTimeout time.Duration) {
 			a.logger.Error("Error while stopping server: %v", slog.Any("error_details", err))
 		}
 
+		if err := a.shutdownTracing(ctxWithTimeout); err != nil {
+			a.logger.Error("Error while flushing traces: %v", slog.Any("error_details", err))
+		}
+
 		a.logger.Info("App has been stopped gracefully")
 
 	case <-ctx.Done():
 		a.logger.Warn("App stopped forced")
 	}
 }
+
+func openPostgres(cfg config.DBConfig, appMetrics *metrics.Metrics) (*postgres.Storage, storage.Repositories, error) {
+	db, err := postgres.New(cfg)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't establish db connection %w", err)
+	}
+
+	if err := appMetrics.Register(metrics.NewPoolCollector(db.DB)); err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't register db metrics %w", err)
+	}
+
+	categoryStorage, err := postgres.NewCategories(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create categories %w", err)
+	}
+
+	productStorage, err := postgres.NewProducts(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create products %w", err)
+	}
+
+	inventoryStorage, err := postgres.NewInventory(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create inventory %w", err)
+	}
+
+	orderStorage, err := postgres.NewOrders(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create orders %w", err)
+	}
+
+	apiKeyStorage, err := postgres.NewAPIKeys(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create api keys %w", err)
+	}
+
+	outboxStorage, err := postgres.NewOutbox(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create outbox %w", err)
+	}
+
+	webhookStorage, err := postgres.NewWebhooks(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create webhooks %w", err)
+	}
+
+	return db, storage.Repositories{
+		Categories: categoryStorage,
+		Products:   productStorage,
+		Inventory:  inventoryStorage,
+		Orders:     orderStorage,
+		APIKeys:    apiKeyStorage,
+		Outbox:     outboxStorage,
+		Webhooks:   webhookStorage,
+	}, nil
+}
+
+func openSQLite(cfg config.DBConfig, appMetrics *metrics.Metrics) (*sqlite.Storage, storage.Repositories, error) {
+	db, err := sqlite.New(cfg)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't open sqlite db %w", err)
+	}
+
+	if err := appMetrics.Register(collectors.NewDBStatsCollector(db.DB, "sqlite")); err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't register db metrics %w", err)
+	}
+
+	categoryStorage, err := sqlite.NewCategories(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create categories %w", err)
+	}
+
+	productStorage, err := sqlite.NewProducts(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create products %w", err)
+	}
+
+	inventoryStorage, err := sqlite.NewInventory(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create inventory %w", err)
+	}
+
+	orderStorage, err := sqlite.NewOrders(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create orders %w", err)
+	}
+
+	apiKeyStorage, err := sqlite.NewAPIKeys(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create api keys %w", err)
+	}
+
+	outboxStorage, err := sqlite.NewOutbox(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create outbox %w", err)
+	}
+
+	webhookStorage, err := sqlite.NewWebhooks(db)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't create webhooks %w", err)
+	}
+
+	return db, storage.Repositories{
+		Categories: categoryStorage,
+		Products:   productStorage,
+		Inventory:  inventoryStorage,
+		Orders:     orderStorage,
+		APIKeys:    apiKeyStorage,
+		Outbox:     outboxStorage,
+		Webhooks:   webhookStorage,
+	}, nil
+}
+
+func openMemory(mem *memory.Storage) storage.Repositories {
+	return storage.Repositories{
+		Categories: memory.NewCategories(mem),
+		Products:   memory.NewProducts(mem),
+		Inventory:  memory.NewInventory(mem),
+		Orders:     memory.NewOrders(mem),
+		APIKeys:    memory.NewAPIKeys(mem),
+		Outbox:     memory.NewOutbox(mem),
+		Webhooks:   memory.NewWebhooks(mem),
+	}
+}
+
+// withCache puts one cache in front of the product and category reads of repos.
+func withCache(repos storage.Repositories, cfg config.CacheConfig,
+	appMetrics *metrics.Metrics) (*cache.Cache, storage.Repositories, error) {
+	catalog, err := cache.New(cfg)
+	if err != nil {
+		return nil, storage.Repositories{}, fmt.Errorf("couldn't configure cache %w", err)
+	}
+
+	repos.Products = cache.NewProducts(repos.Products, catalog,
+		appMetrics.CacheHits.WithLabelValues("products"), appMetrics.CacheMisses.WithLabelValues("products"))
+	repos.Categories = cache.NewCategories(repos.Categories, catalog,
+		appMetrics.CacheHits.WithLabelValues("categories"), appMetrics.CacheMisses.WithLabelValues("categories"))
+
+	return catalog, repos, nil
+}
+
+// purgeOnNotify returns a loop that purges catalog whenever any instance writes the catalog, which keeps the
+// cache of this instance in step with the writes of the others. The cache is purged on every reconnect too, as
+// the writes made while disconnected went unnoticed.
+func purgeOnNotify(pg *postgres.Storage, catalog *cache.Cache) func(context.Context) {
+	return func(ctx context.Context) {
+		pg.Listen(ctx, postgres.CatalogChannel, func(string) { catalog.Purge() }, catalog.Purge)
+	}
+}
+
+// newDispatcher delivers the events of outbox to the sinks of cfg and to extra ones.
+func newDispatcher(outbox storage.OutboxRepository, cfg config.EventsConfig, log *slog.Logger,
+	appMetrics *metrics.Metrics, extra ...events.Sink) (*events.Dispatcher, error) {
+	sinks, err := events.NewSinks(cfg, log)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't configure event sinks %w", err)
+	}
+
+	sinks = append(sinks, extra...)
+
+	dispatcher, err := events.NewDispatcher(outbox, sinks, cfg, appMetrics.EventsDelivered,
+		appMetrics.EventDeliveryFailures)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't configure event dispatcher %w", err)
+	}
+
+	return dispatcher, nil
+}
+
+// shutdown stops in dependency order: requests first, then the workers they may have handed events to,
+// and the storage only once neither can use it anymore.
+func (a App) shutdown(ctx context.Context) error {
+	serverErr := a.server.Stop(ctx)
+
+	workersErr := a.stopWorkers(ctx)
+	if workersErr != nil {
+		a.logger.Error("Error while stopping workers: %v", slog.Any("error_details", workersErr))
+	}
+
+	a.server.Close()
+
+	return serverErr
+}
+
+// background returns a func that starts each of loops in a goroutine of its own, and a stop func that cancels
+// them and waits for them to return, or for ctx to end.
+func background(loops ...func(context.Context)) (func(), func(context.Context) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var running sync.WaitGroup
+
+	start := func() {
+		for _, loop := range loops {
+			running.Add(1)
+
+			go func() {
+				defer running.Done()
+
+				loop(ctx)
+			}()
+		}
+	}
+
+	stop := func(waitCtx context.Context) error {
+		cancel()
+
+		done := make(chan struct{})
+		go func() {
+			running.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return nil
+		case <-waitCtx.Done():
+			return fmt.Errorf("background workers didn't stop %w", waitCtx.Err())
+		}
+	}
+
+	return start, stop
+}
+
+// migrationPath is the migration set of the configured driver; the SQLite one lives next to the postgres one.
+func migrationPath(cfg *config.AppConfig) string {
+	if cfg.DB.Driver == storage.DriverSQLite {
+		return filepath.Join(cfg.Server.MigrationPath, "sqlite")
+	}
+
+	return cfg.Server.MigrationPath
+}
@@ -8,16 +8,27 @@ import (
 )
 
 type AppConfig struct {
-	DB     DBConfig
-	Server ServerConfig
+	DB       DBConfig
+	Server   ServerConfig
+	Auth     AuthConfig
+	Tracing  TracingConfig
+	Cache    CacheConfig
+	Events   EventsConfig
+	Webhooks WebhooksConfig
 }
 
+// DBConfig selects the storage backend with Driver: "postgres", "sqlite" for a database file at SQLitePath
+// where Postgres can't run, or "memory" to keep the data in process memory without a database. The connection
+// settings only apply to postgres. Each SQL backend has its own migrations: postgres reads MIGRATION_PATH
+// and sqlite its "sqlite" subdirectory.
 type DBConfig struct {
-	User   string `env:"DB_USER"     envDefault:"admin"`
-	Passwd string `env:"DB_PASSWORD" envDefault:"admin"`
-	DBName string `env:"DB_NAME"     envDefault:"postgres"`
-	Host   string `env:"HOST"        envDefault:"localhost"`
-	Port   string `env:"PORT"        envDefault:"5432"`
+	Driver     string `env:"DB_DRIVER"   envDefault:"postgres"`
+	User       string `env:"DB_USER"     envDefault:"admin"`
+	Passwd     string `env:"DB_PASSWORD" envDefault:"admin"`
+	DBName     string `env:"DB_NAME"     envDefault:"postgres"`
+	Host       string `env:"HOST"        envDefault:"localhost"`
+	Port       string `env:"PORT"        envDefault:"5432"`
+	SQLitePath string `env:"SQLITE_PATH" envDefault:"./tradeservice.db"`
 }
 
 type ServerConfig struct {
@@ -25,6 +36,89 @@ type ServerConfig struct {
 	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"5s"`
 	EnvType         string        `env:"ENV_TYPE"         envDefault:"local"`
 	MigrationPath   string        `env:"MIGRATION_PATH"   envDefault:"./internal/migrations"`
+	HealthTimeout   time.Duration `env:"HEALTH_TIMEOUT"   envDefault:"2s"`
+	TrustProxy      bool          `env:"TRUST_PROXY"      envDefault:"false"`
+	RateLimit       RateLimitConfig
+}
+
+// CacheConfig enables the in-process cache of product and category reads, an LRU of Size results that
+// also expire after TTL. With postgres, every instance purges its cache when any of them writes the catalog;
+// the other drivers only see their own writes, so with several instances a read can be up to TTL stale.
+type CacheConfig struct {
+	Enabled bool          `env:"CACHE_ENABLED" envDefault:"false"`
+	Size    int           `env:"CACHE_SIZE"    envDefault:"10000"`
+	TTL     time.Duration `env:"CACHE_TTL"     envDefault:"30s"`
+}
+
+// EventsConfig sets up the delivery of the catalog events recorded in the outbox. Sinks lists where they go:
+// "log", "http" to POST each event to HTTPURL, "file" to append it as a JSON line to FilePath, or "none".
+// Delivery is at least once: an event that fails at any sink is retried at all of them, after a backoff that
+// doubles from MinBackoff up to MaxBackoff. Delivered events are kept for Retention.
+type EventsConfig struct {
+	Sinks        []string      `env:"EVENT_SINKS"         envDefault:"log"  envSeparator:","`
+	HTTPURL      string        `env:"EVENT_HTTP_URL"`
+	HTTPTimeout  time.Duration `env:"EVENT_HTTP_TIMEOUT"  envDefault:"5s"`
+	FilePath     string        `env:"EVENT_FILE_PATH"     envDefault:"./events.jsonl"`
+	PollInterval time.Duration `env:"EVENT_POLL_INTERVAL" envDefault:"1s"`
+	BatchSize    int           `env:"EVENT_BATCH_SIZE"    envDefault:"100"`
+	Lease        time.Duration `env:"EVENT_LEASE"         envDefault:"1m"`
+	MinBackoff   time.Duration `env:"EVENT_MIN_BACKOFF"   envDefault:"1s"`
+	MaxBackoff   time.Duration `env:"EVENT_MAX_BACKOFF"   envDefault:"10m"`
+	Retention    time.Duration `env:"EVENT_RETENTION"     envDefault:"168h"`
+}
+
+// WebhooksConfig sets up the delivery of the catalog events to the webhooks registered through the API. Each
+// delivery is POSTed within Timeout and retried after a backoff that doubles from MinBackoff up to MaxBackoff,
+// until it is delivered or has failed MaxAttempts times. Finished deliveries are kept for Retention. Webhooks
+// on loopback, private and link-local hosts are refused unless AllowPrivateHosts is set, e.g. for development.
+type WebhooksConfig struct {
+	Enabled           bool          `env:"WEBHOOKS_ENABLED"            envDefault:"true"`
+	Timeout           time.Duration `env:"WEBHOOK_TIMEOUT"             envDefault:"10s"`
+	PollInterval      time.Duration `env:"WEBHOOK_POLL_INTERVAL"       envDefault:"1s"`
+	BatchSize         int           `env:"WEBHOOK_BATCH_SIZE"          envDefault:"20"`
+	Lease             time.Duration `env:"WEBHOOK_LEASE"               envDefault:"5m"`
+	MaxAttempts       int           `env:"WEBHOOK_MAX_ATTEMPTS"        envDefault:"10"`
+	MinBackoff        time.Duration `env:"WEBHOOK_MIN_BACKOFF"         envDefault:"10s"`
+	MaxBackoff        time.Duration `env:"WEBHOOK_MAX_BACKOFF"         envDefault:"1h"`
+	Retention         time.Duration `env:"WEBHOOK_RETENTION"           envDefault:"720h"`
+	AllowPrivateHosts bool          `env:"WEBHOOK_ALLOW_PRIVATE_HOSTS" envDefault:"false"`
+}
+
+// RateLimitConfig sets the token buckets of each client, keyed by API key, user or IP. Reads are GET, HEAD and
+// OPTIONS requests; every other method spends the write budget. Rates are in requests per second. Client IPs
+// are read from X-Forwarded-For only with TRUST_PROXY, i.e. behind a proxy that sets the header. The IP budget
+// applies to every request of an IP before authentication, so it should exceed the read and write budgets.
+type RateLimitConfig struct {
+	Enabled    bool    `env:"RATE_LIMIT_ENABLED"     envDefault:"true"`
+	ReadRate   float64 `env:"RATE_LIMIT_READ_RATE"   envDefault:"20"`
+	ReadBurst  int     `env:"RATE_LIMIT_READ_BURST"  envDefault:"40"`
+	WriteRate  float64 `env:"RATE_LIMIT_WRITE_RATE"  envDefault:"5"`
+	WriteBurst int     `env:"RATE_LIMIT_WRITE_BURST" envDefault:"10"`
+	IPRate     float64 `env:"RATE_LIMIT_IP_RATE"     envDefault:"50"`
+	IPBurst    int     `env:"RATE_LIMIT_IP_BURST"    envDefault:"100"`
+}
+
+// AuthConfig holds the JWT verification settings. Exactly one key source is
+// used depending on Algorithm: Secret for HS256, PublicKeyFile for RS256.
+type AuthConfig struct {
+	Enabled       bool     `env:"AUTH_ENABLED"         envDefault:"true"`
+	Algorithm     string   `env:"JWT_ALGORITHM"        envDefault:"HS256"`
+	Secret        string   `env:"JWT_SECRET"`
+	PublicKeyFile string   `env:"JWT_PUBLIC_KEY_FILE"`
+	Issuer        string   `env:"JWT_ISSUER"`
+	Audience      string   `env:"JWT_AUDIENCE"`
+	PolicyFile    string   `env:"POLICY_FILE"          envDefault:"./internal/config/policy.json"`
+	PublicGET     []string `env:"AUTH_PUBLIC_GET"      envDefault:"/categories,/categories/*,/products,/products/:id,/products/:id/categories,/product,/openapi.json,/docs,/docs/*,/healthz,/readyz" envSeparator:","`
+}
+
+// TracingConfig selects where spans go: "none", "stdout" for local debugging, or "otlp" to send them
+// over OTLP/HTTP to Endpoint.
+type TracingConfig struct {
+	Exporter    string  `env:"TRACING_EXPORTER"     envDefault:"none"`
+	Endpoint    string  `env:"OTLP_ENDPOINT"        envDefault:"localhost:4318"`
+	Insecure    bool    `env:"OTLP_INSECURE"        envDefault:"true"`
+	ServiceName string  `env:"SERVICE_NAME"         envDefault:"tradeservice"`
+	SampleRatio float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
 }
 
 func New() (cfg *AppConfig, err error) {
@@ -0,0 +1,179 @@
+// Package events delivers the domain events that the catalog writes record in the outbox to pluggable sinks.
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"tradeservice/internal/config"
+	"tradeservice/internal/logger"
+	"tradeservice/internal/models"
+	"tradeservice/internal/storage"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cleanupInterval is how often the delivered events and webhook deliveries older than their retention are
+// dropped.
+const cleanupInterval = time.Hour
+
+var ErrEventsConfig = errors.New("invalid events config")
+
+// Dispatcher polls the outbox and hands every due event to all sinks. An event is marked delivered only once
+// every sink took it, so a crash between a delivery and its mark means the event is delivered again after
+// its lease; several dispatchers, e.g. one per instance, can share an outbox.
+type Dispatcher struct {
+	outbox    storage.OutboxRepository
+	sinks     []Sink
+	cfg       config.EventsConfig
+	delivered prometheus.Counter
+	failed    prometheus.Counter
+	now       func() time.Time
+}
+
+func NewDispatcher(outbox storage.OutboxRepository, sinks []Sink, cfg config.EventsConfig,
+	delivered prometheus.Counter, failed prometheus.Counter) (*Dispatcher, error) {
+	if cfg.BatchSize < 1 || cfg.PollInterval <= 0 || cfg.Lease <= 0 || cfg.Retention <= 0 {
+		return nil, fmt.Errorf("%w: batch size must be at least 1, poll interval, lease and retention positive",
+			ErrEventsConfig)
+	}
+
+	if cfg.MinBackoff <= 0 || cfg.MaxBackoff < cfg.MinBackoff {
+		return nil, fmt.Errorf("%w: backoff must be positive with max not below min", ErrEventsConfig)
+	}
+
+	return &Dispatcher{
+		outbox:    outbox,
+		sinks:     sinks,
+		cfg:       cfg,
+		delivered: delivered,
+		failed:    failed,
+		now:       time.Now,
+	}, nil
+}
+
+// Run dispatches the due events every poll interval, without waiting while full batches keep coming, until
+// ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	poll(ctx, d.cfg.PollInterval, d.cfg.BatchSize, d.Dispatch, func(ctx context.Context) {
+		deleted, err := d.outbox.DeleteDeliveredEvents(ctx, d.now().Add(-d.cfg.Retention))
+		if err != nil && ctx.Err() == nil {
+			logger.FromContext(ctx).Error("Failed to delete delivered events", slog.Any("error_details", err))
+		} else if deleted > 0 {
+			logger.FromContext(ctx).Info("Deleted delivered events", "count", deleted)
+		}
+	})
+}
+
+// Dispatch claims one batch of due events and delivers them; it returns how many it claimed. The events left
+// when the lease runs out are left to the next claim, since another dispatcher may have claimed them by then.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	claimed, err := d.outbox.ClaimEvents(ctx, d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim events %w", err)
+	}
+
+	leaseCtx, cancel := context.WithDeadline(ctx, d.now().Add(d.cfg.Lease))
+	defer cancel()
+
+	for _, event := range claimed {
+		if leaseCtx.Err() != nil {
+			break
+		}
+
+		if err := d.deliver(ctx, leaseCtx, event); err != nil {
+			logger.FromContext(ctx).Error("Failed to record event delivery", "event_id", event.ID,
+				slog.Any("error_details", err))
+		}
+	}
+
+	return len(claimed), nil
+}
+
+// deliver hands event to every sink within the lease of leaseCtx and records the outcome in the outbox.
+func (d *Dispatcher) deliver(ctx context.Context, leaseCtx context.Context, event models.OutboxEvent) error {
+	errs := make([]error, 0, len(d.sinks))
+
+	for _, sink := range d.sinks {
+		if err := sink.Deliver(leaseCtx, event.Event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		d.failed.Inc()
+
+		retryIn := backoff(event.Attempts, d.cfg.MinBackoff, d.cfg.MaxBackoff)
+
+		logger.FromContext(ctx).Warn("Failed to deliver event", "event_id", event.ID, "type", event.Type,
+			"attempts", event.Attempts+1, "retry_in", retryIn, slog.Any("error_details", err))
+
+		return d.outbox.MarkEventFailed(ctx, event.ID, d.now().Add(retryIn), err.Error())
+	}
+
+	if err := d.outbox.MarkEventDelivered(ctx, event.ID); err != nil {
+		return err
+	}
+
+	d.delivered.Inc()
+
+	return nil
+}
+
+// poll calls dispatch every interval, and again right away while it claims full batches of batchSize, and
+// cleanup every cleanupInterval, until ctx is done.
+func poll(ctx context.Context, interval time.Duration, batchSize int, dispatch func(context.Context) (int, error),
+	cleanup func(context.Context)) {
+	ticker := time.NewTicker(interval)
+
+	defer ticker.Stop()
+
+	var cleaned time.Time
+
+	for {
+		for {
+			claimed, err := dispatch(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.FromContext(ctx).Error("Failed to dispatch", slog.Any("error_details", err))
+				}
+
+				break
+			}
+
+			if claimed < batchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		if time.Since(cleaned) >= cleanupInterval {
+			cleaned = time.Now()
+
+			cleanup(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// backoff is the wait before the next attempt after attempts failed ones and the one that just failed: it
+// doubles from minBackoff up to maxBackoff.
+func backoff(attempts int, minBackoff time.Duration, maxBackoff time.Duration) time.Duration {
+	wait := minBackoff
+
+	for range attempts {
+		if wait >= maxBackoff/2 {
+			return maxBackoff
+		}
+
+		wait *= 2
+	}
+
+	return wait
+}
@@ -0,0 +1,358 @@
+package events_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+	"tradeservice/internal/config"
+	"tradeservice/internal/events"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/utils"
+	"tradeservice/internal/storage/memory"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errUnavailable = errors.New("sink unavailable")
+
+var eventsConfig = config.EventsConfig{
+	PollInterval: time.Millisecond,
+	BatchSize:    10,
+	Lease:        time.Minute,
+	MinBackoff:   time.Hour,
+	MaxBackoff:   4 * time.Hour,
+	Retention:    time.Hour,
+}
+
+// recordingSink records the events it was handed and fails while failures are left.
+type recordingSink struct {
+	events   []models.Event
+	failures int
+}
+
+func (s *recordingSink) Deliver(_ context.Context, event models.Event) error {
+	if s.failures > 0 {
+		s.failures--
+
+		return errUnavailable
+	}
+
+	s.events = append(s.events, event)
+
+	return nil
+}
+
+// retryOutbox records the retry times of the failed events and makes them due again right away.
+type retryOutbox struct {
+	*memory.Outbox
+	retries []time.Time
+}
+
+func (o *retryOutbox) MarkEventFailed(ctx context.Context, id string, retryAt time.Time, reason string) error {
+	o.retries = append(o.retries, retryAt)
+
+	return o.Outbox.MarkEventFailed(ctx, id, time.Now(), reason)
+}
+
+type fixture struct {
+	products   *memory.Products
+	outbox     *memory.Outbox
+	delivered  prometheus.Counter
+	failed     prometheus.Counter
+	dispatcher *events.Dispatcher
+}
+
+func newFixture(t *testing.T, cfg config.EventsConfig, sinks ...events.Sink) fixture {
+	t.Helper()
+
+	db := memory.New()
+	f := fixture{
+		products:  memory.NewProducts(db),
+		outbox:    memory.NewOutbox(db),
+		delivered: prometheus.NewCounter(prometheus.CounterOpts{Name: "delivered"}),
+		failed:    prometheus.NewCounter(prometheus.CounterOpts{Name: "failed"}),
+	}
+
+	var err error
+
+	f.dispatcher, err = events.NewDispatcher(f.outbox, sinks, cfg, f.delivered, f.failed)
+	require.NoError(t, err)
+
+	return f
+}
+
+func (f fixture) addProduct(t *testing.T, sku string) models.ProductDto {
+	t.Helper()
+
+	product, err := f.products.AddProduct(context.Background(), models.ProductDto{Name: sku, SKU: sku, Currency: "USD"})
+	require.NoError(t, err)
+
+	return product
+}
+
+func testEvent() models.Event {
+	return models.Event{
+		ID:      "7",
+		Type:    models.EventProductDeleted,
+		Subject: "3",
+		Data:    json.RawMessage(`{"id":"3"}`),
+		Created: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestDispatcher_DeliversToEverySink(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	first, second := &recordingSink{}, &recordingSink{}
+	f := newFixture(t, eventsConfig, first, second)
+
+	bolt := f.addProduct(t, "B-1")
+	nut := f.addProduct(t, "N-1")
+
+	claimed, err := f.dispatcher.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, claimed)
+
+	for _, sink := range []*recordingSink{first, second} {
+		require.Len(t, sink.events, 2)
+		assert.Equal(t, bolt.ID, sink.events[0].Subject)
+		assert.Equal(t, nut.ID, sink.events[1].Subject)
+		assert.Equal(t, models.EventProductCreated, sink.events[0].Type)
+	}
+
+	assert.InDelta(t, 2, testutil.ToFloat64(f.delivered), 0)
+
+	// Delivered events are never claimed again, not even once the lease is over.
+	due, err := f.outbox.ClaimEvents(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
+
+func TestDispatcher_RetriesFailedEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cfg := eventsConfig
+	cfg.MinBackoff, cfg.MaxBackoff = time.Millisecond, time.Millisecond
+
+	healthy, flaky := &recordingSink{}, &recordingSink{failures: 1}
+	f := newFixture(t, cfg, healthy, flaky)
+	product := f.addProduct(t, "B-1")
+
+	_, err := f.dispatcher.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, flaky.events)
+	assert.InDelta(t, 1, testutil.ToFloat64(f.failed), 0)
+	assert.InDelta(t, 0, testutil.ToFloat64(f.delivered), 0)
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = f.dispatcher.Dispatch(ctx)
+	require.NoError(t, err)
+
+	// At least once: the healthy sink sees the event again when it is retried for the flaky one.
+	require.Len(t, healthy.events, 2)
+	require.Len(t, flaky.events, 1)
+	assert.Equal(t, product.ID, flaky.events[0].Subject)
+	assert.Equal(t, healthy.events[0].ID, healthy.events[1].ID)
+	assert.InDelta(t, 1, testutil.ToFloat64(f.delivered), 0)
+}
+
+func TestDispatcher_BacksOffExponentially(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := memory.New()
+	outbox := &retryOutbox{Outbox: memory.NewOutbox(db)}
+	sink := &recordingSink{failures: 10}
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "counter"})
+
+	dispatcher, err := events.NewDispatcher(outbox, []events.Sink{sink}, eventsConfig, counter, counter)
+	require.NoError(t, err)
+
+	_, err = memory.NewProducts(db).AddProduct(ctx, models.ProductDto{Name: "bolt", SKU: "B-1", Currency: "USD"})
+	require.NoError(t, err)
+
+	var waits []time.Duration
+
+	for range 4 {
+		started := time.Now()
+
+		claimed, err := dispatcher.Dispatch(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, claimed)
+
+		waits = append(waits, outbox.retries[len(outbox.retries)-1].Sub(started).Round(time.Minute))
+	}
+
+	assert.Equal(t, []time.Duration{time.Hour, 2 * time.Hour, 4 * time.Hour, 4 * time.Hour}, waits)
+}
+
+func TestDispatcher_RunStopsWithContext(t *testing.T) {
+	t.Parallel()
+
+	sink := &recordingSink{}
+	f := newFixture(t, eventsConfig, sink)
+	f.addProduct(t, "B-1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		f.dispatcher.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return testutil.ToFloat64(f.delivered) == 1 }, time.Second,
+		time.Millisecond)
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run didn't stop")
+	}
+
+	assert.Len(t, sink.events, 1)
+}
+
+func TestNewDispatcher_RejectsInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	broken := []func(*config.EventsConfig){
+		func(cfg *config.EventsConfig) { cfg.BatchSize = 0 },
+		func(cfg *config.EventsConfig) { cfg.Lease = 0 },
+		func(cfg *config.EventsConfig) { cfg.MinBackoff = 0 },
+		func(cfg *config.EventsConfig) { cfg.MaxBackoff = cfg.MinBackoff / 2 },
+	}
+
+	for _, breakConfig := range broken {
+		cfg := eventsConfig
+		breakConfig(&cfg)
+
+		_, err := events.NewDispatcher(memory.NewOutbox(memory.New()), nil, cfg, nil, nil)
+		require.ErrorIs(t, err, events.ErrEventsConfig)
+	}
+}
+
+func TestNewSinks(t *testing.T) {
+	t.Parallel()
+
+	log := utils.NewTestLogger()
+
+	sinks, err := events.NewSinks(config.EventsConfig{
+		Sinks: []string{"log", "http", "file"}, HTTPURL: "http://localhost:9000/events", HTTPTimeout: time.Second,
+		FilePath: "events.jsonl",
+	}, log)
+	require.NoError(t, err)
+	assert.Len(t, sinks, 3)
+
+	sinks, err = events.NewSinks(config.EventsConfig{Sinks: []string{"none"}}, log)
+	require.NoError(t, err)
+	assert.Empty(t, sinks)
+
+	for _, cfg := range []config.EventsConfig{
+		{Sinks: []string{"kafka"}},
+		{Sinks: []string{"http"}, HTTPTimeout: time.Second},
+		{Sinks: []string{"http"}, HTTPURL: "ftp://localhost/events", HTTPTimeout: time.Second},
+		{Sinks: []string{"file"}},
+	} {
+		_, err = events.NewSinks(cfg, log)
+		require.ErrorIs(t, err, events.ErrEventsConfig, "sinks %v", cfg.Sinks)
+	}
+}
+
+func TestHTTPSink_PostsEvents(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		event   models.Event
+		headers http.Header
+	}
+
+	var status atomic.Int64
+
+	status.Store(http.StatusNoContent)
+
+	requests := make(chan request, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var received request
+
+		body, err := io.ReadAll(r.Body)
+		if err == nil {
+			err = json.Unmarshal(body, &received.event)
+		}
+
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		received.headers = r.Header
+		requests <- received
+
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(server.Close)
+
+	sink, err := events.NewHTTPSink(server.URL, time.Second)
+	require.NoError(t, err)
+
+	event := testEvent()
+	require.NoError(t, sink.Deliver(context.Background(), event))
+
+	received := <-requests
+	assert.Equal(t, event, received.event)
+	assert.Equal(t, "application/json", received.headers.Get("Content-Type"))
+	assert.Equal(t, event.ID, received.headers.Get("X-Event-ID"))
+	assert.Equal(t, event.Type, received.headers.Get("X-Event-Type"))
+
+	status.Store(http.StatusServiceUnavailable)
+	require.ErrorIs(t, sink.Deliver(context.Background(), event), events.ErrDeliveryStatus)
+}
+
+func TestFileSink_AppendsJSONLines(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink := events.NewFileSink(path)
+
+	first := testEvent()
+	second := testEvent()
+	second.ID = "8"
+
+	require.NoError(t, sink.Deliver(context.Background(), first))
+	require.NoError(t, sink.Deliver(context.Background(), second))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = file.Close() })
+
+	var lines []models.Event
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event models.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+
+		lines = append(lines, event)
+	}
+
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, []models.Event{first, second}, lines)
+}
@@ -0,0 +1,168 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"tradeservice/internal/config"
+	"tradeservice/internal/models"
+)
+
+const (
+	SinkLog  = "log"
+	SinkHTTP = "http"
+	SinkFile = "file"
+	SinkNone = "none"
+)
+
+var (
+	ErrDeliveryStatus  = errors.New("unexpected delivery response status")
+	ErrDeliveryAddress = errors.New("webhook address is not public")
+)
+
+// Sink receives the events of the outbox. As delivery is at least once, a sink can see an event again, with
+// the same ID, after a failed attempt or a crash.
+type Sink interface {
+	Deliver(ctx context.Context, event models.Event) error
+}
+
+// NewSinks builds the sinks listed in cfg.Sinks.
+func NewSinks(cfg config.EventsConfig, log *slog.Logger) ([]Sink, error) {
+	sinks := make([]Sink, 0, len(cfg.Sinks))
+
+	for _, name := range cfg.Sinks {
+		switch strings.TrimSpace(name) {
+		case SinkLog:
+			sinks = append(sinks, NewLogSink(log))
+		case SinkHTTP:
+			sink, err := NewHTTPSink(cfg.HTTPURL, cfg.HTTPTimeout)
+			if err != nil {
+				return nil, err
+			}
+
+			sinks = append(sinks, sink)
+		case SinkFile:
+			if cfg.FilePath == "" {
+				return nil, fmt.Errorf("%w: the file sink needs a path", ErrEventsConfig)
+			}
+
+			sinks = append(sinks, NewFileSink(cfg.FilePath))
+		case SinkNone, "":
+		default:
+			return nil, fmt.Errorf("%w: unknown sink %q", ErrEventsConfig, name)
+		}
+	}
+
+	return sinks, nil
+}
+
+// LogSink writes every event to the log, e.g. for local development.
+type LogSink struct {
+	log *slog.Logger
+}
+
+func NewLogSink(log *slog.Logger) *LogSink {
+	return &LogSink{log: log}
+}
+
+func (s *LogSink) Deliver(ctx context.Context, event models.Event) error {
+	s.log.InfoContext(ctx, "Event", "event_id", event.ID, "type", event.Type, "subject", event.Subject,
+		"data", event.Data)
+
+	return nil
+}
+
+// HTTPSink POSTs every event as JSON to a URL; any status other than 2xx fails the delivery.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(rawURL string, timeout time.Duration) (*HTTPSink, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: the http sink needs an http(s) URL", ErrEventsConfig)
+	}
+
+	if timeout <= 0 {
+		return nil, fmt.Errorf("%w: the http sink timeout must be positive", ErrEventsConfig)
+	}
+
+	return &HTTPSink{url: rawURL, client: &http.Client{Timeout: timeout}}, nil
+}
+
+func (s *HTTPSink) Deliver(ctx context.Context, event models.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID)
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post event %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	// Drain a little of the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w %d", ErrDeliveryStatus, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// FileSink appends every event as a line of JSON to a file. The file is reopened for every event, so it can
+// be rotated away underneath the sink.
+type FileSink struct {
+	path string
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: filepath.Clean(path)}
+}
+
+func (s *FileSink) Deliver(_ context.Context, event models.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open event file %w", err)
+	}
+
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		_ = file.Close()
+
+		return fmt.Errorf("failed to write event file %w", err)
+	}
+
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("failed to close event file %w", err)
+	}
+
+	return nil
+}
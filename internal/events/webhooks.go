@@ -0,0 +1,253 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+	"tradeservice/internal/config"
+	"tradeservice/internal/logger"
+	"tradeservice/internal/models"
+	"tradeservice/internal/storage"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Headers of a webhook delivery. The signature header is "t=<unix seconds>,v1=<hex HMAC-SHA256>", where the
+// HMAC is keyed with the webhook secret and taken over "<unix seconds>.<body>", so receivers can reject stale
+// and replayed deliveries as well as forged ones. The delivery ID stays the same across retries.
+const (
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// WebhookSink queues every event for the webhooks subscribed to its type. Queueing an event again is a no-op,
+// so it is safe with the at least once delivery of the dispatcher.
+type WebhookSink struct {
+	webhooks storage.WebhookRepository
+}
+
+func NewWebhookSink(webhooks storage.WebhookRepository) *WebhookSink {
+	return &WebhookSink{webhooks: webhooks}
+}
+
+func (s *WebhookSink) Deliver(ctx context.Context, event models.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %w", err)
+	}
+
+	if _, err = s.webhooks.AddWebhookDeliveries(ctx, event, payload); err != nil {
+		return fmt.Errorf("failed to queue webhook deliveries %w", err)
+	}
+
+	return nil
+}
+
+// WebhookDeliverer polls the queued webhook deliveries and POSTs each one, signed, to its webhook. Every
+// attempt is recorded; a failed one is retried after a backoff until the delivery runs out of attempts.
+// Redirects are not followed, so a 3xx response fails the attempt. Unless private hosts are allowed, only
+// public addresses are dialed; the check runs on the resolved address, so DNS can't point a webhook inwards.
+type WebhookDeliverer struct {
+	webhooks  storage.WebhookRepository
+	client    *http.Client
+	cfg       config.WebhooksConfig
+	delivered prometheus.Counter
+	failed    prometheus.Counter
+}
+
+func NewWebhookDeliverer(webhooks storage.WebhookRepository, cfg config.WebhooksConfig,
+	delivered prometheus.Counter, failed prometheus.Counter) (*WebhookDeliverer, error) {
+	if cfg.BatchSize < 1 || cfg.MaxAttempts < 1 {
+		return nil, fmt.Errorf("%w: webhook batch size and max attempts must be at least 1", ErrEventsConfig)
+	}
+
+	if cfg.Timeout <= 0 || cfg.PollInterval <= 0 || cfg.Lease <= 0 || cfg.Retention <= 0 {
+		return nil, fmt.Errorf("%w: webhook timeout, poll interval, lease and retention must be positive",
+			ErrEventsConfig)
+	}
+
+	if cfg.MinBackoff <= 0 || cfg.MaxBackoff < cfg.MinBackoff {
+		return nil, fmt.Errorf("%w: webhook backoff must be positive with max not below min", ErrEventsConfig)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert // net/http sets a *Transport
+
+	if !cfg.AllowPrivateHosts {
+		// A proxy would resolve and dial the webhook host itself, out of reach of the check.
+		transport.Proxy = nil
+		transport.DialContext = (&net.Dialer{
+			Timeout:   cfg.Timeout,
+			KeepAlive: 30 * time.Second,
+			Control:   publicOnly,
+		}).DialContext
+	}
+
+	return &WebhookDeliverer{
+		webhooks: webhooks,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.Timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg:       cfg,
+		delivered: delivered,
+		failed:    failed,
+	}, nil
+}
+
+// Run delivers the due webhook deliveries every poll interval, like Dispatcher.Run, until ctx is done.
+func (d *WebhookDeliverer) Run(ctx context.Context) {
+	poll(ctx, d.cfg.PollInterval, d.cfg.BatchSize, d.Dispatch, func(ctx context.Context) {
+		deleted, err := d.webhooks.DeleteWebhookDeliveries(ctx, time.Now().Add(-d.cfg.Retention))
+		if err != nil && ctx.Err() == nil {
+			logger.FromContext(ctx).Error("Failed to delete webhook deliveries", slog.Any("error_details", err))
+		} else if deleted > 0 {
+			logger.FromContext(ctx).Info("Deleted webhook deliveries", "count", deleted)
+		}
+	})
+}
+
+// Dispatch claims one batch of due deliveries and attempts them; it returns how many it claimed.
+func (d *WebhookDeliverer) Dispatch(ctx context.Context) (int, error) {
+	claimed, err := d.webhooks.ClaimWebhookDeliveries(ctx, d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim webhook deliveries %w", err)
+	}
+
+	leaseCtx, cancel := context.WithDeadline(ctx, time.Now().Add(d.cfg.Lease))
+	defer cancel()
+
+	for _, delivery := range claimed {
+		if leaseCtx.Err() != nil {
+			break
+		}
+
+		if err := d.deliver(ctx, leaseCtx, delivery); err != nil {
+			logger.FromContext(ctx).Error("Failed to record webhook attempt", "delivery_id", delivery.ID,
+				slog.Any("error_details", err))
+		}
+	}
+
+	return len(claimed), nil
+}
+
+// deliver attempts delivery within the lease of leaseCtx and records the attempt.
+func (d *WebhookDeliverer) deliver(ctx context.Context, leaseCtx context.Context,
+	delivery models.WebhookDelivery) error {
+	attempt := d.post(leaseCtx, delivery)
+
+	if attempt.Error == "" {
+		d.delivered.Inc()
+
+		return d.webhooks.RecordWebhookAttempt(ctx, delivery.ID, attempt, nil)
+	}
+
+	d.failed.Inc()
+
+	var retryAt *time.Time
+
+	if delivery.Attempts+1 < d.cfg.MaxAttempts {
+		retry := time.Now().Add(backoff(delivery.Attempts, d.cfg.MinBackoff, d.cfg.MaxBackoff))
+		retryAt = &retry
+	}
+
+	logger.FromContext(ctx).Warn("Failed to deliver webhook", "delivery_id", delivery.ID,
+		"webhook_id", delivery.WebhookID, "attempts", delivery.Attempts+1, "retry_at", retryAt,
+		"error_details", attempt.Error)
+
+	return d.webhooks.RecordWebhookAttempt(ctx, delivery.ID, attempt, retryAt)
+}
+
+// post sends delivery once and describes how it went.
+func (d *WebhookDeliverer) post(ctx context.Context, delivery models.WebhookDelivery) models.WebhookAttemptDto {
+	started := time.Now()
+
+	var attempt models.WebhookAttemptDto
+
+	attempt.StatusCode, attempt.Error = d.send(ctx, delivery, started)
+	attempt.Duration = time.Since(started).Milliseconds()
+
+	return attempt
+}
+
+func (d *WebhookDeliverer) send(ctx context.Context, delivery models.WebhookDelivery,
+	sent time.Time) (int, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Sprintf("failed to build request %v", withoutURL(err))
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookDelivery, delivery.ID)
+	req.Header.Set(HeaderWebhookEvent, delivery.EventType)
+	req.Header.Set(HeaderWebhookSignature, sign(delivery.Secret, sent, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Sprintf("failed to post webhook to %s %v", req.URL.Host, withoutURL(err))
+	}
+
+	defer resp.Body.Close()
+
+	// Drain a little of the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Sprintf("%v %d", ErrDeliveryStatus, resp.StatusCode)
+	}
+
+	return resp.StatusCode, ""
+}
+
+// withoutURL drops the URL a *url.Error quotes; the query or user info of a webhook URL may carry credentials,
+// and the attempt errors end up in the logs and the delivery history.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+
+	return err
+}
+
+// publicOnly is a net.Dialer Control that refuses to connect to an address that isn't public.
+func publicOnly(_ string, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDeliveryAddress, address)
+	}
+
+	if !models.PublicAddr(addr.Addr()) {
+		return fmt.Errorf("%w: %s", ErrDeliveryAddress, addr.Addr())
+	}
+
+	return nil
+}
+
+// sign is the value of the signature header of payload sent at sent.
+func sign(secret string, sent time.Time, payload []byte) string {
+	timestamp := strconv.FormatInt(sent.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,365 @@
+package events_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"tradeservice/internal/config"
+	"tradeservice/internal/events"
+	"tradeservice/internal/logger"
+	"tradeservice/internal/models"
+	"tradeservice/internal/storage/memory"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const webhookSecret = "whsec_test"
+
+var webhooksConfig = config.WebhooksConfig{
+	Timeout:      time.Second,
+	PollInterval: time.Millisecond,
+	BatchSize:    10,
+	Lease:        time.Minute,
+	MaxAttempts:  3,
+	MinBackoff:   time.Hour,
+	MaxBackoff:   4 * time.Hour,
+	Retention:    time.Hour,
+	// The receivers listen on the loopback interface.
+	AllowPrivateHosts: true,
+}
+
+// receivedWebhook is a delivery as the receiver saw it; Verified tells whether its signature matched.
+type receivedWebhook struct {
+	Event    models.Event
+	Headers  http.Header
+	Verified bool
+}
+
+// receiver is a webhook endpoint that verifies the signature of every delivery and answers with status.
+type receiver struct {
+	server   *httptest.Server
+	status   atomic.Int64
+	received chan receivedWebhook
+}
+
+func newReceiver(t *testing.T) *receiver {
+	t.Helper()
+
+	r := &receiver{received: make(chan receivedWebhook, 10)}
+	r.status.Store(http.StatusNoContent)
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		received := receivedWebhook{Headers: req.Header, Verified: verify(req.Header, body)}
+		if err = json.Unmarshal(body, &received.Event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		r.received <- received
+
+		w.WriteHeader(int(r.status.Load()))
+	}))
+	t.Cleanup(r.server.Close)
+
+	return r
+}
+
+// verify checks the signature header the way a partner would: HMAC-SHA256 of "<t>.<body>" with a fresh t.
+func verify(headers http.Header, body []byte) bool {
+	var timestamp, signature string
+
+	for part := range strings.SplitSeq(headers.Get(events.HeaderWebhookSignature), ",") {
+		key, value, _ := strings.Cut(part, "=")
+
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(sent, 0)) > time.Minute {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(webhookSecret))
+	mac.Write([]byte(timestamp + "." + string(body)))
+
+	expected, err := hex.DecodeString(signature)
+
+	return err == nil && hmac.Equal(mac.Sum(nil), expected)
+}
+
+type webhookFixture struct {
+	fixture
+	webhooks  *memory.Webhooks
+	deliverer *events.WebhookDeliverer
+}
+
+func newWebhookFixture(t *testing.T, url string) webhookFixture {
+	t.Helper()
+
+	db := memory.New()
+	webhooks := memory.NewWebhooks(db)
+	f := webhookFixture{
+		fixture: fixture{
+			products:  memory.NewProducts(db),
+			outbox:    memory.NewOutbox(db),
+			delivered: prometheus.NewCounter(prometheus.CounterOpts{Name: "delivered"}),
+			failed:    prometheus.NewCounter(prometheus.CounterOpts{Name: "failed"}),
+		},
+		webhooks: webhooks,
+	}
+
+	var err error
+
+	f.dispatcher, err = events.NewDispatcher(f.outbox, []events.Sink{events.NewWebhookSink(webhooks)}, eventsConfig,
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "events"}),
+		prometheus.NewCounter(prometheus.CounterOpts{Name: "events_failed"}))
+	require.NoError(t, err)
+
+	f.deliverer, err = events.NewWebhookDeliverer(webhooks, webhooksConfig, f.delivered, f.failed)
+	require.NoError(t, err)
+
+	_, err = webhooks.AddWebhook(context.Background(), models.WebhookDto{
+		URL:        url,
+		EventTypes: []string{models.EventProductCreated},
+		Active:     true,
+	}, webhookSecret)
+	require.NoError(t, err)
+
+	return f
+}
+
+// deliver dispatches the outbox to the webhook queue and attempts the due deliveries.
+func (f webhookFixture) deliver(t *testing.T) int {
+	t.Helper()
+
+	_, err := f.dispatcher.Dispatch(context.Background())
+	require.NoError(t, err)
+
+	claimed, err := f.deliverer.Dispatch(context.Background())
+	require.NoError(t, err)
+
+	return claimed
+}
+
+func (f webhookFixture) delivery(t *testing.T) models.WebhookDeliveryDto {
+	t.Helper()
+
+	deliveries, err := f.webhooks.GetWebhookDeliveries(context.Background(), "1",
+		models.ListParams{Limit: 10, Sort: models.SortByCreatedAt})
+	require.NoError(t, err)
+	require.Len(t, deliveries.Items, 1)
+
+	delivery, err := f.webhooks.GetWebhookDelivery(context.Background(), "1", deliveries.Items[0].ID)
+	require.NoError(t, err)
+
+	return delivery
+}
+
+func TestWebhookDeliverer_DeliversSignedEvents(t *testing.T) {
+	t.Parallel()
+
+	r := newReceiver(t)
+	f := newWebhookFixture(t, r.server.URL)
+
+	bolt := f.addProduct(t, "B-1")
+
+	require.NoError(t, f.products.DeleteProduct(context.Background(), bolt.ID))
+
+	assert.Equal(t, 1, f.deliver(t), "only product.created is subscribed")
+
+	received := <-r.received
+	assert.True(t, received.Verified)
+	assert.Equal(t, models.EventProductCreated, received.Event.Type)
+	assert.Equal(t, bolt.ID, received.Event.Subject)
+	assert.Equal(t, models.EventProductCreated, received.Headers.Get(events.HeaderWebhookEvent))
+	assert.Equal(t, "application/json", received.Headers.Get("Content-Type"))
+
+	delivery := f.delivery(t)
+	assert.Equal(t, delivery.ID, received.Headers.Get(events.HeaderWebhookDelivery))
+	assert.Equal(t, models.DeliveryDelivered, delivery.Status)
+	require.Len(t, delivery.History, 1)
+	assert.Equal(t, http.StatusNoContent, delivery.History[0].StatusCode)
+	assert.Empty(t, delivery.History[0].Error)
+	assert.InDelta(t, 1, testutil.ToFloat64(f.delivered), 0)
+
+	assert.Zero(t, f.deliver(t), "a delivered event is not delivered again")
+}
+
+func TestWebhookDeliverer_RetriesWithBackoff(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	r := newReceiver(t)
+	r.status.Store(http.StatusInternalServerError)
+	f := newWebhookFixture(t, r.server.URL)
+
+	f.addProduct(t, "B-1")
+
+	assert.Equal(t, 1, f.deliver(t))
+
+	delivery := f.delivery(t)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Equal(t, "unexpected delivery response status 500", delivery.LastError)
+	require.NotNil(t, delivery.NextAttempt)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *delivery.NextAttempt, time.Minute)
+
+	assert.Zero(t, f.deliver(t), "the retry is not due yet")
+
+	// Redelivery makes the delivery due right away; the backoff doubles with every failed attempt.
+	_, err := f.webhooks.RedeliverWebhookDelivery(ctx, delivery.WebhookID, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.deliver(t))
+
+	delivery = f.delivery(t)
+	require.NotNil(t, delivery.NextAttempt)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), *delivery.NextAttempt, time.Minute)
+
+	_, err = f.webhooks.RedeliverWebhookDelivery(ctx, delivery.WebhookID, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.deliver(t))
+
+	delivery = f.delivery(t)
+	assert.Equal(t, models.DeliveryFailed, delivery.Status, "out of attempts")
+	assert.Nil(t, delivery.NextAttempt)
+	require.Len(t, delivery.History, 3)
+
+	for i, attempt := range delivery.History {
+		assert.Equal(t, i+1, attempt.Attempt)
+		assert.Equal(t, http.StatusInternalServerError, attempt.StatusCode)
+	}
+
+	r.status.Store(http.StatusOK)
+
+	_, err = f.webhooks.RedeliverWebhookDelivery(ctx, delivery.WebhookID, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.deliver(t))
+
+	delivery = f.delivery(t)
+	assert.Equal(t, models.DeliveryDelivered, delivery.Status)
+	assert.Equal(t, 4, delivery.Attempts)
+	assert.InDelta(t, 3, testutil.ToFloat64(f.failed), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(f.delivered), 0)
+
+	for range 4 {
+		assert.True(t, (<-r.received).Verified)
+	}
+}
+
+func TestWebhookDeliverer_DoesNotFollowRedirects(t *testing.T) {
+	t.Parallel()
+
+	r := newReceiver(t)
+	redirect := httptest.NewServer(http.RedirectHandler(r.server.URL, http.StatusFound))
+	t.Cleanup(redirect.Close)
+
+	f := newWebhookFixture(t, redirect.URL)
+	f.addProduct(t, "B-1")
+
+	assert.Equal(t, 1, f.deliver(t))
+
+	delivery := f.delivery(t)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	require.Len(t, delivery.History, 1)
+	assert.Equal(t, http.StatusFound, delivery.History[0].StatusCode)
+	assert.Empty(t, r.received)
+}
+
+func TestWebhookDeliverer_RefusesPrivateAddresses(t *testing.T) {
+	t.Parallel()
+
+	r := newReceiver(t)
+	f := newWebhookFixture(t, r.server.URL)
+
+	cfg := webhooksConfig
+	cfg.AllowPrivateHosts = false
+
+	var err error
+
+	f.deliverer, err = events.NewWebhookDeliverer(f.webhooks, cfg, f.delivered, f.failed)
+	require.NoError(t, err)
+
+	f.addProduct(t, "B-1")
+
+	assert.Equal(t, 1, f.deliver(t))
+
+	delivery := f.delivery(t)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	require.Len(t, delivery.History, 1)
+	assert.Zero(t, delivery.History[0].StatusCode)
+	assert.Contains(t, delivery.History[0].Error, events.ErrDeliveryAddress.Error())
+	assert.Empty(t, r.received)
+}
+
+func TestWebhookDeliverer_KeepsURLOutOfErrors(t *testing.T) {
+	t.Parallel()
+
+	r := newReceiver(t)
+	r.server.Close()
+
+	target := r.server.URL + "/hooks?token=s3cr3t"
+	f := newWebhookFixture(t, target)
+	f.addProduct(t, "B-1")
+
+	var logs bytes.Buffer
+
+	ctx := logger.WithContext(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)))
+
+	_, err := f.dispatcher.Dispatch(ctx)
+	require.NoError(t, err)
+
+	claimed, err := f.deliverer.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+
+	delivery := f.delivery(t)
+	require.Len(t, delivery.History, 1)
+	assert.Contains(t, delivery.History[0].Error, "failed to post webhook to "+r.server.Listener.Addr().String())
+	assert.NotContains(t, delivery.History[0].Error, "s3cr3t")
+	assert.Contains(t, logs.String(), "Failed to deliver webhook")
+	assert.NotContains(t, logs.String(), "s3cr3t")
+}
+
+func TestNewWebhookDeliverer_RejectsBadConfig(t *testing.T) {
+	t.Parallel()
+
+	for _, breakConfig := range []func(*config.WebhooksConfig){
+		func(cfg *config.WebhooksConfig) { cfg.BatchSize = 0 },
+		func(cfg *config.WebhooksConfig) { cfg.MaxAttempts = 0 },
+		func(cfg *config.WebhooksConfig) { cfg.Timeout = 0 },
+		func(cfg *config.WebhooksConfig) { cfg.MinBackoff = 0 },
+		func(cfg *config.WebhooksConfig) { cfg.MaxBackoff = time.Minute },
+	} {
+		cfg := webhooksConfig
+		breakConfig(&cfg)
+
+		_, err := events.NewWebhookDeliverer(memory.NewWebhooks(memory.New()), cfg, nil, nil)
+		require.ErrorIs(t, err, events.ErrEventsConfig)
+	}
+}
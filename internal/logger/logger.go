@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"log/slog"
 	"os"
 )
@@ -35,3 +36,19 @@ func SetupLogger(envType string) *slog.Logger {
 
 	return log
 }
+
+type contextKey struct{}
+
+// WithContext stores a request-scoped logger, e.g. one carrying the request ID.
+func WithContext(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
+}
+
+// FromContext returns the request-scoped logger, or slog.Default outside of a request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if log, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return log
+	}
+
+	return slog.Default()
+}
@@ -0,0 +1,134 @@
+// Package metrics collects the Prometheus metrics of the service and exposes them in the text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "tradeservice"
+
+type Metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+
+	ProductsCreated   prometheus.Counter
+	CategoriesDeleted prometheus.Counter
+	// CacheHits and CacheMisses count the cached reads by repository.
+	CacheHits   *prometheus.CounterVec
+	CacheMisses *prometheus.CounterVec
+	// EventsDelivered and EventDeliveryFailures count the outbox events handed to every sink and the
+	// attempts that failed at some sink.
+	EventsDelivered       prometheus.Counter
+	EventDeliveryFailures prometheus.Counter
+	// WebhooksDelivered and WebhookDeliveryFailures count the webhook deliveries that succeeded and the
+	// attempts that failed.
+	WebhooksDelivered       prometheus.Counter
+	WebhookDeliveryFailures prometheus.Counter
+}
+
+func New() *Metrics {
+	labels := []string{"method", "route", "status"}
+
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by route template and status.",
+		}, labels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests by route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		ProductsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "products_created_total",
+			Help:      "Number of products created.",
+		}),
+		CategoriesDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "categories_deleted_total",
+			Help:      "Number of categories deleted.",
+		}),
+		CacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_hits_total",
+			Help:      "Number of reads served from the cache by repository.",
+		}, []string{"repository"}),
+		CacheMisses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_misses_total",
+			Help:      "Number of cached reads that went to the storage by repository.",
+		}, []string{"repository"}),
+		EventsDelivered: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_delivered_total",
+			Help:      "Number of events delivered to the sinks.",
+		}),
+		EventDeliveryFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "event_delivery_failures_total",
+			Help:      "Number of failed event delivery attempts.",
+		}),
+		WebhooksDelivered: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhooks_delivered_total",
+			Help:      "Number of webhook deliveries that succeeded.",
+		}),
+		WebhookDeliveryFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhook_delivery_failures_total",
+			Help:      "Number of failed webhook delivery attempts.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.latency,
+		m.ProductsCreated,
+		m.CategoriesDeleted,
+		m.CacheHits,
+		m.CacheMisses,
+		m.EventsDelivered,
+		m.EventDeliveryFailures,
+		m.WebhooksDelivered,
+		m.WebhookDeliveryFailures,
+	)
+
+	return m
+}
+
+// Register adds collectors owned by other components, e.g. the DB pool.
+func (m *Metrics) Register(collectors ...prometheus.Collector) error {
+	for _, collector := range collectors {
+		if err := m.registry.Register(collector); err != nil {
+			return fmt.Errorf("failed to register collector %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ObserveRequest records a served request; route is the route template, not the raw URL, to bound cardinality.
+func (m *Metrics) ObserveRequest(method string, route string, status int, elapsed time.Duration) {
+	code := strconv.Itoa(status)
+
+	m.requests.WithLabelValues(method, route, code).Inc()
+	m.latency.WithLabelValues(method, route, code).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE SEQUENCE products_id_seq OWNED BY products.id;
+SELECT setval('products_id_seq', COALESCE((SELECT MAX(id) FROM products), 0) + 1, false);
+ALTER TABLE products ALTER COLUMN id SET DEFAULT nextval('products_id_seq');
+
+ALTER TABLE products
+    ADD COLUMN sku         TEXT,
+    ADD COLUMN description TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN unit_price  BIGINT  NOT NULL DEFAULT 0 CHECK (unit_price >= 0),
+    ADD COLUMN currency    CHAR(3) NOT NULL DEFAULT 'USD' CHECK (currency ~ '^[A-Z]{3}$'),
+    ADD COLUMN active      BOOLEAN NOT NULL DEFAULT TRUE;
+
+UPDATE products SET sku = 'SKU-' || id WHERE sku IS NULL;
+
+ALTER TABLE products ALTER COLUMN sku SET NOT NULL;
+ALTER TABLE products ADD CONSTRAINT products_sku_key UNIQUE (sku);
+
+-- +goose Down
+ALTER TABLE products
+    DROP COLUMN sku,
+    DROP COLUMN description,
+    DROP COLUMN unit_price,
+    DROP COLUMN currency,
+    DROP COLUMN active;
+
+ALTER TABLE products ALTER COLUMN id DROP DEFAULT;
+DROP SEQUENCE products_id_seq;
@@ -0,0 +1,8 @@
+-- +goose Up
+CREATE SEQUENCE categories_id_seq OWNED BY categories.id;
+SELECT setval('categories_id_seq', COALESCE((SELECT MAX(id) FROM categories), 0) + 1, false);
+ALTER TABLE categories ALTER COLUMN id SET DEFAULT nextval('categories_id_seq');
+
+-- +goose Down
+ALTER TABLE categories ALTER COLUMN id DROP DEFAULT;
+DROP SEQUENCE categories_id_seq;
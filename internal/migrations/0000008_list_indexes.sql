@@ -0,0 +1,41 @@
+-- +goose Up
+ALTER TABLE products
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN created_at SET DEFAULT now(),
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN updated_at SET DEFAULT now();
+
+ALTER TABLE categories
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN created_at SET DEFAULT now(),
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN updated_at SET DEFAULT now();
+
+CREATE INDEX products_name_id_idx ON products (name, id);
+CREATE INDEX products_created_at_id_idx ON products (created_at, id);
+CREATE INDEX products_updated_at_id_idx ON products (updated_at, id);
+
+CREATE INDEX categories_name_id_idx ON categories (name, id);
+CREATE INDEX categories_created_at_id_idx ON categories (created_at, id);
+CREATE INDEX categories_updated_at_id_idx ON categories (updated_at, id);
+
+-- +goose Down
+DROP INDEX categories_updated_at_id_idx;
+DROP INDEX categories_created_at_id_idx;
+DROP INDEX categories_name_id_idx;
+
+DROP INDEX products_updated_at_id_idx;
+DROP INDEX products_created_at_id_idx;
+DROP INDEX products_name_id_idx;
+
+ALTER TABLE categories
+    ALTER COLUMN created_at DROP DEFAULT,
+    ALTER COLUMN created_at TYPE DATE,
+    ALTER COLUMN updated_at DROP DEFAULT,
+    ALTER COLUMN updated_at TYPE DATE;
+
+ALTER TABLE products
+    ALTER COLUMN created_at DROP DEFAULT,
+    ALTER COLUMN created_at TYPE DATE,
+    ALTER COLUMN updated_at DROP DEFAULT,
+    ALTER COLUMN updated_at TYPE DATE;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE categories ADD COLUMN parent_id INTEGER REFERENCES categories (id) ON DELETE RESTRICT;
+
+CREATE INDEX categories_parent_id_idx ON categories (parent_id);
+
+-- +goose Down
+DROP INDEX categories_parent_id_idx;
+
+ALTER TABLE categories DROP COLUMN parent_id;
@@ -0,0 +1,44 @@
+-- +goose Up
+CREATE TABLE product_categories (
+    product_id  INTEGER     NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    category_id INTEGER     NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX product_categories_category_id_idx ON product_categories (category_id);
+
+-- Categories used to be duplicated once per product; keep the oldest row of each name within a parent.
+CREATE TEMPORARY TABLE category_duplicates AS
+SELECT id, min(id) OVER (PARTITION BY name, parent_id) AS keep_id
+FROM categories;
+
+INSERT INTO product_categories (product_id, category_id)
+SELECT DISTINCT p.id, d.keep_id
+FROM categories c
+    JOIN category_duplicates d ON d.id = c.id
+    JOIN products p ON p.id::text = c.product_id;
+
+UPDATE categories c SET parent_id = d.keep_id
+FROM category_duplicates d
+WHERE c.parent_id = d.id AND d.id <> d.keep_id;
+
+DELETE FROM categories c
+USING category_duplicates d
+WHERE c.id = d.id AND d.id <> d.keep_id;
+
+DROP TABLE category_duplicates;
+
+ALTER TABLE categories DROP COLUMN product_id;
+
+CREATE UNIQUE INDEX categories_parent_name_key ON categories (COALESCE(parent_id, 0), name);
+
+-- +goose Down
+DROP INDEX categories_parent_name_key;
+
+ALTER TABLE categories ADD COLUMN product_id TEXT;
+
+UPDATE categories c
+SET product_id = (SELECT min(pc.product_id)::text FROM product_categories pc WHERE pc.category_id = c.id);
+
+DROP TABLE product_categories;
@@ -0,0 +1,40 @@
+-- +goose Up
+CREATE TABLE inventory_levels (
+    product_id INTEGER     PRIMARY KEY REFERENCES products (id) ON DELETE CASCADE,
+    on_hand    BIGINT      NOT NULL DEFAULT 0,
+    reserved   BIGINT      NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT inventory_levels_on_hand_check CHECK (on_hand >= 0),
+    CONSTRAINT inventory_levels_reserved_check CHECK (reserved >= 0 AND reserved <= on_hand)
+);
+
+CREATE TABLE stock_movements (
+    id            BIGSERIAL   PRIMARY KEY,
+    product_id    INTEGER     NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    movement_type TEXT        NOT NULL CHECK (movement_type IN ('receipt', 'sale', 'adjustment', 'return')),
+    quantity      BIGINT      NOT NULL CHECK (quantity <> 0),
+    reason        TEXT        NOT NULL DEFAULT '',
+    on_hand_after BIGINT      NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX stock_movements_product_created_idx ON stock_movements (product_id, created_at, id);
+
+-- The ledger is append-only; rows only disappear together with their product.
+-- +goose StatementBegin
+CREATE FUNCTION stock_movements_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER stock_movements_no_update
+    BEFORE UPDATE ON stock_movements
+    FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
+
+-- +goose Down
+DROP TRIGGER stock_movements_no_update ON stock_movements;
+DROP FUNCTION stock_movements_append_only();
+DROP TABLE stock_movements;
+DROP TABLE inventory_levels;
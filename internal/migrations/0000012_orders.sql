@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE orders (
+    id         BIGSERIAL   PRIMARY KEY,
+    status     TEXT        NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'placed', 'paid', 'shipped', 'delivered', 'cancelled', 'refunded')),
+    currency   CHAR(3)     NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    total      BIGINT      NOT NULL CHECK (total >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX orders_created_at_id_idx ON orders (created_at, id);
+CREATE INDEX orders_updated_at_id_idx ON orders (updated_at, id);
+
+CREATE TABLE order_lines (
+    order_id   BIGINT  NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    line_no    INTEGER NOT NULL,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE RESTRICT,
+    quantity   BIGINT  NOT NULL CHECK (quantity > 0),
+    unit_price BIGINT  NOT NULL CHECK (unit_price >= 0),
+    line_total BIGINT  NOT NULL CHECK (line_total >= 0),
+    PRIMARY KEY (order_id, line_no)
+);
+
+CREATE INDEX order_lines_product_id_idx ON order_lines (product_id);
+
+-- +goose Down
+DROP TABLE order_lines;
+DROP TABLE orders;
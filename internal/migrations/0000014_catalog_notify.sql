@@ -0,0 +1,29 @@
+-- +goose Up
+-- Instances caching catalog reads LISTEN on catalog_changed to drop what other instances changed.
+-- Notifications are delivered on commit, once per table and transaction.
+-- +goose StatementBegin
+CREATE FUNCTION notify_catalog_changed() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('catalog_changed', TG_TABLE_NAME);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER products_notify
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON products
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_catalog_changed();
+
+CREATE TRIGGER categories_notify
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON categories
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_catalog_changed();
+
+CREATE TRIGGER product_categories_notify
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON product_categories
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_catalog_changed();
+
+-- +goose Down
+DROP TRIGGER product_categories_notify ON product_categories;
+DROP TRIGGER categories_notify ON categories;
+DROP TRIGGER products_notify ON products;
+DROP FUNCTION notify_catalog_changed();
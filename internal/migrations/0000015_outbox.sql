@@ -0,0 +1,20 @@
+-- +goose Up
+-- Domain events of the catalog, written in the same transaction as the change and delivered by the
+-- dispatcher at least once. next_attempt_at is both the retry time and the lease of a claimed event.
+CREATE TABLE outbox_events (
+    id              BIGSERIAL   PRIMARY KEY,
+    event_type      TEXT        NOT NULL,
+    subject         TEXT        NOT NULL,
+    data            JSONB       NOT NULL,
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    last_error      TEXT        NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX outbox_events_pending_idx ON outbox_events (next_attempt_at, id) WHERE delivered_at IS NULL;
+CREATE INDEX outbox_events_delivered_idx ON outbox_events (delivered_at) WHERE delivered_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE outbox_events;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Ledger rows may only be deleted by the cascade of their product's deletion, which runs once the product
+-- row is gone.
+-- +goose StatementBegin
+CREATE FUNCTION stock_movements_no_delete() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM products WHERE id = OLD.product_id) THEN
+        RAISE EXCEPTION 'stock_movements is append-only';
+    END IF;
+
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER stock_movements_no_delete
+    BEFORE DELETE ON stock_movements
+    FOR EACH ROW EXECUTE FUNCTION stock_movements_no_delete();
+
+-- +goose Down
+DROP TRIGGER stock_movements_no_delete ON stock_movements;
+DROP FUNCTION stock_movements_no_delete();
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Serves the keyset pages of the deliveries of a webhook.
+CREATE INDEX webhook_deliveries_webhook_created_idx ON webhook_deliveries (webhook_id, created_at, id);
+
+-- +goose Down
+DROP INDEX webhook_deliveries_webhook_created_idx;
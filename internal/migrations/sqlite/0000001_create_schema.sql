@@ -0,0 +1,113 @@
+-- +goose Up
+-- The schema of the postgres migrations in one step. Timestamps are microseconds since the Unix epoch,
+-- booleans are 0 or 1 and the scopes of an API key are a JSON array. References that block a delete use the
+-- default NO ACTION: SQLite reports ON DELETE RESTRICT as a trigger error rather than a foreign key error.
+CREATE TABLE categories (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       TEXT    NOT NULL,
+    parent_id  INTEGER REFERENCES categories (id),
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX categories_parent_id_idx ON categories (parent_id);
+CREATE UNIQUE INDEX categories_parent_name_key ON categories (COALESCE(parent_id, 0), name);
+
+CREATE TABLE products (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        TEXT    NOT NULL,
+    sku         TEXT    NOT NULL UNIQUE,
+    description TEXT    NOT NULL DEFAULT '',
+    unit_price  INTEGER NOT NULL DEFAULT 0 CHECK (unit_price >= 0),
+    currency    TEXT    NOT NULL DEFAULT 'USD' CHECK (currency GLOB '[A-Z][A-Z][A-Z]'),
+    active      INTEGER NOT NULL DEFAULT 1 CHECK (active IN (0, 1)),
+    created_at  INTEGER NOT NULL,
+    updated_at  INTEGER NOT NULL
+);
+
+CREATE TABLE product_categories (
+    product_id  INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    created_at  INTEGER NOT NULL,
+    PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX product_categories_category_id_idx ON product_categories (category_id);
+
+CREATE TABLE inventory_levels (
+    product_id INTEGER PRIMARY KEY REFERENCES products (id) ON DELETE CASCADE,
+    on_hand    INTEGER NOT NULL DEFAULT 0,
+    reserved   INTEGER NOT NULL DEFAULT 0,
+    updated_at INTEGER NOT NULL,
+    CONSTRAINT inventory_levels_on_hand_check CHECK (on_hand >= 0),
+    CONSTRAINT inventory_levels_reserved_check CHECK (reserved >= 0 AND reserved <= on_hand)
+);
+
+CREATE TABLE stock_movements (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id    INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    movement_type TEXT    NOT NULL CHECK (movement_type IN ('receipt', 'sale', 'adjustment', 'return')),
+    quantity      INTEGER NOT NULL CHECK (quantity <> 0),
+    reason        TEXT    NOT NULL DEFAULT '',
+    on_hand_after INTEGER NOT NULL,
+    created_at    INTEGER NOT NULL
+);
+
+CREATE INDEX stock_movements_product_created_idx ON stock_movements (product_id, created_at, id);
+
+-- The ledger is append-only; rows only disappear together with their product.
+-- +goose StatementBegin
+CREATE TRIGGER stock_movements_no_update BEFORE UPDATE ON stock_movements
+BEGIN
+    SELECT RAISE(ABORT, 'stock_movements is append-only');
+END;
+-- +goose StatementEnd
+
+CREATE TABLE orders (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    status     TEXT    NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'placed', 'paid', 'shipped', 'delivered', 'cancelled', 'refunded')),
+    currency   TEXT    NOT NULL CHECK (currency GLOB '[A-Z][A-Z][A-Z]'),
+    total      INTEGER NOT NULL CHECK (total >= 0),
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX orders_created_at_id_idx ON orders (created_at, id);
+CREATE INDEX orders_updated_at_id_idx ON orders (updated_at, id);
+
+CREATE TABLE order_lines (
+    order_id   INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    line_no    INTEGER NOT NULL,
+    product_id INTEGER NOT NULL REFERENCES products (id),
+    quantity   INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price INTEGER NOT NULL CHECK (unit_price >= 0),
+    line_total INTEGER NOT NULL CHECK (line_total >= 0),
+    PRIMARY KEY (order_id, line_no)
+);
+
+CREATE INDEX order_lines_product_id_idx ON order_lines (product_id);
+
+CREATE TABLE api_keys (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    name         TEXT    NOT NULL,
+    prefix       TEXT    NOT NULL,
+    key_hash     BLOB    NOT NULL UNIQUE,
+    scopes       TEXT    NOT NULL DEFAULT '[]',
+    expires_at   INTEGER,
+    last_used_at INTEGER,
+    revoked_at   INTEGER,
+    created_at   INTEGER NOT NULL,
+    updated_at   INTEGER NOT NULL
+);
+
+-- +goose Down
+DROP TABLE api_keys;
+DROP TABLE order_lines;
+DROP TABLE orders;
+DROP TRIGGER stock_movements_no_update;
+DROP TABLE stock_movements;
+DROP TABLE inventory_levels;
+DROP TABLE product_categories;
+DROP TABLE products;
+DROP TABLE categories;
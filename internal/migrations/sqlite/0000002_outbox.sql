@@ -0,0 +1,19 @@
+-- +goose Up
+-- Domain events of the catalog, see the postgres migration 0000015. data is the JSON of the event.
+CREATE TABLE outbox_events (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_type      TEXT    NOT NULL,
+    subject         TEXT    NOT NULL,
+    data            TEXT    NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT    NOT NULL DEFAULT '',
+    next_attempt_at INTEGER NOT NULL,
+    delivered_at    INTEGER,
+    created_at      INTEGER NOT NULL
+);
+
+CREATE INDEX outbox_events_pending_idx ON outbox_events (next_attempt_at, id) WHERE delivered_at IS NULL;
+CREATE INDEX outbox_events_delivered_idx ON outbox_events (delivered_at) WHERE delivered_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE outbox_events;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- See the postgres migration 0000017: ledger rows only go with the deletion of their product.
+-- +goose StatementBegin
+CREATE TRIGGER stock_movements_no_delete BEFORE DELETE ON stock_movements
+WHEN EXISTS (SELECT 1 FROM products WHERE id = OLD.product_id)
+BEGIN
+    SELECT RAISE(ABORT, 'stock_movements is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER stock_movements_no_delete;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- See the postgres migration 0000018.
+CREATE INDEX webhook_deliveries_webhook_created_idx ON webhook_deliveries (webhook_id, created_at, id);
+
+-- +goose Down
+DROP INDEX webhook_deliveries_webhook_created_idx;
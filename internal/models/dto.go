@@ -1,12 +1,156 @@
 package models
 
+import "time"
+
 type CategoryDto struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	ProductID string `json:"productId"`
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	ParentID *string   `json:"parentId"`
+	Created  time.Time `json:"createdAt"`
+	Updated  time.Time `json:"updatedAt"`
+}
+
+// CategoryTree is a category together with all of its descendants.
+type CategoryTree struct {
+	CategoryDto
+	Children []CategoryTree `json:"children"`
+}
+
+// CategoryMove names the new parent of a category; a nil ParentID makes it a root category.
+type CategoryMove struct {
+	ParentID *string `json:"parentId"`
 }
 
 type ProductDto struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	SKU         string `json:"sku"`
+	Description string `json:"description"`
+	// UnitPrice is expressed in minor units of Currency (e.g. cents for USD).
+	UnitPrice int64     `json:"unitPrice"`
+	Currency  string    `json:"currency"`
+	Active    bool      `json:"active"`
+	Created   time.Time `json:"createdAt"`
+	Updated   time.Time `json:"updatedAt"`
+}
+
+// ProductPatch carries a partial product update; nil fields are left unchanged.
+type ProductPatch struct {
+	Name        *string `json:"name"`
+	SKU         *string `json:"sku"`
+	Description *string `json:"description"`
+	UnitPrice   *int64  `json:"unitPrice"`
+	Currency    *string `json:"currency"`
+	Active      *bool   `json:"active"`
+}
+
+const (
+	MovementReceipt    = "receipt"
+	MovementSale       = "sale"
+	MovementAdjustment = "adjustment"
+	MovementReturn     = "return"
+)
+
+type StockLevelDto struct {
+	ProductID string    `json:"productId"`
+	OnHand    int64     `json:"onHand"`
+	Reserved  int64     `json:"reserved"`
+	Available int64     `json:"available"`
+	Updated   time.Time `json:"updatedAt"`
+}
+
+// StockMovementDto is an entry of the append-only stock ledger. Clients send a positive Quantity for
+// receipts, sales and returns and a signed one for adjustments; the ledger stores the signed change.
+// FulfilsReservation marks a sale of reserved stock; it is part of the request only and isn't stored.
+type StockMovementDto struct {
+	ID                 string    `json:"id"`
+	ProductID          string    `json:"productId"`
+	Type               string    `json:"type"`
+	Quantity           int64     `json:"quantity"`
+	Reason             string    `json:"reason"`
+	FulfilsReservation bool      `json:"fulfilsReservation,omitempty"`
+	OnHandAfter        int64     `json:"onHandAfter"`
+	Created            time.Time `json:"createdAt"`
+}
+
+// Released is the reserved stock a movement fulfils: the sold quantity of a sale that fulfils a reservation,
+// none otherwise.
+func (m StockMovementDto) Released() int64 {
+	if m.Type == MovementSale && m.FulfilsReservation {
+		return -m.Quantity
+	}
+
+	return 0
+}
+
+type StockReservation struct {
+	Quantity int64 `json:"quantity"`
+}
+
+const (
+	OrderDraft     = "draft"
+	OrderPlaced    = "placed"
+	OrderPaid      = "paid"
+	OrderShipped   = "shipped"
+	OrderDelivered = "delivered"
+	OrderCancelled = "cancelled"
+	OrderRefunded  = "refunded"
+)
+
+// OrderDto is an order with amounts in minor units of Currency. Lists of orders omit the lines.
+type OrderDto struct {
+	ID       string         `json:"id"`
+	Status   string         `json:"status"`
+	Currency string         `json:"currency"`
+	Total    int64          `json:"total"`
+	Lines    []OrderLineDto `json:"lines,omitempty"`
+	Created  time.Time      `json:"createdAt"`
+	Updated  time.Time      `json:"updatedAt"`
+}
+
+type OrderLineDto struct {
+	ProductID string `json:"productId"`
+	Quantity  int64  `json:"quantity"`
+	UnitPrice int64  `json:"unitPrice"`
+	LineTotal int64  `json:"lineTotal"`
+}
+
+type OrderStatusChange struct {
+	Status string `json:"status"`
+}
+
+// APIKeyDto describes an API key without its secret; only the prefix is kept so admins can tell keys apart.
+type APIKeyDto struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Prefix   string     `json:"prefix"`
+	Scopes   []string   `json:"scopes"`
+	Expires  *time.Time `json:"expiresAt,omitempty"`
+	LastUsed *time.Time `json:"lastUsedAt,omitempty"`
+	Revoked  *time.Time `json:"revokedAt,omitempty"`
+	Created  time.Time  `json:"createdAt"`
+	Updated  time.Time  `json:"updatedAt"`
+}
+
+// APIKeySecret is returned once when a key is created or rotated; the plaintext key is never stored.
+type APIKeySecret struct {
+	APIKeyDto
+	Key string `json:"key"`
+}
+
+const (
+	HealthOK   = "ok"
+	HealthFail = "fail"
+)
+
+// HealthReport is the outcome of a liveness or readiness probe together with the result of each check.
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"strings"
 )
 
 var (
@@ -9,4 +10,33 @@ var (
 	ErrNotFound             = errors.New("not found")
 	ErrDB                   = errors.New("db error")
 	ErrDBConnectionCreation = errors.New("db connection creation error")
+	ErrValidation           = errors.New("validation error")
+	ErrConflict             = errors.New("conflict")
+	ErrUnauthorized         = errors.New("unauthorized")
+	ErrForbidden            = errors.New("forbidden")
+	ErrRateLimited          = errors.New("rate limit exceeded")
 )
+
+// FieldError explains why a single field of a request was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError carries the rejected fields of a request; it matches ErrValidation with errors.Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		msgs = append(msgs, field.Field+": "+field.Message)
+	}
+
+	return ErrValidation.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event types of the catalog. Created and updated events carry the product or category, the others one of
+// the Event*Data types.
+const (
+	EventProductCreated    = "product.created"
+	EventProductUpdated    = "product.updated"
+	EventProductRenamed    = "product.renamed"
+	EventProductDeleted    = "product.deleted"
+	EventCategoryCreated   = "category.created"
+	EventCategoryRenamed   = "category.renamed"
+	EventCategoryMoved     = "category.moved"
+	EventCategoryDeleted   = "category.deleted"
+	EventProductAssigned   = "category.product_assigned"
+	EventProductUnassigned = "category.product_unassigned"
+)
+
+// EventTypes lists every event type.
+var EventTypes = []string{
+	EventProductCreated, EventProductUpdated, EventProductRenamed, EventProductDeleted,
+	EventCategoryCreated, EventCategoryRenamed, EventCategoryMoved, EventCategoryDeleted,
+	EventProductAssigned, EventProductUnassigned,
+}
+
+// Event is a domain event, recorded in the outbox in the same transaction as the change it describes.
+// Subject is the ID of the changed product or category; IDs grow in the order the events were recorded.
+type Event struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Subject string          `json:"subject"`
+	Data    json.RawMessage `json:"data"`
+	Created time.Time       `json:"createdAt"`
+}
+
+// OutboxEvent is an event of the outbox with its failed delivery attempts.
+type OutboxEvent struct {
+	Event
+	Attempts  int
+	LastError string
+}
+
+type EventRenameData struct {
+	ID      string `json:"id"`
+	OldName string `json:"oldName"`
+	Name    string `json:"name"`
+}
+
+type EventMoveData struct {
+	ID          string  `json:"id"`
+	OldParentID *string `json:"oldParentId"`
+	ParentID    *string `json:"parentId"`
+}
+
+type EventDeleteData struct {
+	ID string `json:"id"`
+}
+
+type EventAssignmentData struct {
+	CategoryID string `json:"categoryId"`
+	ProductID  string `json:"productId"`
+}
+
+// NewEvent builds an event to record; the outbox assigns its ID and creation time.
+func NewEvent(eventType string, subject string, data any) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to encode %s event %w", eventType, err)
+	}
+
+	return Event{Type: eventType, Subject: subject, Data: raw}, nil
+}
+
+// ProductUpdateEvents are the events of an update of product, whose name was oldName before.
+func ProductUpdateEvents(product ProductDto, oldName string) ([]Event, error) {
+	updated, err := NewEvent(EventProductUpdated, product.ID, product)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.Name == oldName {
+		return []Event{updated}, nil
+	}
+
+	renamed, err := NewEvent(EventProductRenamed, product.ID,
+		EventRenameData{ID: product.ID, OldName: oldName, Name: product.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	return []Event{updated, renamed}, nil
+}
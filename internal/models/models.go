@@ -3,16 +3,69 @@ package models
 import "time"
 
 type Category struct {
-	ID        string    `db:"id"`
-	Name      string    `db:"name"`
+	ID       string    `db:"id"`
+	Name     string    `db:"name"`
+	ParentID *string   `db:"parent_id"`
+	Created  time.Time `db:"created_at"`
+	Updated  time.Time `db:"updated_at"`
+}
+
+type Product struct {
+	ID          string    `db:"id"`
+	Name        string    `db:"name"`
+	SKU         string    `db:"sku"`
+	Description string    `db:"description"`
+	UnitPrice   int64     `db:"unit_price"`
+	Currency    string    `db:"currency"`
+	Active      bool      `db:"active"`
+	Created     time.Time `db:"created_at"`
+	Updated     time.Time `db:"updated_at"`
+}
+
+type StockLevel struct {
 	ProductID string    `db:"product_id"`
-	Created   time.Time `db:"created_at"`
+	OnHand    int64     `db:"on_hand"`
+	Reserved  int64     `db:"reserved"`
 	Updated   time.Time `db:"updated_at"`
 }
 
-type Product struct {
-	ID      string    `db:"id"`
-	Name    string    `db:"name"`
-	Created time.Time `db:"created_at"`
-	Updated time.Time `db:"updated_at"`
+type StockMovement struct {
+	ID          string    `db:"id"`
+	ProductID   string    `db:"product_id"`
+	Type        string    `db:"movement_type"`
+	Quantity    int64     `db:"quantity"`
+	Reason      string    `db:"reason"`
+	OnHandAfter int64     `db:"on_hand_after"`
+	Created     time.Time `db:"created_at"`
+}
+
+type Order struct {
+	ID       string    `db:"id"`
+	Status   string    `db:"status"`
+	Currency string    `db:"currency"`
+	Total    int64     `db:"total"`
+	Created  time.Time `db:"created_at"`
+	Updated  time.Time `db:"updated_at"`
+}
+
+type OrderLine struct {
+	OrderID   string `db:"order_id"`
+	LineNo    int    `db:"line_no"`
+	ProductID string `db:"product_id"`
+	Quantity  int64  `db:"quantity"`
+	UnitPrice int64  `db:"unit_price"`
+	LineTotal int64  `db:"line_total"`
+}
+
+type APIKey struct {
+	ID       string     `db:"id"`
+	Name     string     `db:"name"`
+	Prefix   string     `db:"prefix"`
+	Hash     []byte     `db:"key_hash"`
+	Scopes   []string   `db:"scopes"`
+	Expires  *time.Time `db:"expires_at"`
+	LastUsed *time.Time `db:"last_used_at"`
+	Revoked  *time.Time `db:"revoked_at"`
+	Created  time.Time  `db:"created_at"`
+	Updated  time.Time  `db:"updated_at"`
 }
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+const (
+	SortByName      = "name"
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+)
+
+// ListParams controls keyset pagination, sorting and filtering of list endpoints.
+// Cursor is the opaque value received from a client; After is its decoded form.
+type ListParams struct {
+	Limit        int
+	Cursor       string
+	Sort         string
+	Desc         bool
+	After        *Cursor
+	NamePrefix   string
+	CreatedAfter *time.Time
+	CategoryID   string
+	// IncludeSubcategories widens the CategoryID filter to the whole subtree of the category.
+	IncludeSubcategories bool
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Cursor points at the last row of a page: the value of the sort column and the row ID as tie-breaker.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// Normalize applies defaults, parses the "-field" descending sort notation and decodes the cursor.
+func (p ListParams) Normalize() (ListParams, error) {
+	switch {
+	case p.Limit == 0:
+		p.Limit = DefaultPageLimit
+	case p.Limit < 0 || p.Limit > MaxPageLimit:
+		return p, fmt.Errorf("%w: limit must be between 1 and %d", ErrValidation, MaxPageLimit)
+	}
+
+	if strings.HasPrefix(p.Sort, "-") {
+		p.Sort = strings.TrimPrefix(p.Sort, "-")
+		p.Desc = true
+	}
+
+	switch p.Sort {
+	case "":
+		p.Sort = SortByCreatedAt
+	case SortByName, SortByCreatedAt, SortByUpdatedAt:
+	default:
+		return p, fmt.Errorf("%w: unsupported sort %q", ErrValidation, p.Sort)
+	}
+
+	if p.Cursor == "" {
+		return p, nil
+	}
+
+	cursor, err := DecodeCursor(p.Cursor)
+	if err != nil {
+		return p, err
+	}
+
+	if cursor.Sort != p.Sort || cursor.Desc != p.Desc {
+		return p, fmt.Errorf("%w: cursor does not match sort", ErrValidation)
+	}
+
+	p.After = &cursor
+
+	return p, nil
+}
+
+func EncodeCursor(cursor Cursor) string {
+	raw, _ := json.Marshal(cursor) //nolint:errchkjson // Cursor only holds strings and a bool
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(encoded string) (Cursor, error) {
+	var cursor Cursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, fmt.Errorf("%w: malformed cursor", ErrValidation)
+	}
+
+	if err = json.Unmarshal(raw, &cursor); err != nil || cursor.ID == "" {
+		return cursor, fmt.Errorf("%w: malformed cursor", ErrValidation)
+	}
+
+	return cursor, nil
+}
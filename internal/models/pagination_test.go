@@ -0,0 +1,48 @@
+package models_test
+
+import (
+	"testing"
+	"tradeservice/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListParams_Normalize_Defaults(t *testing.T) {
+	t.Parallel()
+
+	params, err := models.ListParams{}.Normalize()
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultPageLimit, params.Limit)
+	assert.Equal(t, models.SortByCreatedAt, params.Sort)
+	assert.False(t, params.Desc)
+	assert.Nil(t, params.After)
+}
+
+func TestListParams_Normalize_Cursor(t *testing.T) {
+	t.Parallel()
+
+	cursor := models.Cursor{Sort: models.SortByName, Desc: true, Value: "Lenovo", ID: "2"}
+
+	params, err := models.ListParams{Sort: "-name", Cursor: models.EncodeCursor(cursor)}.Normalize()
+	require.NoError(t, err)
+	require.NotNil(t, params.After)
+	assert.Equal(t, cursor, *params.After)
+
+	_, err = models.ListParams{Sort: "name", Cursor: models.EncodeCursor(cursor)}.Normalize()
+	require.ErrorIs(t, err, models.ErrValidation)
+}
+
+func TestListParams_Normalize_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, params := range []models.ListParams{
+		{Limit: -1},
+		{Limit: models.MaxPageLimit + 1},
+		{Sort: "price"},
+		{Cursor: "not a cursor"},
+	} {
+		_, err := params.Normalize()
+		require.ErrorIs(t, err, models.ErrValidation)
+	}
+}
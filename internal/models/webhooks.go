@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"net/netip"
+	"time"
+)
+
+// Statuses of a webhook delivery. A pending delivery is attempted until it is delivered or runs out of
+// attempts and fails; redelivery makes a delivery pending again.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDto is a subscription of a partner URL to catalog events; the secret that signs its deliveries is
+// never returned after creation.
+type WebhookDto struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	Active     bool      `json:"active"`
+	Created    time.Time `json:"createdAt"`
+	Updated    time.Time `json:"updatedAt"`
+}
+
+// WebhookSecret is returned once when a webhook is created; the secret can be chosen by the caller.
+type WebhookSecret struct {
+	WebhookDto
+	Secret string `json:"secret"`
+}
+
+// WebhookDeliveryDto is the delivery of one event to one webhook. History, the attempts oldest first, is
+// only filled in for a single delivery.
+type WebhookDeliveryDto struct {
+	ID          string              `json:"id"`
+	WebhookID   string              `json:"webhookId"`
+	EventID     string              `json:"eventId"`
+	EventType   string              `json:"eventType"`
+	Status      string              `json:"status"`
+	Attempts    int                 `json:"attempts"`
+	LastError   string              `json:"lastError,omitempty"`
+	NextAttempt *time.Time          `json:"nextAttemptAt,omitempty"`
+	History     []WebhookAttemptDto `json:"history,omitempty"`
+	Created     time.Time           `json:"createdAt"`
+	Updated     time.Time           `json:"updatedAt"`
+}
+
+// WebhookAttemptDto records one POST of a delivery. StatusCode is 0 when no response arrived; Error is empty
+// for a successful attempt.
+type WebhookAttemptDto struct {
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Duration   int64     `json:"durationMs"`
+	Created    time.Time `json:"createdAt"`
+}
+
+// WebhookDelivery is a claimed delivery with what it takes to send it: Payload is the JSON of the event.
+type WebhookDelivery struct {
+	WebhookDeliveryDto
+	URL     string
+	Secret  string
+	Payload json.RawMessage
+}
+
+// DeliveryStatus is the status of a delivery after attempt, given the time of its retry if it gets one.
+func (a WebhookAttemptDto) DeliveryStatus(retryAt *time.Time) string {
+	switch {
+	case a.Error == "":
+		return DeliveryDelivered
+	case retryAt != nil:
+		return DeliveryPending
+	default:
+		return DeliveryFailed
+	}
+}
+
+// PublicAddr reports whether webhooks may be delivered to addr: a unicast address that is neither loopback,
+// private nor link-local.
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsGlobalUnicast() && !addr.IsPrivate()
+}
@@ -0,0 +1,32 @@
+// Package apidoc serves the hand-maintained OpenAPI 3 description of the API and a Swagger UI for it.
+package apidoc
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/swgui/v5emb"
+)
+
+const (
+	SpecPath = "/openapi.json"
+	UIPath   = "/docs"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+// Spec returns the raw OpenAPI document.
+func Spec() []byte {
+	return spec
+}
+
+func GetSpec(echo echo.Context) error {
+	return echo.Blob(http.StatusOK, "application/json", spec)
+}
+
+// UI serves Swagger UI and its assets below UIPath, pointed at SpecPath.
+func UI() echo.HandlerFunc {
+	return echo.WrapHandler(v5emb.New("TradeService API", SpecPath, UIPath+"/"))
+}
@@ -0,0 +1,121 @@
+package apidoc_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"tradeservice/internal/config"
+	"tradeservice/internal/metrics"
+	"tradeservice/internal/server/apidoc"
+	"tradeservice/internal/server/handler/apikeys"
+	"tradeservice/internal/server/handler/categories"
+	"tradeservice/internal/server/handler/health"
+	"tradeservice/internal/server/handler/inventory"
+	metricshandler "tradeservice/internal/server/handler/metrics"
+	"tradeservice/internal/server/handler/orders"
+	"tradeservice/internal/server/handler/products"
+	"tradeservice/internal/server/handler/webhooks"
+	"tradeservice/internal/server/policy"
+	"tradeservice/internal/server/server"
+	"tradeservice/internal/server/utils"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pathParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+type document struct {
+	OpenAPI string                                `json:"openapi"`
+	Paths   map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+func passThrough(next echo.HandlerFunc) echo.HandlerFunc {
+	return next
+}
+
+func newServer() *server.Server {
+	logger := utils.NewTestLogger()
+	authorizer := policy.AllowAll()
+
+	return server.New(logger, &config.ServerConfig{}, metrics.New(), passThrough, passThrough, passThrough, passThrough,
+		nil,
+		categories.NewCategoriesHandler(nil, authorizer),
+		products.NewProductHandler(nil, authorizer),
+		inventory.NewInventoryHandler(nil, authorizer),
+		orders.NewOrderHandler(nil, authorizer),
+		apikeys.NewAPIKeyHandler(nil, authorizer),
+		webhooks.NewWebhookHandler(nil, authorizer),
+		health.NewHealthHandler(nil),
+		metricshandler.NewMetricsHandler(nil, authorizer))
+}
+
+// TestSpec_CoversRoutes fails when a route is added to the server without being described in openapi.json.
+func TestSpec_CoversRoutes(t *testing.T) {
+	t.Parallel()
+
+	var doc document
+	require.NoError(t, json.Unmarshal(apidoc.Spec(), &doc))
+	require.True(t, strings.HasPrefix(doc.OpenAPI, "3."))
+
+	for _, route := range newServer().Routes() {
+		if route.Method == echo.RouteNotFound || strings.HasPrefix(route.Path, apidoc.UIPath) {
+			continue
+		}
+
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+
+		operations, ok := doc.Paths[path]
+		if !assert.True(t, ok, "path %s is missing from openapi.json", path) {
+			continue
+		}
+
+		assert.Contains(t, operations, strings.ToLower(route.Method), "%s %s is missing from openapi.json", route.Method, path)
+	}
+}
+
+func TestSpec_DescribesOnlyRoutes(t *testing.T) {
+	t.Parallel()
+
+	var doc document
+	require.NoError(t, json.Unmarshal(apidoc.Spec(), &doc))
+
+	routes := make(map[string]bool)
+	for _, route := range newServer().Routes() {
+		routes[strings.ToLower(route.Method)+" "+pathParam.ReplaceAllString(route.Path, "{$1}")] = true
+	}
+
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+
+			assert.True(t, routes[method+" "+path], "openapi.json describes %s %s, which is not routed", method, path)
+		}
+	}
+}
+
+func TestUI(t *testing.T) {
+	t.Parallel()
+
+	server := echo.New()
+	server.GET(apidoc.SpecPath, apidoc.GetSpec)
+	server.GET(apidoc.UIPath, apidoc.UI())
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, apidoc.UIPath, nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), apidoc.SpecPath)
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, apidoc.SpecPath, nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, string(apidoc.Spec()), rec.Body.String())
+}
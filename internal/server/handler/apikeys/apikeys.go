@@ -0,0 +1,103 @@
+package apikeys
+
+import (
+	"context"
+	"net/http"
+	"tradeservice/internal/logger"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/policy"
+	"tradeservice/internal/server/request"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:generate mockgen -source=apikeys.go -destination=mockAPIKeys/apikeysrepository.go
+
+type APIKeyManager interface {
+	AddAPIKey(ctx context.Context, key models.APIKeyDto) (models.APIKeySecret, error)
+	GetAPIKeys(ctx context.Context) ([]models.APIKeyDto, error)
+	RotateAPIKey(ctx context.Context, id string) (models.APIKeySecret, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+}
+
+type APIKeyController struct {
+	manager APIKeyManager
+	policy  policy.Authorizer
+}
+
+func NewAPIKeyHandler(manager APIKeyManager, authorizer policy.Authorizer) *APIKeyController {
+	return &APIKeyController{manager, authorizer}
+}
+
+func (ctr APIKeyController) GetAPIKeys(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for API Keys")
+
+	if err := ctr.policy.Authorize(echo, policy.APIKeysManage); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.GetAPIKeys(echo.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+// CreateAPIKey responds with the plaintext key; it can't be retrieved again afterwards.
+func (ctr APIKeyController) CreateAPIKey(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Post Request for API Keys")
+
+	if err := ctr.policy.Authorize(echo, policy.APIKeysManage); err != nil {
+		return err
+	}
+
+	var key models.APIKeyDto
+	if err := request.Bind(echo, &key); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.AddAPIKey(echo.Request().Context(), models.APIKeyDto{
+		Name:    key.Name,
+		Scopes:  key.Scopes,
+		Expires: key.Expires,
+	})
+	if err != nil {
+		return err
+	}
+
+	echo.Response().Header().Set("Cache-Control", "no-store")
+
+	return echo.JSON(http.StatusCreated, res)
+}
+
+func (ctr APIKeyController) RotateAPIKey(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Rotate Request for API Keys")
+
+	if err := ctr.policy.Authorize(echo, policy.APIKeysManage); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.RotateAPIKey(echo.Request().Context(), echo.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	echo.Response().Header().Set("Cache-Control", "no-store")
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr APIKeyController) RevokeAPIKey(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Delete Request for API Keys")
+
+	if err := ctr.policy.Authorize(echo, policy.APIKeysManage); err != nil {
+		return err
+	}
+
+	if err := ctr.manager.RevokeAPIKey(echo.Request().Context(), echo.Param("id")); err != nil {
+		return err
+	}
+
+	return echo.NoContent(http.StatusNoContent)
+}
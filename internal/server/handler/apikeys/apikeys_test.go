@@ -0,0 +1,73 @@
+package apikeys_test
+
+import (
+	"net/http"
+	"testing"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/handler/apikeys"
+	mockapikeys "tradeservice/internal/server/handler/apikeys/mockAPIKeys"
+	"tradeservice/internal/server/policy"
+	"tradeservice/internal/server/utils"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAPIKeyController_CreateAPIKey_ReturnsPlaintextOnce(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockapikeys.NewMockAPIKeyManager(ctrl)
+	handler := apikeys.NewAPIKeyHandler(mockManager, policy.AllowAll())
+
+	mockManager.EXPECT().AddAPIKey(gomock.Any(), models.APIKeyDto{Name: "import", Scopes: []string{"products.write"}}).
+		Return(models.APIKeySecret{
+			APIKeyDto: models.APIKeyDto{ID: "1", Name: "import", Prefix: "tsk_abcdefgh"},
+			Key:       "tsk_abcdefghijkl",
+		}, nil)
+
+	rec, req, keys, vals := utils.CreateJSONContext(http.MethodPost, "/admin/api-keys",
+		`{"name":"import","scopes":["products.write"],"prefix":"ignored"}`, nil)
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.CreateAPIKey(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.Contains(t, rec.Body.String(), `"key":"tsk_abcdefghijkl"`)
+}
+
+func TestAPIKeyController_RevokeAPIKey_NotFound(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockapikeys.NewMockAPIKeyManager(ctrl)
+	handler := apikeys.NewAPIKeyHandler(mockManager, policy.AllowAll())
+
+	mockManager.EXPECT().RevokeAPIKey(gomock.Any(), "9").Return(models.ErrNotFound)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodDelete, "/admin/api-keys/:id", map[string]string{
+		"id": "9",
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.RevokeAPIKey(echoCtx)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
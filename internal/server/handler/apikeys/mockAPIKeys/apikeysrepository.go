@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: apikeys.go
+//
+// Generated by this command:
+//
+//	mockgen -source=apikeys.go -destination=mockAPIKeys/apikeysrepository.go
+//
+
+// Package mock_apikeys is a generated GoMock package.
+package mock_apikeys
+
+import (
+	context "context"
+	reflect "reflect"
+	models "tradeservice/internal/models"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAPIKeyManager is a mock of APIKeyManager interface.
+type MockAPIKeyManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyManagerMockRecorder
+	isgomock struct{}
+}
+
+// MockAPIKeyManagerMockRecorder is the mock recorder for MockAPIKeyManager.
+type MockAPIKeyManagerMockRecorder struct {
+	mock *MockAPIKeyManager
+}
+
+// NewMockAPIKeyManager creates a new mock instance.
+func NewMockAPIKeyManager(ctrl *gomock.Controller) *MockAPIKeyManager {
+	mock := &MockAPIKeyManager{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyManager) EXPECT() *MockAPIKeyManagerMockRecorder {
+	return m.recorder
+}
+
+// AddAPIKey mocks base method.
+func (m *MockAPIKeyManager) AddAPIKey(ctx context.Context, key models.APIKeyDto) (models.APIKeySecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAPIKey", ctx, key)
+	ret0, _ := ret[0].(models.APIKeySecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAPIKey indicates an expected call of AddAPIKey.
+func (mr *MockAPIKeyManagerMockRecorder) AddAPIKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAPIKey", reflect.TypeOf((*MockAPIKeyManager)(nil).AddAPIKey), ctx, key)
+}
+
+// GetAPIKeys mocks base method.
+func (m *MockAPIKeyManager) GetAPIKeys(ctx context.Context) ([]models.APIKeyDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeys", ctx)
+	ret0, _ := ret[0].([]models.APIKeyDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys.
+func (mr *MockAPIKeyManagerMockRecorder) GetAPIKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockAPIKeyManager)(nil).GetAPIKeys), ctx)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAPIKeyManager) RevokeAPIKey(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAPIKeyManagerMockRecorder) RevokeAPIKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAPIKeyManager)(nil).RevokeAPIKey), ctx, id)
+}
+
+// RotateAPIKey mocks base method.
+func (m *MockAPIKeyManager) RotateAPIKey(ctx context.Context, id string) (models.APIKeySecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateAPIKey", ctx, id)
+	ret0, _ := ret[0].(models.APIKeySecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateAPIKey indicates an expected call of RotateAPIKey.
+func (mr *MockAPIKeyManagerMockRecorder) RotateAPIKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateAPIKey", reflect.TypeOf((*MockAPIKeyManager)(nil).RotateAPIKey), ctx, id)
+}
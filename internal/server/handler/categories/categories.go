@@ -2,94 +2,278 @@ package categories
 
 import (
 	"context"
-	"errors"
-	"log/slog"
 	"net/http"
+	"tradeservice/internal/logger"
 	"tradeservice/internal/models"
+	"tradeservice/internal/server/policy"
+	"tradeservice/internal/server/request"
 
 	"github.com/labstack/echo/v4"
 )
 
-//go:generate mockgen -source=categories.go -destination=mock/categoriesrepository.go
+//go:generate mockgen -source=categories.go -destination=mockCategories/categoriesrepository.go
 
 type CategoryManager interface {
-	AddCategory(ctx context.Context, name string, productID string) (ID string, err error)
-	GetCategory(ctx context.Context) ([]models.CategoryDto, error)
-	SetCategory(ctx context.Context, ID string, name string) error
+	AddCategory(ctx context.Context, category models.CategoryDto) (models.CategoryDto, error)
+	AddCategoryForProduct(ctx context.Context, category models.CategoryDto, productID string) (models.CategoryDto, error)
+	GetCategory(ctx context.Context, params models.ListParams) (models.Page[models.CategoryDto], error)
+	GetCategoryByID(ctx context.Context, ID string) (models.CategoryDto, error)
+	SetCategory(ctx context.Context, ID string, name string) (models.CategoryDto, error)
 	DeleteCategory(ctx context.Context, ID string) error
+	GetCategorySubtree(ctx context.Context, ID string) (models.CategoryTree, error)
+	GetCategoryAncestors(ctx context.Context, ID string) ([]models.CategoryDto, error)
+	MoveCategory(ctx context.Context, ID string, parentID *string) (models.CategoryDto, error)
+	AssignProduct(ctx context.Context, ID string, productID string) error
+	UnassignProduct(ctx context.Context, ID string, productID string) error
+	GetProductCategories(ctx context.Context, productID string) ([]models.CategoryDto, error)
 }
 
 type CategoriesController struct {
 	manager CategoryManager
-	logger  *slog.Logger
+	policy  policy.Authorizer
 }
 
-func NewCategoriesHandler(manager CategoryManager, log *slog.Logger) *CategoriesController {
-	return &CategoriesController{manager, log}
+func NewCategoriesHandler(manager CategoryManager, authorizer policy.Authorizer) *CategoriesController {
+	return &CategoriesController{manager, authorizer}
 }
 
 func (ctr CategoriesController) GetCategory(echo echo.Context) error {
-	ctr.logger.Debug("Get Request for Categories")
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Categories")
 
-	res, err := ctr.manager.GetCategory(echo.Request().Context())
+	if err := ctr.policy.Authorize(echo, policy.CategoriesRead); err != nil {
+		return err
+	}
+
+	params, err := request.ListParams(echo)
 	if err != nil {
-		return echo.NoContent(http.StatusInternalServerError)
+		return err
+	}
+
+	res, err := ctr.manager.GetCategory(echo.Request().Context(), params)
+	if err != nil {
+		return err
 	}
 
 	return echo.JSON(http.StatusOK, res)
 }
 
-func (ctr CategoriesController) AddCategory(echo echo.Context) error {
-	ctr.logger.Debug("Post Request for Categories")
+func (ctr CategoriesController) GetCategoryByID(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Category")
 
-	categoryName := echo.Param("categoryName")
+	if err := ctr.policy.Authorize(echo, policy.CategoriesRead); err != nil {
+		return err
+	}
 
-	productID := echo.Param("productId")
+	categoryID := echo.Param("id")
 
-	res, err := ctr.manager.AddCategory(echo.Request().Context(), categoryName, productID)
+	res, err := ctr.manager.GetCategoryByID(echo.Request().Context(), categoryID)
 	if err != nil {
-		if errors.Is(err, models.ErrUnique) {
-			return echo.NoContent(http.StatusConflict)
-		}
-
-		return echo.NoContent(http.StatusInternalServerError)
+		return err
 	}
 
 	return echo.JSON(http.StatusOK, res)
 }
 
+func (ctr CategoriesController) CreateCategory(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Post Request for Categories")
+
+	if err := ctr.policy.Authorize(echo, policy.CategoriesWrite); err != nil {
+		return err
+	}
+
+	var category models.CategoryDto
+	if err := request.Bind(echo, &category); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.AddCategory(echo.Request().Context(), category)
+	if err != nil {
+		return err
+	}
+
+	echo.Response().Header().Set("Location", "/categories/"+res.ID)
+
+	return echo.JSON(http.StatusCreated, res)
+}
+
+// AddCategory serves the deprecated POST /categories/create/:categoryName/:productId route,
+// which assigns the product to the top-level category of that name and creates it if needed.
+func (ctr CategoriesController) AddCategory(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Post Request for Categories")
+
+	if err := ctr.policy.Authorize(echo, policy.CategoriesWrite); err != nil {
+		return err
+	}
+
+	category := models.CategoryDto{
+		Name: echo.Param("categoryName"),
+	}
+
+	res, err := ctr.manager.AddCategoryForProduct(echo.Request().Context(), category, echo.Param("productId"))
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res.ID)
+}
+
 func (ctr CategoriesController) DeleteCategory(echo echo.Context) error {
-	ctr.logger.Debug("Delete Request for Categories")
+	logger.FromContext(echo.Request().Context()).Debug("Delete Request for Categories")
 
-	categoryID := echo.Param("categoryId")
+	if err := ctr.policy.Authorize(echo, policy.CategoriesDelete); err != nil {
+		return err
+	}
+
+	categoryID := echo.Param("id")
 
 	err := ctr.manager.DeleteCategory(echo.Request().Context(), categoryID)
 	if err != nil {
-		if errors.Is(err, models.ErrNotFound) {
-			return echo.NoContent(http.StatusNotFound)
-		}
-
-		return echo.NoContent(http.StatusInternalServerError)
+		return err
 	}
 
 	return echo.NoContent(http.StatusOK)
 }
 
+// UpdateCategory serves both PUT and PATCH: the name is the only mutable field of a category.
+func (ctr CategoriesController) UpdateCategory(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Update Request for Categories")
+
+	if err := ctr.policy.Authorize(echo, policy.CategoriesWrite); err != nil {
+		return err
+	}
+
+	categoryID := echo.Param("id")
+
+	var category models.CategoryDto
+	if err := request.Bind(echo, &category); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.SetCategory(echo.Request().Context(), categoryID, category.Name)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+// SetCategory serves the deprecated POST /categories/update/:categoryId/:categoryName route.
 func (ctr CategoriesController) SetCategory(echo echo.Context) error {
-	ctr.logger.Debug("Patch Request for Categories")
+	logger.FromContext(echo.Request().Context()).Debug("Patch Request for Categories")
+
+	if err := ctr.policy.Authorize(echo, policy.CategoriesWrite); err != nil {
+		return err
+	}
 
 	categoryID := echo.Param("categoryId")
 
 	categoryName := echo.Param("categoryName")
 
-	err := ctr.manager.SetCategory(echo.Request().Context(), categoryID, categoryName)
+	_, err := ctr.manager.SetCategory(echo.Request().Context(), categoryID, categoryName)
 	if err != nil {
-		if errors.Is(err, models.ErrNotFound) {
-			return echo.NoContent(http.StatusNotFound)
-		}
-
-		return echo.NoContent(http.StatusInternalServerError)
+		return err
 	}
 
 	return echo.NoContent(http.StatusOK)
 }
+
+func (ctr CategoriesController) GetCategorySubtree(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Category subtree")
+
+	if err := ctr.policy.Authorize(echo, policy.CategoriesRead); err != nil {
+		return err
+	}
+
+	categoryID := echo.Param("id")
+
+	res, err := ctr.manager.GetCategorySubtree(echo.Request().Context(), categoryID)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr CategoriesController) GetCategoryAncestors(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Category ancestors")
+
+	if err := ctr.policy.Authorize(echo, policy.CategoriesRead); err != nil {
+		return err
+	}
+
+	categoryID := echo.Param("id")
+
+	res, err := ctr.manager.GetCategoryAncestors(echo.Request().Context(), categoryID)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr CategoriesController) MoveCategory(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Move Request for Categories")
+
+	if err := ctr.policy.Authorize(echo, policy.CategoriesWrite); err != nil {
+		return err
+	}
+
+	categoryID := echo.Param("id")
+
+	var move models.CategoryMove
+	if err := request.Bind(echo, &move); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.MoveCategory(echo.Request().Context(), categoryID, move.ParentID)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr CategoriesController) AssignProduct(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Assign Request for Categories")
+
+	if err := ctr.policy.Authorize(echo, policy.CategoriesWrite); err != nil {
+		return err
+	}
+
+	err := ctr.manager.AssignProduct(echo.Request().Context(), echo.Param("id"), echo.Param("productId"))
+	if err != nil {
+		return err
+	}
+
+	return echo.NoContent(http.StatusNoContent)
+}
+
+func (ctr CategoriesController) UnassignProduct(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Unassign Request for Categories")
+
+	if err := ctr.policy.Authorize(echo, policy.CategoriesWrite); err != nil {
+		return err
+	}
+
+	err := ctr.manager.UnassignProduct(echo.Request().Context(), echo.Param("id"), echo.Param("productId"))
+	if err != nil {
+		return err
+	}
+
+	return echo.NoContent(http.StatusNoContent)
+}
+
+func (ctr CategoriesController) GetProductCategories(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Product categories")
+
+	if err := ctr.policy.Authorize(echo, policy.CategoriesRead); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.GetProductCategories(echo.Request().Context(), echo.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
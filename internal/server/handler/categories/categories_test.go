@@ -2,10 +2,10 @@ package categories_test
 
 import (
 	"net/http"
-	"strings"
 	"testing"
 	"tradeservice/internal/models"
 	"tradeservice/internal/server/handler/categories"
+	"tradeservice/internal/server/policy"
 	"tradeservice/internal/server/utils"
 
 	"github.com/stretchr/testify/require"
@@ -25,15 +25,14 @@ func TestCategoriesController_GetCategory(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockcategories.NewMockCategoryManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := categories.NewCategoriesHandler(mockManager, logger)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
 
-	categoriesList := []models.CategoryDto{
+	categoriesList := models.Page[models.CategoryDto]{Items: []models.CategoryDto{
 		{ID: "1", Name: "cat1"},
 		{ID: "2", Name: "cat2"},
-	}
+	}}
 
-	mockManager.EXPECT().GetCategory(gomock.Any()).Return(categoriesList, nil)
+	mockManager.EXPECT().GetCategory(gomock.Any(), models.ListParams{}).Return(categoriesList, nil)
 
 	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/categories", nil)
 
@@ -55,10 +54,9 @@ func TestCategoriesController_GetCategory_Error(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockcategories.NewMockCategoryManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := categories.NewCategoriesHandler(mockManager, logger)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
 
-	mockManager.EXPECT().GetCategory(gomock.Any()).Return(nil, models.ErrDB)
+	mockManager.EXPECT().GetCategory(gomock.Any(), models.ListParams{}).Return(models.Page[models.CategoryDto]{}, models.ErrDB)
 
 	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/categories", nil)
 
@@ -68,8 +66,7 @@ func TestCategoriesController_GetCategory_Error(t *testing.T) {
 	echoCtx.SetParamValues(vals...)
 
 	err := handler.GetCategory(echoCtx)
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.ErrorIs(t, err, models.ErrDB)
 }
 
 func TestCategoriesController_AddCategory_Success(t *testing.T) {
@@ -80,18 +77,18 @@ func TestCategoriesController_AddCategory_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockcategories.NewMockCategoryManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := categories.NewCategoriesHandler(mockManager, logger)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
 
 	categoryName := "newcat"
 	productID := "prod123"
 	newID := "42"
 
-	mockManager.EXPECT().AddCategory(gomock.Any(), categoryName, productID).Return(newID, nil)
+	mockManager.EXPECT().AddCategoryForProduct(gomock.Any(), models.CategoryDto{Name: categoryName}, productID).
+		Return(models.CategoryDto{ID: newID, Name: categoryName}, nil)
 
 	rec, req, keys, vals := utils.CreateContext(http.MethodPost, "/categories/:categoryName/:productId", map[string]string{
 		"categoryName": categoryName,
-		"productID":    productID,
+		"productId":    productID,
 	})
 
 	e := echo.New()
@@ -102,7 +99,7 @@ func TestCategoriesController_AddCategory_Success(t *testing.T) {
 	err := handler.AddCategory(echoCtx)
 	require.NoError(t, err)
 	assert.Equal(t, http.StatusOK, rec.Code)
-	assert.Equal(t, newID, strings.TrimSpace(rec.Body.String()))
+	assert.JSONEq(t, `"`+newID+`"`, rec.Body.String())
 }
 
 func TestCategoriesController_AddCategory_Conflict(t *testing.T) {
@@ -113,17 +110,17 @@ func TestCategoriesController_AddCategory_Conflict(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockcategories.NewMockCategoryManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := categories.NewCategoriesHandler(mockManager, logger)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
 
 	categoryName := "dupCat"
 	productID := "prod123"
 
-	mockManager.EXPECT().AddCategory(gomock.Any(), categoryName, productID).Return("", models.ErrUnique)
+	mockManager.EXPECT().AddCategoryForProduct(gomock.Any(), models.CategoryDto{Name: categoryName}, productID).
+		Return(models.CategoryDto{}, models.ErrUnique)
 
 	rec, req, keys, vals := utils.CreateContext(http.MethodPost, "/categories/:categoryName/:productId", map[string]string{
 		"categoryName": categoryName,
-		"productID":    productID,
+		"productId":    productID,
 	})
 
 	e := echo.New()
@@ -132,8 +129,7 @@ func TestCategoriesController_AddCategory_Conflict(t *testing.T) {
 	echoCtx.SetParamValues(vals...)
 
 	err := handler.AddCategory(echoCtx)
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusConflict, rec.Code)
+	require.ErrorIs(t, err, models.ErrUnique)
 }
 
 func TestCategoriesController_DeleteCategory_Success(t *testing.T) {
@@ -144,15 +140,14 @@ func TestCategoriesController_DeleteCategory_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockcategories.NewMockCategoryManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := categories.NewCategoriesHandler(mockManager, logger)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
 
 	categoryID := "42"
 
 	mockManager.EXPECT().DeleteCategory(gomock.Any(), categoryID).Return(nil)
 
-	rec, req, keys, vals := utils.CreateContext(http.MethodDelete, "/categories/:categoryId", map[string]string{
-		"categoryId": categoryID,
+	rec, req, keys, vals := utils.CreateContext(http.MethodDelete, "/categories/:id", map[string]string{
+		"id": categoryID,
 	})
 
 	e := echo.New()
@@ -173,15 +168,14 @@ func TestCategoriesController_DeleteCategory_NotFound(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockcategories.NewMockCategoryManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := categories.NewCategoriesHandler(mockManager, logger)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
 
 	categoryID := "42"
 
 	mockManager.EXPECT().DeleteCategory(gomock.Any(), categoryID).Return(models.ErrNotFound)
 
-	rec, req, keys, vals := utils.CreateContext(http.MethodDelete, "/categories/:categoryId", map[string]string{
-		"categoryId": categoryID,
+	rec, req, keys, vals := utils.CreateContext(http.MethodDelete, "/categories/:id", map[string]string{
+		"id": categoryID,
 	})
 
 	e := echo.New()
@@ -190,8 +184,7 @@ func TestCategoriesController_DeleteCategory_NotFound(t *testing.T) {
 	echoCtx.SetParamValues(vals...)
 
 	err := handler.DeleteCategory(echoCtx)
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusNotFound, rec.Code)
+	require.ErrorIs(t, err, models.ErrNotFound)
 }
 
 func TestCategoriesController_SetCategory_Success(t *testing.T) {
@@ -202,13 +195,13 @@ func TestCategoriesController_SetCategory_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockcategories.NewMockCategoryManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := categories.NewCategoriesHandler(mockManager, logger)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
 
 	categoryID := "42"
 	categoryName := "updated"
 
-	mockManager.EXPECT().SetCategory(gomock.Any(), categoryID, categoryName).Return(nil)
+	mockManager.EXPECT().SetCategory(gomock.Any(), categoryID, categoryName).
+		Return(models.CategoryDto{ID: categoryID, Name: categoryName}, nil)
 
 	rec, req, keys, vals := utils.CreateContext(http.MethodPost, "/categories/:categoryId/:categoryName", map[string]string{
 		"categoryId":   categoryID,
@@ -233,13 +226,13 @@ func TestCategoriesController_SetCategory_NotFound(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockcategories.NewMockCategoryManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := categories.NewCategoriesHandler(mockManager, logger)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
 
 	categoryID := "42"
 	categoryName := "updated"
 
-	mockManager.EXPECT().SetCategory(gomock.Any(), categoryID, categoryName).Return(models.ErrNotFound)
+	mockManager.EXPECT().SetCategory(gomock.Any(), categoryID, categoryName).
+		Return(models.CategoryDto{}, models.ErrNotFound)
 
 	rec, req, keys, vals := utils.CreateContext(http.MethodPost, "/categories/:categoryId/:categoryName", map[string]string{
 		"categoryId":   categoryID,
@@ -252,6 +245,297 @@ func TestCategoriesController_SetCategory_NotFound(t *testing.T) {
 	echoCtx.SetParamValues(vals...)
 
 	err := handler.SetCategory(echoCtx)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestCategoriesController_CreateCategory_Created(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockcategories.NewMockCategoryManager(ctrl)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
+
+	categoryName := "Gaming / Laptops"
+	newID := "42"
+
+	mockManager.EXPECT().AddCategory(gomock.Any(), models.CategoryDto{Name: categoryName}).
+		Return(models.CategoryDto{ID: newID, Name: categoryName}, nil)
+
+	rec, req, keys, vals := utils.CreateJSONContext(http.MethodPost, "/categories", `{"name":"`+categoryName+`"}`, nil)
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.CreateCategory(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "/categories/"+newID, rec.Header().Get("Location"))
+}
+
+func TestCategoriesController_UpdateCategory_Invalid(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockcategories.NewMockCategoryManager(ctrl)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
+
+	categoryID := "42"
+
+	mockManager.EXPECT().SetCategory(gomock.Any(), categoryID, "").Return(models.CategoryDto{}, models.ErrValidation)
+
+	rec, req, keys, vals := utils.CreateJSONContext(http.MethodPut, "/categories/:id", `{"name":""}`, map[string]string{
+		"id": categoryID,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.UpdateCategory(echoCtx)
+	require.ErrorIs(t, err, models.ErrValidation)
+}
+
+func TestCategoriesController_GetCategoryByID_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockcategories.NewMockCategoryManager(ctrl)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
+
+	categoryID := "42"
+
+	mockManager.EXPECT().GetCategoryByID(gomock.Any(), categoryID).Return(models.CategoryDto{ID: categoryID, Name: "cat1"}, nil)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/categories/:id", map[string]string{
+		"id": categoryID,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.GetCategoryByID(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCategoriesController_GetCategoryByID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockcategories.NewMockCategoryManager(ctrl)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
+
+	categoryID := "42"
+
+	mockManager.EXPECT().GetCategoryByID(gomock.Any(), categoryID).Return(models.CategoryDto{}, models.ErrNotFound)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/categories/:id", map[string]string{
+		"id": categoryID,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.GetCategoryByID(echoCtx)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestCategoriesController_GetCategorySubtree_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockcategories.NewMockCategoryManager(ctrl)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
+
+	categoryID := "1"
+	tree := models.CategoryTree{
+		CategoryDto: models.CategoryDto{ID: categoryID, Name: "Electronics"},
+		Children: []models.CategoryTree{
+			{CategoryDto: models.CategoryDto{ID: "2", Name: "Laptops", ParentID: &categoryID}, Children: []models.CategoryTree{}},
+		},
+	}
+
+	mockManager.EXPECT().GetCategorySubtree(gomock.Any(), categoryID).Return(tree, nil)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/categories/:id/subtree", map[string]string{
+		"id": categoryID,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.GetCategorySubtree(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"children":[{"id":"2","name":"Laptops","parentId":"1"`)
+}
+
+func TestCategoriesController_MoveCategory_Cycle(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockcategories.NewMockCategoryManager(ctrl)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
+
+	categoryID := "1"
+	parentID := "3"
+
+	mockManager.EXPECT().MoveCategory(gomock.Any(), categoryID, &parentID).Return(models.CategoryDto{}, models.ErrConflict)
+
+	rec, req, keys, vals := utils.CreateJSONContext(http.MethodPost, "/categories/:id/move", `{"parentId":"3"}`,
+		map[string]string{
+			"id": categoryID,
+		})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.MoveCategory(echoCtx)
+	require.ErrorIs(t, err, models.ErrConflict)
+}
+
+func TestCategoriesController_MoveCategory_ToRoot(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockcategories.NewMockCategoryManager(ctrl)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
+
+	categoryID := "2"
+
+	mockManager.EXPECT().MoveCategory(gomock.Any(), categoryID, nil).Return(models.CategoryDto{ID: categoryID}, nil)
+
+	rec, req, keys, vals := utils.CreateJSONContext(http.MethodPost, "/categories/:id/move", `{"parentId":null}`,
+		map[string]string{
+			"id": categoryID,
+		})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.MoveCategory(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCategoriesController_AssignProduct_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockcategories.NewMockCategoryManager(ctrl)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
+
+	categoryID := "1"
+	productID := "2"
+
+	mockManager.EXPECT().AssignProduct(gomock.Any(), categoryID, productID).Return(nil)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodPut, "/categories/:id/products/:productId", map[string]string{
+		"id":        categoryID,
+		"productId": productID,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.AssignProduct(echoCtx)
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestCategoriesController_AssignProduct_NotFound(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockcategories.NewMockCategoryManager(ctrl)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
+
+	categoryID := "1"
+	productID := "404"
+
+	mockManager.EXPECT().AssignProduct(gomock.Any(), categoryID, productID).Return(models.ErrNotFound)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodPut, "/categories/:id/products/:productId", map[string]string{
+		"id":        categoryID,
+		"productId": productID,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.AssignProduct(echoCtx)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestCategoriesController_GetProductCategories_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockcategories.NewMockCategoryManager(ctrl)
+	handler := categories.NewCategoriesHandler(mockManager, policy.AllowAll())
+
+	productID := "2"
+
+	mockManager.EXPECT().GetProductCategories(gomock.Any(), productID).
+		Return([]models.CategoryDto{{ID: "1", Name: "Laptop"}}, nil)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/products/:id/categories", map[string]string{
+		"id": productID,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.GetProductCategories(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
 }
@@ -3,10 +3,10 @@
 //
 // Generated by this command:
 //
-//	mockgen -source=categories.go -destination=mock/categoriesrepository.go
+//	mockgen -source=categories.go -destination=mockCategories/categoriesrepository.go
 //
 
-// Package mock_handlers is a generated GoMock package.
+// Package mock_categories is a generated GoMock package.
 package mock_categories
 
 import (
@@ -42,59 +42,178 @@ func (m *MockCategoryManager) EXPECT() *MockCategoryManagerMockRecorder {
 }
 
 // AddCategory mocks base method.
-func (m *MockCategoryManager) AddCategory(ctx context.Context, name, productId string) (string, error) {
+func (m *MockCategoryManager) AddCategory(ctx context.Context, category models.CategoryDto) (models.CategoryDto, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddCategory", ctx, name, productId)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "AddCategory", ctx, category)
+	ret0, _ := ret[0].(models.CategoryDto)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddCategory indicates an expected call of AddCategory.
-func (mr *MockCategoryManagerMockRecorder) AddCategory(ctx, name, productId any) *gomock.Call {
+func (mr *MockCategoryManagerMockRecorder) AddCategory(ctx, category any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCategory", reflect.TypeOf((*MockCategoryManager)(nil).AddCategory), ctx, name, productId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCategory", reflect.TypeOf((*MockCategoryManager)(nil).AddCategory), ctx, category)
+}
+
+// AddCategoryForProduct mocks base method.
+func (m *MockCategoryManager) AddCategoryForProduct(ctx context.Context, category models.CategoryDto, productID string) (models.CategoryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCategoryForProduct", ctx, category, productID)
+	ret0, _ := ret[0].(models.CategoryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCategoryForProduct indicates an expected call of AddCategoryForProduct.
+func (mr *MockCategoryManagerMockRecorder) AddCategoryForProduct(ctx, category, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCategoryForProduct", reflect.TypeOf((*MockCategoryManager)(nil).AddCategoryForProduct), ctx, category, productID)
+}
+
+// AssignProduct mocks base method.
+func (m *MockCategoryManager) AssignProduct(ctx context.Context, ID, productID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignProduct", ctx, ID, productID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignProduct indicates an expected call of AssignProduct.
+func (mr *MockCategoryManagerMockRecorder) AssignProduct(ctx, ID, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignProduct", reflect.TypeOf((*MockCategoryManager)(nil).AssignProduct), ctx, ID, productID)
 }
 
 // DeleteCategory mocks base method.
-func (m *MockCategoryManager) DeleteCategory(ctx context.Context, id string) error {
+func (m *MockCategoryManager) DeleteCategory(ctx context.Context, ID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCategory", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteCategory", ctx, ID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteCategory indicates an expected call of DeleteCategory.
-func (mr *MockCategoryManagerMockRecorder) DeleteCategory(ctx, id any) *gomock.Call {
+func (mr *MockCategoryManagerMockRecorder) DeleteCategory(ctx, ID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockCategoryManager)(nil).DeleteCategory), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockCategoryManager)(nil).DeleteCategory), ctx, ID)
 }
 
 // GetCategory mocks base method.
-func (m *MockCategoryManager) GetCategory(ctx context.Context) ([]models.CategoryDto, error) {
+func (m *MockCategoryManager) GetCategory(ctx context.Context, params models.ListParams) (models.Page[models.CategoryDto], error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCategory", ctx)
-	ret0, _ := ret[0].([]models.CategoryDto)
+	ret := m.ctrl.Call(m, "GetCategory", ctx, params)
+	ret0, _ := ret[0].(models.Page[models.CategoryDto])
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCategory indicates an expected call of GetCategory.
-func (mr *MockCategoryManagerMockRecorder) GetCategory(ctx any) *gomock.Call {
+func (mr *MockCategoryManagerMockRecorder) GetCategory(ctx, params any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockCategoryManager)(nil).GetCategory), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockCategoryManager)(nil).GetCategory), ctx, params)
+}
+
+// GetCategoryAncestors mocks base method.
+func (m *MockCategoryManager) GetCategoryAncestors(ctx context.Context, ID string) ([]models.CategoryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryAncestors", ctx, ID)
+	ret0, _ := ret[0].([]models.CategoryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryAncestors indicates an expected call of GetCategoryAncestors.
+func (mr *MockCategoryManagerMockRecorder) GetCategoryAncestors(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryAncestors", reflect.TypeOf((*MockCategoryManager)(nil).GetCategoryAncestors), ctx, ID)
+}
+
+// GetCategoryByID mocks base method.
+func (m *MockCategoryManager) GetCategoryByID(ctx context.Context, ID string) (models.CategoryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryByID", ctx, ID)
+	ret0, _ := ret[0].(models.CategoryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryByID indicates an expected call of GetCategoryByID.
+func (mr *MockCategoryManagerMockRecorder) GetCategoryByID(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryByID", reflect.TypeOf((*MockCategoryManager)(nil).GetCategoryByID), ctx, ID)
+}
+
+// GetCategorySubtree mocks base method.
+func (m *MockCategoryManager) GetCategorySubtree(ctx context.Context, ID string) (models.CategoryTree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategorySubtree", ctx, ID)
+	ret0, _ := ret[0].(models.CategoryTree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategorySubtree indicates an expected call of GetCategorySubtree.
+func (mr *MockCategoryManagerMockRecorder) GetCategorySubtree(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategorySubtree", reflect.TypeOf((*MockCategoryManager)(nil).GetCategorySubtree), ctx, ID)
+}
+
+// GetProductCategories mocks base method.
+func (m *MockCategoryManager) GetProductCategories(ctx context.Context, productID string) ([]models.CategoryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductCategories", ctx, productID)
+	ret0, _ := ret[0].([]models.CategoryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductCategories indicates an expected call of GetProductCategories.
+func (mr *MockCategoryManagerMockRecorder) GetProductCategories(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductCategories", reflect.TypeOf((*MockCategoryManager)(nil).GetProductCategories), ctx, productID)
+}
+
+// MoveCategory mocks base method.
+func (m *MockCategoryManager) MoveCategory(ctx context.Context, ID string, parentID *string) (models.CategoryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveCategory", ctx, ID, parentID)
+	ret0, _ := ret[0].(models.CategoryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveCategory indicates an expected call of MoveCategory.
+func (mr *MockCategoryManagerMockRecorder) MoveCategory(ctx, ID, parentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveCategory", reflect.TypeOf((*MockCategoryManager)(nil).MoveCategory), ctx, ID, parentID)
 }
 
 // SetCategory mocks base method.
-func (m *MockCategoryManager) SetCategory(ctx context.Context, id, name string) error {
+func (m *MockCategoryManager) SetCategory(ctx context.Context, ID, name string) (models.CategoryDto, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetCategory", ctx, id, name)
+	ret := m.ctrl.Call(m, "SetCategory", ctx, ID, name)
+	ret0, _ := ret[0].(models.CategoryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCategory indicates an expected call of SetCategory.
+func (mr *MockCategoryManagerMockRecorder) SetCategory(ctx, ID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCategory", reflect.TypeOf((*MockCategoryManager)(nil).SetCategory), ctx, ID, name)
+}
+
+// UnassignProduct mocks base method.
+func (m *MockCategoryManager) UnassignProduct(ctx context.Context, ID, productID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnassignProduct", ctx, ID, productID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetCategory indicates an expected call of SetCategory.
-func (mr *MockCategoryManagerMockRecorder) SetCategory(ctx, id, name any) *gomock.Call {
+// UnassignProduct indicates an expected call of UnassignProduct.
+func (mr *MockCategoryManagerMockRecorder) UnassignProduct(ctx, ID, productID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCategory", reflect.TypeOf((*MockCategoryManager)(nil).SetCategory), ctx, id, name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnassignProduct", reflect.TypeOf((*MockCategoryManager)(nil).UnassignProduct), ctx, ID, productID)
 }
@@ -0,0 +1,43 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"tradeservice/internal/logger"
+	"tradeservice/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:generate mockgen -source=health.go -destination=mockHealth/healthrepository.go
+
+type HealthManager interface {
+	Live(ctx context.Context) models.HealthReport
+	Ready(ctx context.Context) models.HealthReport
+}
+
+// HealthController serves the orchestrator probes; they are public and bypass the policy.
+type HealthController struct {
+	manager HealthManager
+}
+
+func NewHealthHandler(manager HealthManager) *HealthController {
+	return &HealthController{manager}
+}
+
+func (ctr HealthController) Healthz(echo echo.Context) error {
+	return echo.JSON(http.StatusOK, ctr.manager.Live(echo.Request().Context()))
+}
+
+// Readyz answers 503 when any check fails, including once shutdown has begun.
+func (ctr HealthController) Readyz(echo echo.Context) error {
+	report := ctr.manager.Ready(echo.Request().Context())
+	if report.Status != models.HealthOK {
+		logger.FromContext(echo.Request().Context()).Warn("Readiness check failed", slog.Any("checks", report.Checks))
+
+		return echo.JSON(http.StatusServiceUnavailable, report)
+	}
+
+	return echo.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,45 @@
+package health_test
+
+import (
+	"net/http"
+	"testing"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/handler/health"
+	mockhealth "tradeservice/internal/server/handler/health/mockHealth"
+	"tradeservice/internal/server/utils"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHealthController_Readyz_Unavailable(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockhealth.NewMockHealthManager(ctrl)
+	handler := health.NewHealthHandler(mockManager)
+
+	mockManager.EXPECT().Ready(gomock.Any()).Return(models.HealthReport{
+		Status: models.HealthFail,
+		Checks: map[string]models.CheckResult{
+			"database": {Status: models.HealthFail, LatencyMS: 2, Error: "failed to ping DB"},
+		},
+	})
+
+	rec, req, _, _ := utils.CreateJSONContext(http.MethodGet, "/readyz", "", nil)
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+
+	err := handler.Readyz(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"status":"fail","checks":{"database":{"status":"fail","latencyMs":2,"error":"failed to ping DB"}}}`,
+		rec.Body.String())
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: health.go
+//
+// Generated by this command:
+//
+//	mockgen -source=health.go -destination=mockHealth/healthrepository.go
+//
+
+// Package mock_health is a generated GoMock package.
+package mock_health
+
+import (
+	context "context"
+	reflect "reflect"
+	models "tradeservice/internal/models"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockHealthManager is a mock of HealthManager interface.
+type MockHealthManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthManagerMockRecorder
+	isgomock struct{}
+}
+
+// MockHealthManagerMockRecorder is the mock recorder for MockHealthManager.
+type MockHealthManagerMockRecorder struct {
+	mock *MockHealthManager
+}
+
+// NewMockHealthManager creates a new mock instance.
+func NewMockHealthManager(ctrl *gomock.Controller) *MockHealthManager {
+	mock := &MockHealthManager{ctrl: ctrl}
+	mock.recorder = &MockHealthManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthManager) EXPECT() *MockHealthManagerMockRecorder {
+	return m.recorder
+}
+
+// Live mocks base method.
+func (m *MockHealthManager) Live(ctx context.Context) models.HealthReport {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Live", ctx)
+	ret0, _ := ret[0].(models.HealthReport)
+	return ret0
+}
+
+// Live indicates an expected call of Live.
+func (mr *MockHealthManagerMockRecorder) Live(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Live", reflect.TypeOf((*MockHealthManager)(nil).Live), ctx)
+}
+
+// Ready mocks base method.
+func (m *MockHealthManager) Ready(ctx context.Context) models.HealthReport {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready", ctx)
+	ret0, _ := ret[0].(models.HealthReport)
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockHealthManagerMockRecorder) Ready(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockHealthManager)(nil).Ready), ctx)
+}
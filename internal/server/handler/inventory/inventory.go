@@ -0,0 +1,127 @@
+package inventory
+
+import (
+	"context"
+	"net/http"
+	"tradeservice/internal/logger"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/policy"
+	"tradeservice/internal/server/request"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:generate mockgen -source=inventory.go -destination=mockInventory/inventoryrepository.go
+
+type InventoryManager interface {
+	GetStockLevel(ctx context.Context, productID string) (models.StockLevelDto, error)
+	RecordMovement(ctx context.Context, productID string, movement models.StockMovementDto) (models.StockMovementDto, error)
+	GetStockMovements(ctx context.Context, productID string,
+		params models.ListParams) (models.Page[models.StockMovementDto], error)
+	Reserve(ctx context.Context, productID string, quantity int64) (models.StockLevelDto, error)
+	Release(ctx context.Context, productID string, quantity int64) (models.StockLevelDto, error)
+}
+
+type InventoryController struct {
+	manager InventoryManager
+	policy  policy.Authorizer
+}
+
+func NewInventoryHandler(manager InventoryManager, authorizer policy.Authorizer) *InventoryController {
+	return &InventoryController{manager, authorizer}
+}
+
+func (ctr InventoryController) GetStockLevel(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Stock level")
+
+	if err := ctr.policy.Authorize(echo, policy.InventoryRead); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.GetStockLevel(echo.Request().Context(), echo.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr InventoryController) GetStockMovements(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Stock movements")
+
+	if err := ctr.policy.Authorize(echo, policy.InventoryRead); err != nil {
+		return err
+	}
+
+	params, err := request.ListParams(echo)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.GetStockMovements(echo.Request().Context(), echo.Param("id"), params)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr InventoryController) RecordMovement(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Post Request for Stock movements")
+
+	if err := ctr.policy.Authorize(echo, policy.InventoryWrite); err != nil {
+		return err
+	}
+
+	var movement models.StockMovementDto
+	if err := request.Bind(echo, &movement); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.RecordMovement(echo.Request().Context(), echo.Param("id"), movement)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusCreated, res)
+}
+
+func (ctr InventoryController) Reserve(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Reserve Request for Stock")
+
+	if err := ctr.policy.Authorize(echo, policy.InventoryWrite); err != nil {
+		return err
+	}
+
+	var reservation models.StockReservation
+	if err := request.Bind(echo, &reservation); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.Reserve(echo.Request().Context(), echo.Param("id"), reservation.Quantity)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr InventoryController) Release(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Release Request for Stock")
+
+	if err := ctr.policy.Authorize(echo, policy.InventoryWrite); err != nil {
+		return err
+	}
+
+	var reservation models.StockReservation
+	if err := request.Bind(echo, &reservation); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.Release(echo.Request().Context(), echo.Param("id"), reservation.Quantity)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,166 @@
+package inventory_test
+
+import (
+	"net/http"
+	"testing"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/handler/inventory"
+	mockinventory "tradeservice/internal/server/handler/inventory/mockInventory"
+	"tradeservice/internal/server/policy"
+	"tradeservice/internal/server/utils"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestInventoryController_GetStockLevel(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockinventory.NewMockInventoryManager(ctrl)
+	handler := inventory.NewInventoryHandler(mockManager, policy.AllowAll())
+
+	productID := "1"
+
+	mockManager.EXPECT().GetStockLevel(gomock.Any(), productID).
+		Return(models.StockLevelDto{ProductID: productID, OnHand: 10, Reserved: 4, Available: 6}, nil)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/products/:id/stock", map[string]string{
+		"id": productID,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.GetStockLevel(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"available":6`)
+}
+
+func TestInventoryController_GetStockLevel_NotFound(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockinventory.NewMockInventoryManager(ctrl)
+	handler := inventory.NewInventoryHandler(mockManager, policy.AllowAll())
+
+	productID := "404"
+
+	mockManager.EXPECT().GetStockLevel(gomock.Any(), productID).Return(models.StockLevelDto{}, models.ErrNotFound)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/products/:id/stock", map[string]string{
+		"id": productID,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.GetStockLevel(echoCtx)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestInventoryController_RecordMovement_Created(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockinventory.NewMockInventoryManager(ctrl)
+	handler := inventory.NewInventoryHandler(mockManager, policy.AllowAll())
+
+	productID := "1"
+	movement := models.StockMovementDto{Type: models.MovementReceipt, Quantity: 5, Reason: "PO-17"}
+
+	mockManager.EXPECT().RecordMovement(gomock.Any(), productID, movement).
+		Return(models.StockMovementDto{ID: "9", ProductID: productID, Type: models.MovementReceipt, Quantity: 5}, nil)
+
+	rec, req, keys, vals := utils.CreateJSONContext(http.MethodPost, "/products/:id/stock/movements",
+		`{"type":"receipt","quantity":5,"reason":"PO-17"}`, map[string]string{
+			"id": productID,
+		})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.RecordMovement(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestInventoryController_RecordMovement_InsufficientStock(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockinventory.NewMockInventoryManager(ctrl)
+	handler := inventory.NewInventoryHandler(mockManager, policy.AllowAll())
+
+	productID := "1"
+	movement := models.StockMovementDto{Type: models.MovementSale, Quantity: 50}
+
+	mockManager.EXPECT().RecordMovement(gomock.Any(), productID, movement).
+		Return(models.StockMovementDto{}, models.ErrConflict)
+
+	rec, req, keys, vals := utils.CreateJSONContext(http.MethodPost, "/products/:id/stock/movements",
+		`{"type":"sale","quantity":50}`, map[string]string{
+			"id": productID,
+		})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.RecordMovement(echoCtx)
+	require.ErrorIs(t, err, models.ErrConflict)
+}
+
+func TestInventoryController_RecordMovement_FulfilsReservation(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockinventory.NewMockInventoryManager(ctrl)
+	handler := inventory.NewInventoryHandler(mockManager, policy.AllowAll())
+
+	productID := "1"
+	movement := models.StockMovementDto{Type: models.MovementSale, Quantity: 2, FulfilsReservation: true}
+
+	mockManager.EXPECT().RecordMovement(gomock.Any(), productID, movement).
+		Return(models.StockMovementDto{ID: "9", ProductID: productID, Type: models.MovementSale, Quantity: -2}, nil)
+
+	rec, req, keys, vals := utils.CreateJSONContext(http.MethodPost, "/products/:id/stock/movements",
+		`{"type":"sale","quantity":2,"fulfilsReservation":true}`, map[string]string{
+			"id": productID,
+		})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.RecordMovement(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
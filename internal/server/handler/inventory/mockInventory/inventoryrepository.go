@@ -0,0 +1,117 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: inventory.go
+//
+// Generated by this command:
+//
+//	mockgen -source=inventory.go -destination=mockInventory/inventoryrepository.go
+//
+
+// Package mock_inventory is a generated GoMock package.
+package mock_inventory
+
+import (
+	context "context"
+	reflect "reflect"
+	models "tradeservice/internal/models"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockInventoryManager is a mock of InventoryManager interface.
+type MockInventoryManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockInventoryManagerMockRecorder
+	isgomock struct{}
+}
+
+// MockInventoryManagerMockRecorder is the mock recorder for MockInventoryManager.
+type MockInventoryManagerMockRecorder struct {
+	mock *MockInventoryManager
+}
+
+// NewMockInventoryManager creates a new mock instance.
+func NewMockInventoryManager(ctrl *gomock.Controller) *MockInventoryManager {
+	mock := &MockInventoryManager{ctrl: ctrl}
+	mock.recorder = &MockInventoryManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInventoryManager) EXPECT() *MockInventoryManagerMockRecorder {
+	return m.recorder
+}
+
+// GetStockLevel mocks base method.
+func (m *MockInventoryManager) GetStockLevel(ctx context.Context, productID string) (models.StockLevelDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStockLevel", ctx, productID)
+	ret0, _ := ret[0].(models.StockLevelDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStockLevel indicates an expected call of GetStockLevel.
+func (mr *MockInventoryManagerMockRecorder) GetStockLevel(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStockLevel", reflect.TypeOf((*MockInventoryManager)(nil).GetStockLevel), ctx, productID)
+}
+
+// GetStockMovements mocks base method.
+func (m *MockInventoryManager) GetStockMovements(ctx context.Context, productID string, params models.ListParams) (models.Page[models.StockMovementDto], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStockMovements", ctx, productID, params)
+	ret0, _ := ret[0].(models.Page[models.StockMovementDto])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStockMovements indicates an expected call of GetStockMovements.
+func (mr *MockInventoryManagerMockRecorder) GetStockMovements(ctx, productID, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStockMovements", reflect.TypeOf((*MockInventoryManager)(nil).GetStockMovements), ctx, productID, params)
+}
+
+// RecordMovement mocks base method.
+func (m *MockInventoryManager) RecordMovement(ctx context.Context, productID string, movement models.StockMovementDto) (models.StockMovementDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordMovement", ctx, productID, movement)
+	ret0, _ := ret[0].(models.StockMovementDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordMovement indicates an expected call of RecordMovement.
+func (mr *MockInventoryManagerMockRecorder) RecordMovement(ctx, productID, movement any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordMovement", reflect.TypeOf((*MockInventoryManager)(nil).RecordMovement), ctx, productID, movement)
+}
+
+// Release mocks base method.
+func (m *MockInventoryManager) Release(ctx context.Context, productID string, quantity int64) (models.StockLevelDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, productID, quantity)
+	ret0, _ := ret[0].(models.StockLevelDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockInventoryManagerMockRecorder) Release(ctx, productID, quantity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockInventoryManager)(nil).Release), ctx, productID, quantity)
+}
+
+// Reserve mocks base method.
+func (m *MockInventoryManager) Reserve(ctx context.Context, productID string, quantity int64) (models.StockLevelDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", ctx, productID, quantity)
+	ret0, _ := ret[0].(models.StockLevelDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockInventoryManagerMockRecorder) Reserve(ctx, productID, quantity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockInventoryManager)(nil).Reserve), ctx, productID, quantity)
+}
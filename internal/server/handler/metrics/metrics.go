@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"net/http"
+	"tradeservice/internal/server/policy"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MetricsController serves the Prometheus exposition to callers granted metrics.read.
+type MetricsController struct {
+	handler http.Handler
+	policy  policy.Authorizer
+}
+
+func NewMetricsHandler(handler http.Handler, authorizer policy.Authorizer) *MetricsController {
+	return &MetricsController{handler: handler, policy: authorizer}
+}
+
+func (ctr MetricsController) GetMetrics(echo echo.Context) error {
+	if err := ctr.policy.Authorize(echo, policy.MetricsRead); err != nil {
+		return err
+	}
+
+	ctr.handler.ServeHTTP(echo.Response(), echo.Request())
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tradeservice/internal/server/handler/metrics"
+	"tradeservice/internal/server/middleware"
+	"tradeservice/internal/server/policy"
+	"tradeservice/internal/server/problem"
+	"tradeservice/internal/server/utils"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsController_GetMetrics_RequiresMetricsRead(t *testing.T) {
+	t.Parallel()
+
+	rules := make(map[string][]string, len(policy.Actions))
+	for _, action := range policy.Actions {
+		rules[action] = []string{"catalog-admin"}
+	}
+
+	rules[policy.MetricsRead] = []string{"monitoring"}
+
+	authorizer, err := policy.New(rules)
+	require.NoError(t, err)
+
+	exposition := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("http_requests_total 1\n"))
+	})
+	handler := metrics.NewMetricsHandler(exposition, authorizer)
+
+	for _, tc := range []struct {
+		name      string
+		principal *middleware.Principal
+		status    int
+	}{
+		{name: "anonymous", status: http.StatusForbidden},
+		{name: "other role", principal: &middleware.Principal{Subject: "ed", Roles: []string{"catalog-admin"}},
+			status: http.StatusForbidden},
+		{name: "monitoring role", principal: &middleware.Principal{Subject: "prom", Roles: []string{"monitoring"}},
+			status: http.StatusOK},
+		{name: "scoped key", principal: &middleware.Principal{Subject: "apikey:1", Scopes: []string{policy.MetricsRead}},
+			status: http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			principal := tc.principal
+
+			server := echo.New()
+			server.HTTPErrorHandler = problem.ErrorHandler(utils.NewTestLogger())
+			server.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(echoCtx echo.Context) error {
+					if principal != nil {
+						middleware.SetPrincipal(echoCtx, *principal)
+					}
+
+					return next(echoCtx)
+				}
+			})
+			server.GET("/metrics", handler.GetMetrics)
+
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+			assert.Equal(t, tc.status, rec.Code)
+
+			if tc.status == http.StatusOK {
+				assert.Contains(t, rec.Body.String(), "http_requests_total")
+			} else {
+				assert.NotContains(t, rec.Body.String(), "http_requests_total")
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: orders.go
+//
+// Generated by this command:
+//
+//	mockgen -source=orders.go -destination=mockOrders/ordersrepository.go
+//
+
+// Package mock_orders is a generated GoMock package.
+package mock_orders
+
+import (
+	context "context"
+	reflect "reflect"
+	models "tradeservice/internal/models"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrderManager is a mock of OrderManager interface.
+type MockOrderManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderManagerMockRecorder
+	isgomock struct{}
+}
+
+// MockOrderManagerMockRecorder is the mock recorder for MockOrderManager.
+type MockOrderManagerMockRecorder struct {
+	mock *MockOrderManager
+}
+
+// NewMockOrderManager creates a new mock instance.
+func NewMockOrderManager(ctrl *gomock.Controller) *MockOrderManager {
+	mock := &MockOrderManager{ctrl: ctrl}
+	mock.recorder = &MockOrderManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderManager) EXPECT() *MockOrderManagerMockRecorder {
+	return m.recorder
+}
+
+// AddOrder mocks base method.
+func (m *MockOrderManager) AddOrder(ctx context.Context, lines []models.OrderLineDto) (models.OrderDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOrder", ctx, lines)
+	ret0, _ := ret[0].(models.OrderDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddOrder indicates an expected call of AddOrder.
+func (mr *MockOrderManagerMockRecorder) AddOrder(ctx, lines any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockOrderManager)(nil).AddOrder), ctx, lines)
+}
+
+// GetOrder mocks base method.
+func (m *MockOrderManager) GetOrder(ctx context.Context, params models.ListParams) (models.Page[models.OrderDto], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", ctx, params)
+	ret0, _ := ret[0].(models.Page[models.OrderDto])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockOrderManagerMockRecorder) GetOrder(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrderManager)(nil).GetOrder), ctx, params)
+}
+
+// GetOrderByID mocks base method.
+func (m *MockOrderManager) GetOrderByID(ctx context.Context, id string) (models.OrderDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByID", ctx, id)
+	ret0, _ := ret[0].(models.OrderDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByID indicates an expected call of GetOrderByID.
+func (mr *MockOrderManagerMockRecorder) GetOrderByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByID", reflect.TypeOf((*MockOrderManager)(nil).GetOrderByID), ctx, id)
+}
+
+// SetOrderStatus mocks base method.
+func (m *MockOrderManager) SetOrderStatus(ctx context.Context, id, status string) (models.OrderDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOrderStatus", ctx, id, status)
+	ret0, _ := ret[0].(models.OrderDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOrderStatus indicates an expected call of SetOrderStatus.
+func (mr *MockOrderManagerMockRecorder) SetOrderStatus(ctx, id, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrderStatus", reflect.TypeOf((*MockOrderManager)(nil).SetOrderStatus), ctx, id, status)
+}
@@ -0,0 +1,107 @@
+package orders
+
+import (
+	"context"
+	"net/http"
+	"tradeservice/internal/logger"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/policy"
+	"tradeservice/internal/server/request"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:generate mockgen -source=orders.go -destination=mockOrders/ordersrepository.go
+
+type OrderManager interface {
+	AddOrder(ctx context.Context, lines []models.OrderLineDto) (models.OrderDto, error)
+	GetOrder(ctx context.Context, params models.ListParams) (models.Page[models.OrderDto], error)
+	GetOrderByID(ctx context.Context, id string) (models.OrderDto, error)
+	SetOrderStatus(ctx context.Context, id string, status string) (models.OrderDto, error)
+}
+
+type OrderController struct {
+	manager OrderManager
+	policy  policy.Authorizer
+}
+
+func NewOrderHandler(manager OrderManager, authorizer policy.Authorizer) *OrderController {
+	return &OrderController{manager, authorizer}
+}
+
+func (ctr OrderController) GetOrder(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Orders")
+
+	if err := ctr.policy.Authorize(echo, policy.OrdersRead); err != nil {
+		return err
+	}
+
+	params, err := request.ListParams(echo)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.GetOrder(echo.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr OrderController) GetOrderByID(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Order")
+
+	if err := ctr.policy.Authorize(echo, policy.OrdersRead); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.GetOrderByID(echo.Request().Context(), echo.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr OrderController) CreateOrder(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Post Request for Orders")
+
+	if err := ctr.policy.Authorize(echo, policy.OrdersCreate); err != nil {
+		return err
+	}
+
+	var order models.OrderDto
+	if err := request.Bind(echo, &order); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.AddOrder(echo.Request().Context(), order.Lines)
+	if err != nil {
+		return err
+	}
+
+	echo.Response().Header().Set("Location", "/orders/"+res.ID)
+
+	return echo.JSON(http.StatusCreated, res)
+}
+
+func (ctr OrderController) SetOrderStatus(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Status Request for Orders")
+
+	if err := ctr.policy.Authorize(echo, policy.OrdersStatus); err != nil {
+		return err
+	}
+
+	var change models.OrderStatusChange
+	if err := request.Bind(echo, &change); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.SetOrderStatus(echo.Request().Context(), echo.Param("id"), change.Status)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,102 @@
+package orders_test
+
+import (
+	"net/http"
+	"testing"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/handler/orders"
+	mockorders "tradeservice/internal/server/handler/orders/mockOrders"
+	"tradeservice/internal/server/policy"
+	"tradeservice/internal/server/utils"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOrderController_CreateOrder_Created(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockorders.NewMockOrderManager(ctrl)
+	handler := orders.NewOrderHandler(mockManager, policy.AllowAll())
+
+	lines := []models.OrderLineDto{{ProductID: "1", Quantity: 2}}
+
+	mockManager.EXPECT().AddOrder(gomock.Any(), lines).
+		Return(models.OrderDto{ID: "5", Status: models.OrderDraft, Currency: "EUR", Total: 200, Lines: lines}, nil)
+
+	rec, req, keys, vals := utils.CreateJSONContext(http.MethodPost, "/orders",
+		`{"lines":[{"productId":"1","quantity":2}]}`, nil)
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.CreateOrder(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "/orders/5", rec.Header().Get("Location"))
+}
+
+func TestOrderController_GetOrderByID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockorders.NewMockOrderManager(ctrl)
+	handler := orders.NewOrderHandler(mockManager, policy.AllowAll())
+
+	orderID := "404"
+
+	mockManager.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(models.OrderDto{}, models.ErrNotFound)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/orders/:id", map[string]string{
+		"id": orderID,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.GetOrderByID(echoCtx)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestOrderController_SetOrderStatus_IllegalTransition(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockorders.NewMockOrderManager(ctrl)
+	handler := orders.NewOrderHandler(mockManager, policy.AllowAll())
+
+	orderID := "5"
+
+	mockManager.EXPECT().SetOrderStatus(gomock.Any(), orderID, models.OrderShipped).
+		Return(models.OrderDto{}, models.ErrConflict)
+
+	rec, req, keys, vals := utils.CreateJSONContext(http.MethodPost, "/orders/:id/status", `{"status":"shipped"}`,
+		map[string]string{
+			"id": orderID,
+		})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.SetOrderStatus(echoCtx)
+	require.ErrorIs(t, err, models.ErrConflict)
+}
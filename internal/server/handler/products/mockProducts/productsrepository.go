@@ -6,7 +6,7 @@
 //	mockgen -source=products.go -destination=mockProducts/productsrepository.go
 //
 
-// Package mock_handlers is a generated GoMock package.
+// Package mock_products is a generated GoMock package.
 package mock_products
 
 import (
@@ -42,18 +42,18 @@ func (m *MockProductManager) EXPECT() *MockProductManagerMockRecorder {
 }
 
 // AddProduct mocks base method.
-func (m *MockProductManager) AddProduct(ctx context.Context, name string) (string, error) {
+func (m *MockProductManager) AddProduct(ctx context.Context, product models.ProductDto) (models.ProductDto, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddProduct", ctx, name)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "AddProduct", ctx, product)
+	ret0, _ := ret[0].(models.ProductDto)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddProduct indicates an expected call of AddProduct.
-func (mr *MockProductManagerMockRecorder) AddProduct(ctx, name any) *gomock.Call {
+func (mr *MockProductManagerMockRecorder) AddProduct(ctx, product any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockProductManager)(nil).AddProduct), ctx, name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockProductManager)(nil).AddProduct), ctx, product)
 }
 
 // DeleteProduct mocks base method.
@@ -71,30 +71,61 @@ func (mr *MockProductManagerMockRecorder) DeleteProduct(ctx, id any) *gomock.Cal
 }
 
 // GetProduct mocks base method.
-func (m *MockProductManager) GetProduct(ctx context.Context) ([]models.ProductDto, error) {
+func (m *MockProductManager) GetProduct(ctx context.Context, params models.ListParams) (models.Page[models.ProductDto], error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProduct", ctx)
-	ret0, _ := ret[0].([]models.ProductDto)
+	ret := m.ctrl.Call(m, "GetProduct", ctx, params)
+	ret0, _ := ret[0].(models.Page[models.ProductDto])
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetProduct indicates an expected call of GetProduct.
-func (mr *MockProductManagerMockRecorder) GetProduct(ctx any) *gomock.Call {
+func (mr *MockProductManagerMockRecorder) GetProduct(ctx, params any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*MockProductManager)(nil).GetProduct), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*MockProductManager)(nil).GetProduct), ctx, params)
+}
+
+// GetProductByID mocks base method.
+func (m *MockProductManager) GetProductByID(ctx context.Context, id string) (models.ProductDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductByID", ctx, id)
+	ret0, _ := ret[0].(models.ProductDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductByID indicates an expected call of GetProductByID.
+func (mr *MockProductManagerMockRecorder) GetProductByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductByID", reflect.TypeOf((*MockProductManager)(nil).GetProductByID), ctx, id)
+}
+
+// PatchProduct mocks base method.
+func (m *MockProductManager) PatchProduct(ctx context.Context, id string, patch models.ProductPatch) (models.ProductDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchProduct", ctx, id, patch)
+	ret0, _ := ret[0].(models.ProductDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchProduct indicates an expected call of PatchProduct.
+func (mr *MockProductManagerMockRecorder) PatchProduct(ctx, id, patch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchProduct", reflect.TypeOf((*MockProductManager)(nil).PatchProduct), ctx, id, patch)
 }
 
 // SetProduct mocks base method.
-func (m *MockProductManager) SetProduct(ctx context.Context, id, name string) error {
+func (m *MockProductManager) SetProduct(ctx context.Context, id string, product models.ProductDto) (models.ProductDto, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetProduct", ctx, id, name)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "SetProduct", ctx, id, product)
+	ret0, _ := ret[0].(models.ProductDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // SetProduct indicates an expected call of SetProduct.
-func (mr *MockProductManagerMockRecorder) SetProduct(ctx, id, name any) *gomock.Call {
+func (mr *MockProductManagerMockRecorder) SetProduct(ctx, id, product any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProduct", reflect.TypeOf((*MockProductManager)(nil).SetProduct), ctx, id, name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProduct", reflect.TypeOf((*MockProductManager)(nil).SetProduct), ctx, id, product)
 }
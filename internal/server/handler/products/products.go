@@ -2,92 +2,279 @@ package products
 
 import (
 	"context"
-	"errors"
-	"log/slog"
+	"crypto/rand"
 	"net/http"
+	"tradeservice/internal/logger"
 	"tradeservice/internal/models"
+	"tradeservice/internal/server/policy"
+	"tradeservice/internal/server/request"
 
 	"github.com/labstack/echo/v4"
 )
 
+// HeaderNextCursor carries the cursor of the next page on routes that respond with a bare array.
+const HeaderNextCursor = "X-Next-Cursor"
+
+// legacyCurrency is the currency of products created on the legacy route without one, as in the schema.
+const legacyCurrency = "USD"
+
 //go:generate mockgen -source=products.go -destination=mockProducts/productsrepository.go
 
 type ProductManager interface {
-	AddProduct(ctx context.Context, name string) (id string, err error)
-	GetProduct(ctx context.Context) ([]models.ProductDto, error)
-	SetProduct(ctx context.Context, id string, name string) error
+	AddProduct(ctx context.Context, product models.ProductDto) (models.ProductDto, error)
+	GetProduct(ctx context.Context, params models.ListParams) (models.Page[models.ProductDto], error)
+	GetProductByID(ctx context.Context, id string) (models.ProductDto, error)
+	SetProduct(ctx context.Context, id string, product models.ProductDto) (models.ProductDto, error)
+	PatchProduct(ctx context.Context, id string, patch models.ProductPatch) (models.ProductDto, error)
 	DeleteProduct(ctx context.Context, id string) error
 }
 
+// productReplacement is the body of PUT /products/:id. Active is required there, so a replace that leaves it
+// out can't reactivate a product.
+type productReplacement struct {
+	models.ProductDto
+	Active *bool `json:"active"`
+}
+
 type ProductController struct {
 	manager ProductManager
-	logger  *slog.Logger
+	policy  policy.Authorizer
 }
 
-func NewProductHandler(manager ProductManager, log *slog.Logger) *ProductController {
-	return &ProductController{manager, log}
+func NewProductHandler(manager ProductManager, authorizer policy.Authorizer) *ProductController {
+	return &ProductController{manager, authorizer}
 }
 
 func (ctr ProductController) GetProduct(echo echo.Context) error {
-	ctr.logger.Debug("Get Request for Products")
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Products")
+
+	if err := ctr.policy.Authorize(echo, policy.ProductsRead); err != nil {
+		return err
+	}
+
+	params, err := request.ListParams(echo)
+	if err != nil {
+		return err
+	}
 
-	res, err := ctr.manager.GetProduct(echo.Request().Context())
+	res, err := ctr.manager.GetProduct(echo.Request().Context(), params)
 	if err != nil {
-		return echo.NoContent(http.StatusInternalServerError)
+		return err
 	}
 
 	return echo.JSON(http.StatusOK, res)
 }
 
-func (ctr ProductController) AddProduct(echo echo.Context) error {
-	ctr.logger.Debug("Post Request for Products")
+// ListProducts serves the deprecated GET /product route. It keeps the route's bare array response and passes
+// the cursor of the next page in the X-Next-Cursor header.
+func (ctr ProductController) ListProducts(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Products")
 
-	productName := echo.Param("productName")
+	if err := ctr.policy.Authorize(echo, policy.ProductsRead); err != nil {
+		return err
+	}
+
+	params, err := request.ListParams(echo)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.GetProduct(echo.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	if res.NextCursor != "" {
+		echo.Response().Header().Set(HeaderNextCursor, res.NextCursor)
+	}
+
+	if res.Items == nil {
+		res.Items = []models.ProductDto{}
+	}
+
+	return echo.JSON(http.StatusOK, res.Items)
+}
+
+// GetCategoryProducts lists the products assigned to a category, optionally including its subcategories.
+func (ctr ProductController) GetCategoryProducts(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Category products")
+
+	if err := ctr.policy.Authorize(echo, policy.ProductsRead); err != nil {
+		return err
+	}
 
-	res, err := ctr.manager.AddProduct(echo.Request().Context(), productName)
+	params, err := request.ListParams(echo)
 	if err != nil {
-		if errors.Is(err, models.ErrUnique) {
-			return echo.NoContent(http.StatusConflict)
-		}
+		return err
+	}
+
+	params.CategoryID = echo.Param("id")
+
+	res, err := ctr.manager.GetProduct(echo.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr ProductController) GetProductByID(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Product")
+
+	if err := ctr.policy.Authorize(echo, policy.ProductsRead); err != nil {
+		return err
+	}
+
+	productID := echo.Param("id")
+
+	res, err := ctr.manager.GetProductByID(echo.Request().Context(), productID)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr ProductController) CreateProduct(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Post Request for Products")
+
+	if err := ctr.policy.Authorize(echo, policy.ProductsWrite); err != nil {
+		return err
+	}
+
+	product := models.ProductDto{Active: true}
+	if err := request.Bind(echo, &product); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.AddProduct(echo.Request().Context(), product)
+	if err != nil {
+		return err
+	}
 
-		return echo.NoContent(http.StatusInternalServerError)
+	echo.Response().Header().Set("Location", "/products/"+res.ID)
+
+	return echo.JSON(http.StatusCreated, res)
+}
+
+// AddProduct serves the deprecated POST /product/create/:productName route.
+func (ctr ProductController) AddProduct(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Post Request for Products")
+
+	if err := ctr.policy.Authorize(echo, policy.ProductsWrite); err != nil {
+		return err
+	}
+
+	product := models.ProductDto{Active: true}
+	if err := request.Bind(echo, &product); err != nil {
+		return err
+	}
+
+	if productName := echo.Param("productName"); productName != "" {
+		product.Name = productName
+	}
+
+	// Legacy clients only send a name, so the fields required since then get defaults.
+	if product.SKU == "" {
+		product.SKU = "SKU-" + rand.Text()
+	}
+
+	if product.Currency == "" {
+		product.Currency = legacyCurrency
+	}
+
+	res, err := ctr.manager.AddProduct(echo.Request().Context(), product)
+	if err != nil {
+		return err
 	}
 
 	return echo.JSON(http.StatusOK, res)
 }
 
 func (ctr ProductController) DeleteProduct(echo echo.Context) error {
-	ctr.logger.Debug("Delete Request for Products")
+	logger.FromContext(echo.Request().Context()).Debug("Delete Request for Products")
 
-	productID := echo.Param("productId")
+	if err := ctr.policy.Authorize(echo, policy.ProductsDelete); err != nil {
+		return err
+	}
+
+	productID := echo.Param("id")
 
 	err := ctr.manager.DeleteProduct(echo.Request().Context(), productID)
 	if err != nil {
-		if errors.Is(err, models.ErrNotFound) {
-			return echo.NoContent(http.StatusNotFound)
-		}
-
-		return echo.NoContent(http.StatusInternalServerError)
+		return err
 	}
 
 	return echo.NoContent(http.StatusOK)
 }
 
+func (ctr ProductController) UpdateProduct(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Put Request for Products")
+
+	if err := ctr.policy.Authorize(echo, policy.ProductsWrite); err != nil {
+		return err
+	}
+
+	productID := echo.Param("id")
+
+	var body productReplacement
+	if err := request.Bind(echo, &body); err != nil {
+		return err
+	}
+
+	if body.Active == nil {
+		return &models.ValidationError{Fields: []models.FieldError{{Field: "active", Message: "is required"}}}
+	}
+
+	product := body.ProductDto
+	product.Active = *body.Active
+
+	res, err := ctr.manager.SetProduct(echo.Request().Context(), productID, product)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr ProductController) PatchProduct(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Patch Request for Products")
+
+	if err := ctr.policy.Authorize(echo, policy.ProductsWrite); err != nil {
+		return err
+	}
+
+	productID := echo.Param("id")
+
+	var patch models.ProductPatch
+	if err := request.Bind(echo, &patch); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.PatchProduct(echo.Request().Context(), productID, patch)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+// SetProduct serves the deprecated POST /product/update/:productName/:productId route. It only renames the
+// product; the other fields are left as they are.
 func (ctr ProductController) SetProduct(echo echo.Context) error {
-	ctr.logger.Debug("Patch Request for Products")
+	logger.FromContext(echo.Request().Context()).Debug("Patch Request for Products")
 
-	productID := echo.Param("productId")
+	if err := ctr.policy.Authorize(echo, policy.ProductsWrite); err != nil {
+		return err
+	}
 
+	productID := echo.Param("productId")
 	productName := echo.Param("productName")
 
-	err := ctr.manager.SetProduct(echo.Request().Context(), productID, productName)
+	res, err := ctr.manager.PatchProduct(echo.Request().Context(), productID, models.ProductPatch{Name: &productName})
 	if err != nil {
-		if errors.Is(err, models.ErrNotFound) {
-			return echo.NoContent(http.StatusNotFound)
-		}
-
-		return echo.NoContent(http.StatusInternalServerError)
+		return err
 	}
 
-	return echo.NoContent(http.StatusOK)
+	return echo.JSON(http.StatusOK, res)
 }
@@ -1,21 +1,37 @@
 package products_test
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 	"tradeservice/internal/models"
 	"tradeservice/internal/server/handler/products"
 	mockproducts "tradeservice/internal/server/handler/products/mockProducts"
+	"tradeservice/internal/server/policy"
+	"tradeservice/internal/server/problem"
 	"tradeservice/internal/server/utils"
+	"tradeservice/internal/services/product"
+	"tradeservice/internal/storage/memory"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
 
+// legacyProduct matches the product the legacy create route makes from name: a generated SKU and USD.
+func legacyProduct(name string) gomock.Matcher {
+	return gomock.Cond(func(product models.ProductDto) bool {
+		return product.Name == name && strings.HasPrefix(product.SKU, "SKU-") && product.Currency == "USD" &&
+			product.Active
+	})
+}
+
 func TestCategoriesController_GetProduct(t *testing.T) {
 	t.Parallel()
 
@@ -24,15 +40,14 @@ func TestCategoriesController_GetProduct(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockproducts.NewMockProductManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := products.NewProductHandler(mockManager, logger)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
 
-	productList := []models.ProductDto{
+	productList := models.Page[models.ProductDto]{Items: []models.ProductDto{
 		{ID: "1", Name: "cat1"},
 		{ID: "2", Name: "cat2"},
-	}
+	}}
 
-	mockManager.EXPECT().GetProduct(gomock.Any()).Return(productList, nil)
+	mockManager.EXPECT().GetProduct(gomock.Any(), models.ListParams{}).Return(productList, nil)
 
 	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/products", nil)
 
@@ -46,6 +61,37 @@ func TestCategoriesController_GetProduct(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
 
+func TestCategoriesController_ListProducts_BareArray(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockproducts.NewMockProductManager(ctrl)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
+
+	productList := models.Page[models.ProductDto]{Items: []models.ProductDto{{ID: "1", Name: "cat1"}}, NextCursor: "abc"}
+
+	mockManager.EXPECT().GetProduct(gomock.Any(), models.ListParams{}).Return(productList, nil)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/product", nil)
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.ListProducts(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "abc", rec.Header().Get(products.HeaderNextCursor))
+
+	var res []models.ProductDto
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, productList.Items, res)
+}
+
 func TestCategoriesController_GetProduct_Error(t *testing.T) {
 	t.Parallel()
 
@@ -54,10 +100,9 @@ func TestCategoriesController_GetProduct_Error(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockproducts.NewMockProductManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := products.NewProductHandler(mockManager, logger)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
 
-	mockManager.EXPECT().GetProduct(gomock.Any()).Return(nil, models.ErrDB)
+	mockManager.EXPECT().GetProduct(gomock.Any(), models.ListParams{}).Return(models.Page[models.ProductDto]{}, models.ErrDB)
 
 	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/products", nil)
 
@@ -67,8 +112,7 @@ func TestCategoriesController_GetProduct_Error(t *testing.T) {
 	echoCtx.SetParamValues(vals...)
 
 	err := handler.GetProduct(echoCtx)
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.ErrorIs(t, err, models.ErrDB)
 }
 
 func TestCategoriesController_AddProduct_Success(t *testing.T) {
@@ -79,16 +123,16 @@ func TestCategoriesController_AddProduct_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockproducts.NewMockProductManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := products.NewProductHandler(mockManager, logger)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
 
 	productName := "newcat"
 	newID := "42"
 
-	mockManager.EXPECT().AddProduct(gomock.Any(), productName).Return(newID, nil)
+	mockManager.EXPECT().AddProduct(gomock.Any(), legacyProduct(productName)).
+		Return(models.ProductDto{ID: newID, Name: productName, Active: true}, nil)
 
 	rec, req, keys, vals := utils.CreateContext(http.MethodPost, "/products/create/:productName", map[string]string{
-		"categoryName": productName,
+		"productName": productName,
 	})
 
 	e := echo.New()
@@ -99,7 +143,10 @@ func TestCategoriesController_AddProduct_Success(t *testing.T) {
 	err := handler.AddProduct(echoCtx)
 	require.NoError(t, err)
 	assert.Equal(t, http.StatusOK, rec.Code)
-	assert.Equal(t, newID, strings.TrimSpace(rec.Body.String()))
+
+	var res models.ProductDto
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, newID, res.ID)
 }
 
 func TestCategoriesController_AddProduct_Conflict(t *testing.T) {
@@ -110,12 +157,12 @@ func TestCategoriesController_AddProduct_Conflict(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockproducts.NewMockProductManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := products.NewProductHandler(mockManager, logger)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
 
 	productName := "dupProd"
 
-	mockManager.EXPECT().AddProduct(gomock.Any(), productName).Return("", models.ErrUnique)
+	mockManager.EXPECT().AddProduct(gomock.Any(), legacyProduct(productName)).
+		Return(models.ProductDto{}, models.ErrUnique)
 
 	rec, req, keys, vals := utils.CreateContext(http.MethodPost, "/products/create/:productName", map[string]string{
 		"productName": productName,
@@ -127,8 +174,7 @@ func TestCategoriesController_AddProduct_Conflict(t *testing.T) {
 	echoCtx.SetParamValues(vals...)
 
 	err := handler.AddProduct(echoCtx)
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusConflict, rec.Code)
+	require.ErrorIs(t, err, models.ErrUnique)
 }
 
 func TestCategoriesController_DeleteProduct_Success(t *testing.T) {
@@ -139,15 +185,14 @@ func TestCategoriesController_DeleteProduct_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockproducts.NewMockProductManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := products.NewProductHandler(mockManager, logger)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
 
 	productID := "42"
 
 	mockManager.EXPECT().DeleteProduct(gomock.Any(), productID).Return(nil)
 
-	rec, req, keys, vals := utils.CreateContext(http.MethodDelete, "/product/:productId", map[string]string{
-		"productId": productID,
+	rec, req, keys, vals := utils.CreateContext(http.MethodDelete, "/products/:id", map[string]string{
+		"id": productID,
 	})
 
 	e := echo.New()
@@ -168,15 +213,14 @@ func TestCategoriesController_DeleteProduct_NotFound(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockproducts.NewMockProductManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := products.NewProductHandler(mockManager, logger)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
 
 	productID := "42"
 
 	mockManager.EXPECT().DeleteProduct(gomock.Any(), productID).Return(models.ErrNotFound)
 
-	rec, req, keys, vals := utils.CreateContext(http.MethodDelete, "/product/:productId", map[string]string{
-		"productId": productID,
+	rec, req, keys, vals := utils.CreateContext(http.MethodDelete, "/products/:id", map[string]string{
+		"id": productID,
 	})
 
 	e := echo.New()
@@ -185,8 +229,7 @@ func TestCategoriesController_DeleteProduct_NotFound(t *testing.T) {
 	echoCtx.SetParamValues(vals...)
 
 	err := handler.DeleteProduct(echoCtx)
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusNotFound, rec.Code)
+	require.ErrorIs(t, err, models.ErrNotFound)
 }
 
 func TestCategoriesController_SetProduct_Success(t *testing.T) {
@@ -197,13 +240,13 @@ func TestCategoriesController_SetProduct_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockproducts.NewMockProductManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := products.NewProductHandler(mockManager, logger)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
 
 	productID := "42"
 	productName := "updated"
 
-	mockManager.EXPECT().SetProduct(gomock.Any(), productID, productName).Return(nil)
+	mockManager.EXPECT().PatchProduct(gomock.Any(), productID, models.ProductPatch{Name: &productName}).
+		Return(models.ProductDto{ID: productID, Name: productName, Active: true}, nil)
 
 	rec, req, keys, vals := utils.CreateContext(http.MethodPost, "/update/:productName/:productId", map[string]string{
 		"productId":   productID,
@@ -228,13 +271,13 @@ func TestCategoriesController_SetProduct_NotFound(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockManager := mockproducts.NewMockProductManager(ctrl)
-	logger := utils.NewTestLogger()
-	handler := products.NewProductHandler(mockManager, logger)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
 
 	productID := "42"
 	productName := "updated"
 
-	mockManager.EXPECT().SetProduct(gomock.Any(), productID, productName).Return(models.ErrNotFound)
+	mockManager.EXPECT().PatchProduct(gomock.Any(), productID, models.ProductPatch{Name: &productName}).
+		Return(models.ProductDto{}, models.ErrNotFound)
 
 	rec, req, keys, vals := utils.CreateContext(http.MethodPost, "/update/:productName/:productId", map[string]string{
 		"productId":   productID,
@@ -247,6 +290,328 @@ func TestCategoriesController_SetProduct_NotFound(t *testing.T) {
 	echoCtx.SetParamValues(vals...)
 
 	err := handler.SetProduct(echoCtx)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestCategoriesController_AddProduct_Invalid(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockproducts.NewMockProductManager(ctrl)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
+
+	productName := "noSku"
+
+	mockManager.EXPECT().AddProduct(gomock.Any(), legacyProduct(productName)).
+		Return(models.ProductDto{}, models.ErrValidation)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodPost, "/products/create/:productName", map[string]string{
+		"productName": productName,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.AddProduct(echoCtx)
+	require.ErrorIs(t, err, models.ErrValidation)
+}
+
+func TestCategoriesController_CreateProduct_Created(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockproducts.NewMockProductManager(ctrl)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
+
+	product := models.ProductDto{Name: "Lenovo / ThinkPad", SKU: "LEN-1", UnitPrice: 129900, Currency: "EUR", Active: true}
+	newID := "42"
+
+	created := product
+	created.ID = newID
+
+	mockManager.EXPECT().AddProduct(gomock.Any(), product).Return(created, nil)
+
+	rec, req, keys, vals := utils.CreateJSONContext(http.MethodPost, "/products",
+		`{"name":"Lenovo / ThinkPad","sku":"LEN-1","unitPrice":129900,"currency":"EUR"}`, nil)
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.CreateProduct(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "/products/"+newID, rec.Header().Get("Location"))
+
+	var res models.ProductDto
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, created, res)
+}
+
+func TestCategoriesController_PatchProduct_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockproducts.NewMockProductManager(ctrl)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
+
+	productID := "42"
+	price := int64(99900)
+
+	mockManager.EXPECT().PatchProduct(gomock.Any(), productID, models.ProductPatch{UnitPrice: &price}).
+		Return(models.ProductDto{ID: productID, UnitPrice: price}, nil)
+
+	rec, req, keys, vals := utils.CreateJSONContext(http.MethodPatch, "/products/:id", `{"unitPrice":99900}`, map[string]string{
+		"id": productID,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.PatchProduct(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCategoriesController_UpdateProduct_RequiresActive(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockproducts.NewMockProductManager(ctrl)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
+
+	productID := "42"
+	product := models.ProductDto{Name: "Lenovo", SKU: "LEN-1", Currency: "EUR"}
+
+	mockManager.EXPECT().SetProduct(gomock.Any(), productID, product).Return(product, nil)
+
+	for body, expected := range map[string]error{
+		`{"name":"Lenovo","sku":"LEN-1","currency":"EUR","active":false}`: nil,
+		`{"name":"Lenovo","sku":"LEN-1","currency":"EUR"}`:                models.ErrValidation,
+	} {
+		rec, req, keys, vals := utils.CreateJSONContext(http.MethodPut, "/products/:id", body, map[string]string{
+			"id": productID,
+		})
+
+		e := echo.New()
+		echoCtx := e.NewContext(req, rec)
+		echoCtx.SetParamNames(keys...)
+		echoCtx.SetParamValues(vals...)
+
+		err := handler.UpdateProduct(echoCtx)
+		if expected != nil {
+			require.ErrorIs(t, err, expected)
+
+			continue
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+}
+
+func TestCategoriesController_GetProductByID_Success(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockproducts.NewMockProductManager(ctrl)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
+
+	productID := "42"
+
+	mockManager.EXPECT().GetProductByID(gomock.Any(), productID).Return(models.ProductDto{ID: productID, Name: "Lenovo"}, nil)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/products/:id", map[string]string{
+		"id": productID,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.GetProductByID(echoCtx)
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCategoriesController_GetProductByID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockproducts.NewMockProductManager(ctrl)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
+
+	productID := "42"
+
+	mockManager.EXPECT().GetProductByID(gomock.Any(), productID).Return(models.ProductDto{}, models.ErrNotFound)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/products/:id", map[string]string{
+		"id": productID,
+	})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.GetProductByID(echoCtx)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestCategoriesController_GetProduct_Filtered(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockproducts.NewMockProductManager(ctrl)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
+
+	createdAfter := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	params := models.ListParams{
+		Limit:        10,
+		Cursor:       "abc",
+		Sort:         "-name",
+		NamePrefix:   "Len",
+		CreatedAfter: &createdAfter,
+		CategoryID:   "7",
+	}
+
+	mockManager.EXPECT().GetProduct(gomock.Any(), params).
+		Return(models.Page[models.ProductDto]{Items: []models.ProductDto{{ID: "1"}}, NextCursor: "def"}, nil)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet,
+		"/products?limit=10&cursor=abc&sort=-name&name_prefix=Len&created_after=2025-01-02T03:04:05Z&category_id=7", nil)
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.GetProduct(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"next_cursor":"def"`)
+}
+
+func TestCategoriesController_GetProduct_InvalidLimit(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockproducts.NewMockProductManager(ctrl)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/products?limit=ten", nil)
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.GetProduct(echoCtx)
+	require.ErrorIs(t, err, models.ErrValidation)
+}
+
+func TestCategoriesController_GetCategoryProducts_Subcategories(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockproducts.NewMockProductManager(ctrl)
+	handler := products.NewProductHandler(mockManager, policy.AllowAll())
+
+	categoryID := "7"
+
+	mockManager.EXPECT().GetProduct(gomock.Any(), models.ListParams{CategoryID: categoryID, IncludeSubcategories: true}).
+		Return(models.Page[models.ProductDto]{Items: []models.ProductDto{{ID: "1"}}}, nil)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/categories/:id/products?include_subcategories=true",
+		map[string]string{
+			"id": categoryID,
+		})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.GetCategoryProducts(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCategoriesController_AddProduct_LegacyCreateWithNameOnly(t *testing.T) {
+	t.Parallel()
+
+	created := prometheus.NewCounter(prometheus.CounterOpts{Name: "created"})
+	handler := products.NewProductHandler(product.New(memory.NewProducts(memory.New()), created), policy.AllowAll())
+
+	for _, name := range []string{"kettle", "toaster"} {
+		rec, req, keys, vals := utils.CreateContext(http.MethodPost, "/product/create/"+name,
+			map[string]string{"productName": name})
+
+		e := echo.New()
+		echoCtx := e.NewContext(req, rec)
+		echoCtx.SetParamNames(keys...)
+		echoCtx.SetParamValues(vals...)
+
+		require.NoError(t, handler.AddProduct(echoCtx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var res models.ProductDto
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Equal(t, name, res.Name)
+		assert.Equal(t, "USD", res.Currency)
+		assert.True(t, res.Active)
+	}
+}
+
+func TestCategoriesController_ProductLists_RejectMalformedCategoryID(t *testing.T) {
+	t.Parallel()
+
+	created := prometheus.NewCounter(prometheus.CounterOpts{Name: "created"})
+	handler := products.NewProductHandler(product.New(memory.NewProducts(memory.New()), created), policy.AllowAll())
+
+	server := echo.New()
+	server.HTTPErrorHandler = problem.ErrorHandler(utils.NewTestLogger())
+	server.GET("/products", handler.GetProduct)
+	server.GET("/categories/:id/products", handler.GetCategoryProducts)
+
+	for _, target := range []string{"/products?category_id=abc", "/categories/abc/products",
+		"/categories/99999999999/products"} {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
 }
@@ -0,0 +1,161 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webhooks.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webhooks.go -destination=mockWebhooks/webhooksrepository.go
+//
+
+// Package mock_webhooks is a generated GoMock package.
+package mock_webhooks
+
+import (
+	context "context"
+	reflect "reflect"
+	models "tradeservice/internal/models"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWebhookManager is a mock of WebhookManager interface.
+type MockWebhookManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookManagerMockRecorder
+	isgomock struct{}
+}
+
+// MockWebhookManagerMockRecorder is the mock recorder for MockWebhookManager.
+type MockWebhookManagerMockRecorder struct {
+	mock *MockWebhookManager
+}
+
+// NewMockWebhookManager creates a new mock instance.
+func NewMockWebhookManager(ctrl *gomock.Controller) *MockWebhookManager {
+	mock := &MockWebhookManager{ctrl: ctrl}
+	mock.recorder = &MockWebhookManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookManager) EXPECT() *MockWebhookManagerMockRecorder {
+	return m.recorder
+}
+
+// AddWebhook mocks base method.
+func (m *MockWebhookManager) AddWebhook(ctx context.Context, webhook models.WebhookSecret) (models.WebhookSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWebhook", ctx, webhook)
+	ret0, _ := ret[0].(models.WebhookSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddWebhook indicates an expected call of AddWebhook.
+func (mr *MockWebhookManagerMockRecorder) AddWebhook(ctx, webhook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWebhook", reflect.TypeOf((*MockWebhookManager)(nil).AddWebhook), ctx, webhook)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookManager) DeleteWebhook(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookManagerMockRecorder) DeleteWebhook(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookManager)(nil).DeleteWebhook), ctx, id)
+}
+
+// GetWebhookByID mocks base method.
+func (m *MockWebhookManager) GetWebhookByID(ctx context.Context, id string) (models.WebhookDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookByID", ctx, id)
+	ret0, _ := ret[0].(models.WebhookDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookByID indicates an expected call of GetWebhookByID.
+func (mr *MockWebhookManagerMockRecorder) GetWebhookByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookByID", reflect.TypeOf((*MockWebhookManager)(nil).GetWebhookByID), ctx, id)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockWebhookManager) GetWebhookDeliveries(ctx context.Context, webhookID string, params models.ListParams) (models.Page[models.WebhookDeliveryDto], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, webhookID, params)
+	ret0, _ := ret[0].(models.Page[models.WebhookDeliveryDto])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockWebhookManagerMockRecorder) GetWebhookDeliveries(ctx, webhookID, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockWebhookManager)(nil).GetWebhookDeliveries), ctx, webhookID, params)
+}
+
+// GetWebhookDelivery mocks base method.
+func (m *MockWebhookManager) GetWebhookDelivery(ctx context.Context, webhookID, id string) (models.WebhookDeliveryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDelivery", ctx, webhookID, id)
+	ret0, _ := ret[0].(models.WebhookDeliveryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDelivery indicates an expected call of GetWebhookDelivery.
+func (mr *MockWebhookManagerMockRecorder) GetWebhookDelivery(ctx, webhookID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDelivery", reflect.TypeOf((*MockWebhookManager)(nil).GetWebhookDelivery), ctx, webhookID, id)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockWebhookManager) GetWebhooks(ctx context.Context) ([]models.WebhookDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", ctx)
+	ret0, _ := ret[0].([]models.WebhookDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockWebhookManagerMockRecorder) GetWebhooks(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockWebhookManager)(nil).GetWebhooks), ctx)
+}
+
+// RedeliverWebhookDelivery mocks base method.
+func (m *MockWebhookManager) RedeliverWebhookDelivery(ctx context.Context, webhookID, id string) (models.WebhookDeliveryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeliverWebhookDelivery", ctx, webhookID, id)
+	ret0, _ := ret[0].(models.WebhookDeliveryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeliverWebhookDelivery indicates an expected call of RedeliverWebhookDelivery.
+func (mr *MockWebhookManagerMockRecorder) RedeliverWebhookDelivery(ctx, webhookID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeliverWebhookDelivery", reflect.TypeOf((*MockWebhookManager)(nil).RedeliverWebhookDelivery), ctx, webhookID, id)
+}
+
+// SetWebhook mocks base method.
+func (m *MockWebhookManager) SetWebhook(ctx context.Context, id string, webhook models.WebhookDto) (models.WebhookDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWebhook", ctx, id, webhook)
+	ret0, _ := ret[0].(models.WebhookDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWebhook indicates an expected call of SetWebhook.
+func (mr *MockWebhookManagerMockRecorder) SetWebhook(ctx, id, webhook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWebhook", reflect.TypeOf((*MockWebhookManager)(nil).SetWebhook), ctx, id, webhook)
+}
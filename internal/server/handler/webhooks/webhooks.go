@@ -0,0 +1,184 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"tradeservice/internal/logger"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/policy"
+	"tradeservice/internal/server/request"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:generate mockgen -source=webhooks.go -destination=mockWebhooks/webhooksrepository.go
+
+type WebhookManager interface {
+	AddWebhook(ctx context.Context, webhook models.WebhookSecret) (models.WebhookSecret, error)
+	GetWebhooks(ctx context.Context) ([]models.WebhookDto, error)
+	GetWebhookByID(ctx context.Context, id string) (models.WebhookDto, error)
+	SetWebhook(ctx context.Context, id string, webhook models.WebhookDto) (models.WebhookDto, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	GetWebhookDeliveries(ctx context.Context, webhookID string,
+		params models.ListParams) (models.Page[models.WebhookDeliveryDto], error)
+	GetWebhookDelivery(ctx context.Context, webhookID string, id string) (models.WebhookDeliveryDto, error)
+	RedeliverWebhookDelivery(ctx context.Context, webhookID string, id string) (models.WebhookDeliveryDto, error)
+}
+
+type WebhookController struct {
+	manager WebhookManager
+	policy  policy.Authorizer
+}
+
+func NewWebhookHandler(manager WebhookManager, authorizer policy.Authorizer) *WebhookController {
+	return &WebhookController{manager, authorizer}
+}
+
+func (ctr WebhookController) GetWebhooks(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Webhooks")
+
+	if err := ctr.policy.Authorize(echo, policy.WebhooksManage); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.GetWebhooks(echo.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+// CreateWebhook responds with the signing secret; it can't be retrieved again afterwards.
+func (ctr WebhookController) CreateWebhook(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Post Request for Webhooks")
+
+	if err := ctr.policy.Authorize(echo, policy.WebhooksManage); err != nil {
+		return err
+	}
+
+	var webhook models.WebhookSecret
+	if err := request.Bind(echo, &webhook); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.AddWebhook(echo.Request().Context(), models.WebhookSecret{
+		WebhookDto: models.WebhookDto{
+			URL:        webhook.URL,
+			EventTypes: webhook.EventTypes,
+		},
+		Secret: webhook.Secret,
+	})
+	if err != nil {
+		return err
+	}
+
+	echo.Response().Header().Set("Cache-Control", "no-store")
+
+	return echo.JSON(http.StatusCreated, res)
+}
+
+func (ctr WebhookController) GetWebhookByID(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Webhook")
+
+	if err := ctr.policy.Authorize(echo, policy.WebhooksManage); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.GetWebhookByID(echo.Request().Context(), echo.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr WebhookController) UpdateWebhook(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Put Request for Webhook")
+
+	if err := ctr.policy.Authorize(echo, policy.WebhooksManage); err != nil {
+		return err
+	}
+
+	var webhook models.WebhookDto
+	if err := request.Bind(echo, &webhook); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.SetWebhook(echo.Request().Context(), echo.Param("id"), models.WebhookDto{
+		URL:        webhook.URL,
+		EventTypes: webhook.EventTypes,
+		Active:     webhook.Active,
+	})
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr WebhookController) DeleteWebhook(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Delete Request for Webhook")
+
+	if err := ctr.policy.Authorize(echo, policy.WebhooksManage); err != nil {
+		return err
+	}
+
+	if err := ctr.manager.DeleteWebhook(echo.Request().Context(), echo.Param("id")); err != nil {
+		return err
+	}
+
+	return echo.NoContent(http.StatusNoContent)
+}
+
+func (ctr WebhookController) GetWebhookDeliveries(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Webhook Deliveries")
+
+	if err := ctr.policy.Authorize(echo, policy.WebhooksManage); err != nil {
+		return err
+	}
+
+	params, err := request.ListParams(echo)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.GetWebhookDeliveries(echo.Request().Context(), echo.Param("id"), params)
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+func (ctr WebhookController) GetWebhookDelivery(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Get Request for Webhook Delivery")
+
+	if err := ctr.policy.Authorize(echo, policy.WebhooksManage); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.GetWebhookDelivery(echo.Request().Context(), echo.Param("id"), echo.Param("deliveryId"))
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusOK, res)
+}
+
+// RedeliverWebhookDelivery accepts the delivery for sending again; the deliverer picks it up on its next poll.
+func (ctr WebhookController) RedeliverWebhookDelivery(echo echo.Context) error {
+	logger.FromContext(echo.Request().Context()).Debug("Redeliver Request for Webhook Delivery")
+
+	if err := ctr.policy.Authorize(echo, policy.WebhooksManage); err != nil {
+		return err
+	}
+
+	res, err := ctr.manager.RedeliverWebhookDelivery(echo.Request().Context(), echo.Param("id"),
+		echo.Param("deliveryId"))
+	if err != nil {
+		return err
+	}
+
+	return echo.JSON(http.StatusAccepted, res)
+}
@@ -0,0 +1,116 @@
+package webhooks_test
+
+import (
+	"net/http"
+	"testing"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/handler/webhooks"
+	mockwebhooks "tradeservice/internal/server/handler/webhooks/mockWebhooks"
+	"tradeservice/internal/server/policy"
+	"tradeservice/internal/server/utils"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWebhookController_CreateWebhook_ReturnsSecretOnce(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockwebhooks.NewMockWebhookManager(ctrl)
+	handler := webhooks.NewWebhookHandler(mockManager, policy.AllowAll())
+
+	mockManager.EXPECT().AddWebhook(gomock.Any(), models.WebhookSecret{
+		WebhookDto: models.WebhookDto{URL: "https://partner.example.com", EventTypes: []string{"product.created"}},
+	}).Return(models.WebhookSecret{
+		WebhookDto: models.WebhookDto{ID: "1", URL: "https://partner.example.com", Active: true},
+		Secret:     "whsec_abcdefgh",
+	}, nil)
+
+	rec, req, keys, vals := utils.CreateJSONContext(http.MethodPost, "/admin/webhooks",
+		`{"url":"https://partner.example.com","eventTypes":["product.created"],"id":"7","active":false}`, nil)
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.CreateWebhook(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.Contains(t, rec.Body.String(), `"secret":"whsec_abcdefgh"`)
+}
+
+func TestWebhookController_RedeliverWebhookDelivery(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockwebhooks.NewMockWebhookManager(ctrl)
+	handler := webhooks.NewWebhookHandler(mockManager, policy.AllowAll())
+
+	mockManager.EXPECT().RedeliverWebhookDelivery(gomock.Any(), "1", "5").
+		Return(models.WebhookDeliveryDto{ID: "5", WebhookID: "1", Status: models.DeliveryPending}, nil)
+	mockManager.EXPECT().RedeliverWebhookDelivery(gomock.Any(), "1", "6").Return(models.WebhookDeliveryDto{},
+		models.ErrNotFound)
+
+	for deliveryID, expected := range map[string]error{"5": nil, "6": models.ErrNotFound} {
+		rec, req, keys, vals := utils.CreateContext(http.MethodPost, "/admin/webhooks/:id/deliveries/:deliveryId/redeliver",
+			map[string]string{"id": "1", "deliveryId": deliveryID})
+
+		e := echo.New()
+		echoCtx := e.NewContext(req, rec)
+		echoCtx.SetParamNames(keys...)
+		echoCtx.SetParamValues(vals...)
+
+		err := handler.RedeliverWebhookDelivery(echoCtx)
+		if expected != nil {
+			require.ErrorIs(t, err, expected)
+
+			continue
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+	}
+}
+
+func TestWebhookController_GetWebhookDeliveries_PassesListParams(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockManager := mockwebhooks.NewMockWebhookManager(ctrl)
+	handler := webhooks.NewWebhookHandler(mockManager, policy.AllowAll())
+
+	mockManager.EXPECT().GetWebhookDeliveries(gomock.Any(), "1", models.ListParams{Limit: 20, Cursor: "abc"}).
+		Return(models.Page[models.WebhookDeliveryDto]{
+			Items:      []models.WebhookDeliveryDto{{ID: "5", WebhookID: "1", Status: models.DeliveryDelivered}},
+			NextCursor: "def",
+		}, nil)
+
+	rec, req, keys, vals := utils.CreateContext(http.MethodGet, "/admin/webhooks/1/deliveries?limit=20&cursor=abc",
+		map[string]string{"id": "1"})
+
+	e := echo.New()
+	echoCtx := e.NewContext(req, rec)
+	echoCtx.SetParamNames(keys...)
+	echoCtx.SetParamValues(vals...)
+
+	err := handler.GetWebhookDeliveries(echoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"next_cursor":"def"`)
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"tradeservice/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (models.APIKeyDto, error)
+}
+
+// APIKeyAuth authenticates requests carrying an X-API-Key header and attaches the key's scopes to the
+// principal. Requests without the header are left to the JWT middleware.
+func APIKeyAuth(authenticator APIKeyAuthenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(echo echo.Context) error {
+			key := echo.Request().Header.Get(APIKeyHeader)
+			if key == "" {
+				return next(echo)
+			}
+
+			res, err := authenticator.Authenticate(echo.Request().Context(), key)
+			if err != nil {
+				return err
+			}
+
+			SetPrincipal(echo, Principal{Subject: "apikey:" + res.ID, Scopes: res.Scopes})
+
+			return next(echo)
+		}
+	}
+}
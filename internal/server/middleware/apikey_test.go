@@ -0,0 +1,48 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"tradeservice/internal/config"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/middleware"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticKeys map[string]models.APIKeyDto
+
+func (s staticKeys) Authenticate(_ context.Context, key string) (models.APIKeyDto, error) {
+	res, ok := s[key]
+	if !ok {
+		return models.APIKeyDto{}, models.ErrUnauthorized
+	}
+
+	return res, nil
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	t.Parallel()
+
+	server := newAuthServer(t, config.AuthConfig{Enabled: true, Algorithm: "HS256", Secret: secret})
+	server.Pre(middleware.APIKeyAuth(staticKeys{
+		"tsk_valid": {ID: "7", Scopes: []string{"products.write"}},
+	}))
+
+	serveKey := func(key string) (int, string) {
+		req, rec := newRequest(http.MethodPost, "/products")
+		req.Header.Set(middleware.APIKeyHeader, key)
+		server.ServeHTTP(rec, req)
+
+		return rec.Code, rec.Body.String()
+	}
+
+	code, body := serveKey("tsk_valid")
+	require.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"Subject":"apikey:7","Roles":null,"Scopes":["products.write"]}`, body)
+
+	code, _ = serveKey("tsk_revoked")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
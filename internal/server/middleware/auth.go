@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"tradeservice/internal/config"
+	"tradeservice/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const principalKey = "principal"
+
+var ErrAuthConfig = errors.New("invalid auth config")
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Roles   []string
+	Scopes  []string
+}
+
+// HasRole reports whether the principal was granted role.
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PrincipalFrom returns the principal stored by the auth middleware, if any.
+func PrincipalFrom(echo echo.Context) (Principal, bool) {
+	principal, ok := echo.Get(principalKey).(Principal)
+
+	return principal, ok
+}
+
+// SetPrincipal stores principal on the request context.
+func SetPrincipal(echo echo.Context, principal Principal) {
+	echo.Set(principalKey, principal)
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles"`
+}
+
+// JWTAuth validates bearer tokens and exposes the subject and roles of the caller via PrincipalFrom.
+// GET requests to routes matching cfg.PublicGET, and requests already authenticated by an API key,
+// pass without a token.
+func JWTAuth(cfg config.AuthConfig) (echo.MiddlewareFunc, error) {
+	if !cfg.Enabled {
+		return func(next echo.HandlerFunc) echo.HandlerFunc { return next }, nil
+	}
+
+	key, err := verificationKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{cfg.Algorithm}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	parser := jwt.NewParser(options...)
+	keyFunc := func(*jwt.Token) (any, error) { return key, nil }
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(echo echo.Context) error {
+			if echo.Request().Method == http.MethodGet && isPublic(cfg.PublicGET, echo.Path()) {
+				return next(echo)
+			}
+
+			if _, ok := PrincipalFrom(echo); ok {
+				return next(echo)
+			}
+
+			raw, ok := bearerToken(echo.Request().Header.Get("Authorization"))
+			if !ok {
+				return unauthorized(echo, "missing bearer token")
+			}
+
+			var tokenClaims claims
+			if _, err := parser.ParseWithClaims(raw, &tokenClaims, keyFunc); err != nil {
+				return unauthorized(echo, "invalid bearer token")
+			}
+
+			if tokenClaims.Subject == "" {
+				return unauthorized(echo, "token has no subject")
+			}
+
+			SetPrincipal(echo, Principal{Subject: tokenClaims.Subject, Roles: tokenClaims.Roles})
+
+			return next(echo)
+		}
+	}, nil
+}
+
+func verificationKey(cfg config.AuthConfig) (any, error) {
+	switch cfg.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("%w: JWT_SECRET is required for HS256", ErrAuthConfig)
+		}
+
+		return []byte(cfg.Secret), nil
+	case jwt.SigningMethodRS256.Alg():
+		if cfg.PublicKeyFile == "" {
+			return nil, fmt.Errorf("%w: JWT_PUBLIC_KEY_FILE is required for RS256", ErrAuthConfig)
+		}
+
+		pem, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read public key %w", err)
+		}
+
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrAuthConfig, err)
+		}
+
+		return key, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrAuthConfig, cfg.Algorithm)
+	}
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
+
+// isPublic matches a route template against the allow-list; a trailing "/*" covers every route below the prefix.
+func isPublic(allowed []string, path string) bool {
+	for _, pattern := range allowed {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(path, prefix+"/") {
+				return true
+			}
+
+			continue
+		}
+
+		if pattern == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+func unauthorized(echo echo.Context, reason string) error {
+	echo.Response().Header().Set("WWW-Authenticate", `Bearer realm="tradeservice"`)
+
+	return fmt.Errorf("%w: %s", models.ErrUnauthorized, reason)
+}
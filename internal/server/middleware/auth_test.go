@@ -0,0 +1,184 @@
+package middleware_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"tradeservice/internal/config"
+	"tradeservice/internal/server/middleware"
+	"tradeservice/internal/server/problem"
+	"tradeservice/internal/server/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "test-secret"
+
+func newAuthServer(t *testing.T, cfg config.AuthConfig) *echo.Echo {
+	t.Helper()
+
+	auth, err := middleware.JWTAuth(cfg)
+	require.NoError(t, err)
+
+	server := echo.New()
+	server.HTTPErrorHandler = problem.ErrorHandler(utils.NewTestLogger())
+	server.Use(auth)
+
+	whoami := func(echo echo.Context) error {
+		principal, ok := middleware.PrincipalFrom(echo)
+		if !ok {
+			return echo.String(http.StatusOK, "anonymous")
+		}
+
+		return echo.JSON(http.StatusOK, principal)
+	}
+
+	server.GET("/products/:id", whoami)
+	server.GET("/products/:id/stock", whoami)
+	server.POST("/products", whoami)
+	server.GET("/orders", whoami)
+
+	return server
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	require.NoError(t, err)
+
+	return token
+}
+
+func newRequest(method, path string) (*http.Request, *httptest.ResponseRecorder) {
+	return httptest.NewRequest(method, path, nil), httptest.NewRecorder()
+}
+
+func serve(server *echo.Echo, method, path, token string) *httptest.ResponseRecorder {
+	req, rec := newRequest(method, path)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	server.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestJWTAuth_HS256(t *testing.T) {
+	t.Parallel()
+
+	server := newAuthServer(t, config.AuthConfig{
+		Enabled:   true,
+		Algorithm: "HS256",
+		Secret:    secret,
+		PublicGET: []string{"/products/*"},
+	})
+
+	valid := sign(t, jwt.SigningMethodHS256, []byte(secret), jwt.MapClaims{
+		"sub":   "alice",
+		"roles": []string{"admin"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+
+	rec := serve(server, http.MethodPost, "/products", valid)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"Subject":"alice","Roles":["admin"],"Scopes":null}`, rec.Body.String())
+
+	rec = serve(server, http.MethodGet, "/products/1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(server, http.MethodGet, "/orders", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+}
+
+func TestJWTAuth_DefaultPublicRoutes(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.New()
+	require.NoError(t, err)
+
+	cfg.Auth.Secret = secret
+	server := newAuthServer(t, cfg.Auth)
+
+	rec := serve(server, http.MethodGet, "/products/1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(server, http.MethodGet, "/products/1/stock", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "stock levels are not public")
+}
+
+func TestJWTAuth_RejectsInvalidTokens(t *testing.T) {
+	t.Parallel()
+
+	server := newAuthServer(t, config.AuthConfig{Enabled: true, Algorithm: "HS256", Secret: secret})
+
+	for name, token := range map[string]string{
+		"garbage": "not-a-jwt",
+		"wrong key": sign(t, jwt.SigningMethodHS256, []byte("other"), jwt.MapClaims{
+			"sub": "alice", "exp": time.Now().Add(time.Hour).Unix(),
+		}),
+		"expired": sign(t, jwt.SigningMethodHS256, []byte(secret), jwt.MapClaims{
+			"sub": "alice", "exp": time.Now().Add(-time.Minute).Unix(),
+		}),
+		"no expiry": sign(t, jwt.SigningMethodHS256, []byte(secret), jwt.MapClaims{"sub": "alice"}),
+		"no subject": sign(t, jwt.SigningMethodHS256, []byte(secret), jwt.MapClaims{
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}),
+	} {
+		rec := serve(server, http.MethodPost, "/products", token)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, name)
+	}
+}
+
+func TestJWTAuth_RS256(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "jwt.pub")
+	require.NoError(t, os.WriteFile(keyFile,
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	server := newAuthServer(t, config.AuthConfig{Enabled: true, Algorithm: "RS256", PublicKeyFile: keyFile})
+
+	claims := jwt.MapClaims{"sub": "bob", "exp": time.Now().Add(time.Hour).Unix()}
+
+	rec := serve(server, http.MethodGet, "/orders", sign(t, jwt.SigningMethodRS256, key, claims))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// An HS256 token signed with the public key must not pass as RS256.
+	rec = serve(server, http.MethodGet, "/orders", sign(t, jwt.SigningMethodHS256, der, claims))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestJWTAuth_Config(t *testing.T) {
+	t.Parallel()
+
+	_, err := middleware.JWTAuth(config.AuthConfig{Enabled: true, Algorithm: "HS256"})
+	require.ErrorIs(t, err, middleware.ErrAuthConfig)
+
+	_, err = middleware.JWTAuth(config.AuthConfig{Enabled: true, Algorithm: "none"})
+	require.ErrorIs(t, err, middleware.ErrAuthConfig)
+
+	server := newAuthServer(t, config.AuthConfig{Enabled: false})
+
+	rec := serve(server, http.MethodPost, "/products", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Deprecated flags responses of legacy routes so clients can migrate to successor before the routes are removed.
+func Deprecated(successor string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(echo echo.Context) error {
+			echo.Response().Header().Set("Deprecation", "true")
+			echo.Response().Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+
+			return next(echo)
+		}
+	}
+}
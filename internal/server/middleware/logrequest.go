@@ -1,13 +1,14 @@
 package middleware
 
 import (
-	"log/slog"
 	"time"
+	"tradeservice/internal/logger"
 
 	"github.com/labstack/echo/v4"
 )
 
-func LogRequest(logger *slog.Logger) echo.MiddlewareFunc {
+// LogRequest logs every request through the request-scoped logger, so it must run after RequestID.
+func LogRequest() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(echo echo.Context) error {
 			start := time.Now()
@@ -16,7 +17,7 @@ func LogRequest(logger *slog.Logger) echo.MiddlewareFunc {
 
 			stop := time.Now()
 
-			logger.Info("Request: ",
+			logger.FromContext(echo.Request().Context()).Info("Request: ",
 				"Method", echo.Request().Method,
 				"URL", echo.Request().URL,
 				"Time", stop.Sub(start),
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"time"
+	"tradeservice/internal/metrics"
+
+	"github.com/labstack/echo/v4"
+)
+
+const unmatchedRoute = "unmatched"
+
+// Metrics records every request by route template and status. Errors are rendered here, through the
+// server's error handler, so the status is known before it is recorded; the handler skips committed responses.
+func Metrics(m *metrics.Metrics) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(echo echo.Context) error {
+			start := time.Now()
+
+			err := next(echo)
+			if err != nil {
+				echo.Error(err)
+			}
+
+			route := echo.Path()
+			if route == "" {
+				route = unmatchedRoute
+			}
+
+			m.ObserveRequest(echo.Request().Method, route, echo.Response().Status, time.Since(start))
+
+			return err
+		}
+	}
+}
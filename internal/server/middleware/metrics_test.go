@@ -0,0 +1,50 @@
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tradeservice/internal/metrics"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/middleware"
+	"tradeservice/internal/server/problem"
+	"tradeservice/internal/server/utils"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_LabelsByRouteTemplate(t *testing.T) {
+	t.Parallel()
+
+	m := metrics.New()
+
+	server := echo.New()
+	server.HTTPErrorHandler = problem.ErrorHandler(utils.NewTestLogger())
+	server.Use(middleware.Metrics(m))
+	server.GET("/products/:id", func(echo echo.Context) error {
+		if echo.Param("id") == "404" {
+			return fmt.Errorf("failed to get product %w", models.ErrNotFound)
+		}
+
+		return echo.NoContent(http.StatusOK)
+	})
+	server.GET("/metrics", echo.WrapHandler(m.Handler()))
+
+	for _, path := range []string{"/products/1", "/products/2", "/products/404", "/nowhere/1"} {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+
+	assert.Contains(t, body, `tradeservice_http_requests_total{method="GET",route="/products/:id",status="200"} 2`)
+	assert.Contains(t, body, `tradeservice_http_requests_total{method="GET",route="/products/:id",status="404"} 1`)
+	assert.NotContains(t, body, `route="/nowhere/1"`)
+	assert.Contains(t, body, `tradeservice_http_request_duration_seconds_bucket{method="GET",route="/products/:id",status="200"`)
+	assert.Contains(t, body, "tradeservice_products_created_total 0")
+}
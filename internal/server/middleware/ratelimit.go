@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"tradeservice/internal/config"
+	"tradeservice/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+const sweepInterval = time.Minute
+
+var ErrRateLimitConfig = errors.New("invalid rate limit config")
+
+// unlimitedRoutes serve infrastructure that polls on its own schedule.
+var unlimitedRoutes = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// budget is one class of token buckets, e.g. all write buckets, with a bucket per client.
+type budget struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func newBudget(rate float64, burst int) *budget {
+	return &budget{rate: rate, burst: float64(burst), buckets: make(map[string]*bucket)}
+}
+
+// take spends a token of client's bucket. It returns the tokens left, or when refused, the wait for the next one.
+func (b *budget) take(client string, now time.Time) (bool, float64, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sweep(now)
+
+	bkt, ok := b.buckets[client]
+	if !ok {
+		bkt = &bucket{tokens: b.burst, updated: now}
+		b.buckets[client] = bkt
+	}
+
+	bkt.tokens = math.Min(b.burst, bkt.tokens+now.Sub(bkt.updated).Seconds()*b.rate)
+	bkt.updated = now
+
+	if bkt.tokens < 1 {
+		return false, bkt.tokens, b.after(1 - bkt.tokens)
+	}
+
+	bkt.tokens--
+
+	return true, bkt.tokens, 0
+}
+
+// sweep forgets buckets that have refilled; a new bucket starts full, so this loses nothing.
+func (b *budget) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < sweepInterval {
+		return
+	}
+
+	b.lastSweep = now
+
+	for client, bkt := range b.buckets {
+		if bkt.tokens+now.Sub(bkt.updated).Seconds()*b.rate >= b.burst {
+			delete(b.buckets, client)
+		}
+	}
+}
+
+func (b *budget) after(tokens float64) time.Duration {
+	return time.Duration(tokens / b.rate * float64(time.Second))
+}
+
+// IPRateLimit applies one token bucket per client IP to every request, probes included. It runs before
+// APIKeyAuth and JWTAuth, so a flood of requests, with or without credentials, is refused before its keys are
+// looked up; RateLimit still budgets each principal after authentication.
+func IPRateLimit(cfg config.RateLimitConfig) (echo.MiddlewareFunc, error) {
+	if !cfg.Enabled {
+		return func(next echo.HandlerFunc) echo.HandlerFunc { return next }, nil
+	}
+
+	if cfg.IPRate <= 0 || cfg.IPBurst < 1 {
+		return nil, fmt.Errorf("%w: rates must be positive and bursts at least 1", ErrRateLimitConfig)
+	}
+
+	limits := newBudget(cfg.IPRate, cfg.IPBurst)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(echo echo.Context) error {
+			if err := spend(echo, limits, "ip:"+echo.RealIP()); err != nil {
+				return err
+			}
+
+			return next(echo)
+		}
+	}, nil
+}
+
+// RateLimit applies token buckets per client with separate read and write budgets. The client is the
+// authenticated principal, so it must run after APIKeyAuth and JWTAuth, or the IP for anonymous callers.
+// Every limited response carries RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset; refusals also
+// carry Retry-After.
+func RateLimit(cfg config.RateLimitConfig) (echo.MiddlewareFunc, error) {
+	if !cfg.Enabled {
+		return func(next echo.HandlerFunc) echo.HandlerFunc { return next }, nil
+	}
+
+	if cfg.ReadRate <= 0 || cfg.WriteRate <= 0 || cfg.ReadBurst < 1 || cfg.WriteBurst < 1 {
+		return nil, fmt.Errorf("%w: rates must be positive and bursts at least 1", ErrRateLimitConfig)
+	}
+
+	reads := newBudget(cfg.ReadRate, cfg.ReadBurst)
+	writes := newBudget(cfg.WriteRate, cfg.WriteBurst)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(echo echo.Context) error {
+			if unlimitedRoutes[echo.Path()] {
+				return next(echo)
+			}
+
+			limits := writes
+
+			switch echo.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				limits = reads
+			}
+
+			client := "ip:" + echo.RealIP()
+			if principal, ok := PrincipalFrom(echo); ok {
+				client = "sub:" + principal.Subject
+			}
+
+			if err := spend(echo, limits, client); err != nil {
+				return err
+			}
+
+			return next(echo)
+		}
+	}, nil
+}
+
+// spend takes a token of client's bucket and sets the RateLimit headers; it fails once the bucket is empty.
+func spend(echo echo.Context, limits *budget, client string) error {
+	allowed, remaining, wait := limits.take(client, time.Now())
+
+	header := echo.Response().Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(int(limits.burst)))
+	header.Set("RateLimit-Remaining", strconv.Itoa(int(remaining)))
+	header.Set("RateLimit-Reset", strconv.Itoa(seconds(limits.after(limits.burst-remaining))))
+
+	if !allowed {
+		header.Set("Retry-After", strconv.Itoa(seconds(wait)))
+
+		return fmt.Errorf("%w: retry in %ds", models.ErrRateLimited, seconds(wait))
+	}
+
+	return nil
+}
+
+// seconds rounds up, so clients honouring the headers never retry too early.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,151 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"tradeservice/internal/config"
+	"tradeservice/internal/server/middleware"
+	"tradeservice/internal/server/problem"
+	"tradeservice/internal/server/utils"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The rates are low enough that no bucket refills while a test runs.
+var limits = config.RateLimitConfig{Enabled: true, ReadRate: 0.01, ReadBurst: 3, WriteRate: 0.01, WriteBurst: 1}
+
+func newLimitedServer(t *testing.T, cfg config.RateLimitConfig) *echo.Echo {
+	t.Helper()
+
+	rateLimit, err := middleware.RateLimit(cfg)
+	require.NoError(t, err)
+
+	server := echo.New()
+	server.HTTPErrorHandler = problem.ErrorHandler(utils.NewTestLogger())
+	server.IPExtractor = echo.ExtractIPDirect()
+	server.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(echo echo.Context) error {
+			if subject := echo.Request().Header.Get("X-Test-Subject"); subject != "" {
+				middleware.SetPrincipal(echo, middleware.Principal{Subject: subject})
+			}
+
+			return next(echo)
+		}
+	})
+	server.Use(rateLimit)
+
+	ok := func(echo echo.Context) error { return echo.NoContent(http.StatusOK) }
+
+	server.GET("/products", ok)
+	server.POST("/products", ok)
+	server.GET("/healthz", ok)
+
+	return server
+}
+
+func call(server *echo.Echo, method string, path string, subject string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+
+	if subject != "" {
+		req.Header.Set("X-Test-Subject", subject)
+	}
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestRateLimit_RefusesWhenBudgetIsSpent(t *testing.T) {
+	t.Parallel()
+
+	server := newLimitedServer(t, limits)
+
+	for i := range 3 {
+		rec := call(server, http.MethodGet, "/products", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, strconv.Itoa(2-i), rec.Header().Get("RateLimit-Remaining"))
+	}
+
+	rec := call(server, http.MethodGet, "/products", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+
+	retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.Positive(t, retryAfter)
+}
+
+func TestRateLimit_SeparatesReadsWritesAndClients(t *testing.T) {
+	t.Parallel()
+
+	server := newLimitedServer(t, limits)
+
+	assert.Equal(t, http.StatusOK, call(server, http.MethodPost, "/products", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, call(server, http.MethodPost, "/products", "").Code)
+
+	// Reads have their own budget, and an authenticated caller is limited apart from its IP.
+	assert.Equal(t, http.StatusOK, call(server, http.MethodGet, "/products", "").Code)
+	assert.Equal(t, http.StatusOK, call(server, http.MethodPost, "/products", "alice").Code)
+	assert.Equal(t, http.StatusTooManyRequests, call(server, http.MethodPost, "/products", "alice").Code)
+}
+
+func TestRateLimit_SkipsProbesAndDisabledConfig(t *testing.T) {
+	t.Parallel()
+
+	server := newLimitedServer(t, limits)
+	disabled := newLimitedServer(t, config.RateLimitConfig{})
+
+	for range 5 {
+		assert.Equal(t, http.StatusOK, call(server, http.MethodGet, "/healthz", "").Code)
+		assert.Equal(t, http.StatusOK, call(disabled, http.MethodPost, "/products", "").Code)
+	}
+}
+
+func TestIPRateLimit_RefusesBeforeAuthentication(t *testing.T) {
+	t.Parallel()
+
+	ipRateLimit, err := middleware.IPRateLimit(config.RateLimitConfig{Enabled: true, IPRate: 0.01, IPBurst: 2})
+	require.NoError(t, err)
+
+	authenticated := 0
+
+	server := echo.New()
+	server.HTTPErrorHandler = problem.ErrorHandler(utils.NewTestLogger())
+	server.IPExtractor = echo.ExtractIPDirect()
+	server.Use(ipRateLimit)
+	server.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(echo echo.Context) error {
+			authenticated++
+
+			return next(echo)
+		}
+	})
+	server.GET("/products", func(echo echo.Context) error { return echo.NoContent(http.StatusOK) })
+
+	// Every subject of an IP spends the same bucket.
+	assert.Equal(t, http.StatusOK, call(server, http.MethodGet, "/products", "alice").Code)
+	assert.Equal(t, http.StatusOK, call(server, http.MethodGet, "/products", "bob").Code)
+
+	rec := call(server, http.MethodGet, "/products", "carol")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	assert.Equal(t, 2, authenticated)
+}
+
+func TestRateLimit_RejectsInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	_, err := middleware.RateLimit(config.RateLimitConfig{Enabled: true, ReadRate: 1, ReadBurst: 1, WriteBurst: 1})
+	require.ErrorIs(t, err, middleware.ErrRateLimitConfig)
+
+	_, err = middleware.IPRateLimit(config.RateLimitConfig{Enabled: true, IPBurst: 1})
+	require.ErrorIs(t, err, middleware.ErrRateLimitConfig)
+}
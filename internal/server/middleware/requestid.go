@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"log/slog"
+	"regexp"
+	"tradeservice/internal/logger"
+
+	"github.com/labstack/echo/v4"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern limits accepted client IDs, which end up in headers and logs, to short tokens.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID accepts the caller's X-Request-ID or generates one, echoes it in the response and stores a
+// logger carrying it in the request context for handlers, services and storage.
+func RequestID(base *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(echo echo.Context) error {
+			id := echo.Request().Header.Get(RequestIDHeader)
+			if !requestIDPattern.MatchString(id) {
+				id = rand.Text()
+			}
+
+			echo.Response().Header().Set(RequestIDHeader, id)
+
+			ctx := logger.WithContext(echo.Request().Context(), base.With("request_id", id))
+			echo.SetRequest(echo.Request().WithContext(ctx))
+
+			return next(echo)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tradeservice/internal/logger"
+	"tradeservice/internal/server/middleware"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveWithRequestID(t *testing.T, requestID string) (string, map[string]any) {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	server := echo.New()
+	server.Use(middleware.RequestID(slog.New(slog.NewJSONHandler(&buf, nil))))
+	server.GET("/products", func(echo echo.Context) error {
+		logger.FromContext(echo.Request().Context()).Info("listing products")
+
+		return echo.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	if requestID != "" {
+		req.Header.Set(middleware.RequestIDHeader, requestID)
+	}
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+
+	return rec.Header().Get(middleware.RequestIDHeader), line
+}
+
+func TestRequestID_KeepsClientID(t *testing.T) {
+	t.Parallel()
+
+	id, line := serveWithRequestID(t, "req-42")
+
+	assert.Equal(t, "req-42", id)
+	assert.Equal(t, "req-42", line["request_id"])
+}
+
+func TestRequestID_GeneratesMissingOrInvalidID(t *testing.T) {
+	t.Parallel()
+
+	for _, header := range []string{"", "bad id\r\nX-Injected: 1"} {
+		id, line := serveWithRequestID(t, header)
+
+		assert.NotEmpty(t, id)
+		assert.NotEqual(t, header, id)
+		assert.Equal(t, id, line["request_id"])
+	}
+}
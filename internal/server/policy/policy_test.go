@@ -0,0 +1,81 @@
+package policy_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/middleware"
+	"tradeservice/internal/server/policy"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newContext(principal *middleware.Principal) (echo.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	echoCtx := echo.New().NewContext(httptest.NewRequest(http.MethodDelete, "/products/1", nil), rec)
+
+	if principal != nil {
+		middleware.SetPrincipal(echoCtx, *principal)
+	}
+
+	return echoCtx, rec
+}
+
+func TestLoad_ShippedPolicy(t *testing.T) {
+	t.Parallel()
+
+	authorizer, err := policy.Load("../../config/policy.json")
+	require.NoError(t, err)
+
+	editor := &middleware.Principal{Subject: "ed", Roles: []string{"catalog-editor"}}
+	admin := &middleware.Principal{Subject: "ada", Roles: []string{"catalog-admin"}}
+
+	anonymous, _ := newContext(nil)
+	require.NoError(t, authorizer.Authorize(anonymous, policy.ProductsRead))
+	require.ErrorIs(t, authorizer.Authorize(anonymous, policy.ProductsWrite), models.ErrForbidden)
+
+	asEditor, _ := newContext(editor)
+	require.NoError(t, authorizer.Authorize(asEditor, policy.ProductsWrite))
+	require.ErrorIs(t, authorizer.Authorize(asEditor, policy.ProductsDelete), models.ErrForbidden)
+	require.ErrorIs(t, authorizer.Authorize(asEditor, policy.CategoriesDelete), models.ErrForbidden)
+
+	asAdmin, _ := newContext(admin)
+	require.NoError(t, authorizer.Authorize(asAdmin, policy.ProductsDelete))
+	require.NoError(t, authorizer.Authorize(asAdmin, policy.CategoriesDelete))
+}
+
+func TestLoad_RejectsIncompletePolicy(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": {"products.read": ["*"]}}`), 0o600))
+
+	_, err := policy.Load(path)
+	require.ErrorIs(t, err, policy.ErrPolicy)
+}
+
+func TestPolicy_Reason(t *testing.T) {
+	t.Parallel()
+
+	rules := make(map[string][]string, len(policy.Actions))
+	for _, action := range policy.Actions {
+		rules[action] = []string{"catalog-admin"}
+	}
+
+	authorizer, err := policy.New(rules)
+	require.NoError(t, err)
+
+	echoCtx, _ := newContext(&middleware.Principal{Subject: "ed", Roles: []string{"catalog-editor"}})
+
+	err = authorizer.Authorize(echoCtx, policy.ProductsDelete)
+	require.ErrorIs(t, err, models.ErrForbidden)
+	assert.Equal(t, "forbidden: products.delete requires one of the roles catalog-admin", err.Error())
+
+	scoped, _ := newContext(&middleware.Principal{Subject: "apikey:1", Scopes: []string{policy.ProductsDelete}})
+	require.NoError(t, authorizer.Authorize(scoped, policy.ProductsDelete))
+}
@@ -0,0 +1,126 @@
+package problem
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"tradeservice/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	ContentType     = "application/problem+json"
+	requestIDHeader = "X-Request-ID"
+	internalDetail  = "the server failed to process the request"
+	databaseDetail  = "the database is unavailable, try again later"
+)
+
+// Problem is an RFC 7807 problem details body.
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Instance  string              `json:"instance,omitempty"`
+	RequestID string              `json:"requestId,omitempty"`
+	Errors    []models.FieldError `json:"errors,omitempty"`
+}
+
+type kind struct {
+	err    error
+	status int
+	slug   string
+	title  string
+	// detail replaces the error message, which may carry internal details, when set.
+	detail string
+}
+
+// kinds is checked in order; the first sentinel the error wraps decides the response.
+var kinds = []kind{
+	{models.ErrValidation, http.StatusBadRequest, "validation", "Invalid request", ""},
+	{models.ErrUnauthorized, http.StatusUnauthorized, "unauthorized", "Authentication required", ""},
+	{models.ErrForbidden, http.StatusForbidden, "forbidden", "Forbidden", ""},
+	{models.ErrNotFound, http.StatusNotFound, "not-found", "Resource not found", ""},
+	{models.ErrUnique, http.StatusConflict, "already-exists", "Resource already exists", ""},
+	{models.ErrConflict, http.StatusConflict, "conflict", "Conflict with the current state", ""},
+	{models.ErrRateLimited, http.StatusTooManyRequests, "rate-limited", "Too many requests", ""},
+	{models.ErrDB, http.StatusServiceUnavailable, "database", "Database unavailable", databaseDetail},
+}
+
+// From converts err into a problem. Details of unexpected errors are not exposed to the client.
+func From(err error) Problem {
+	for _, k := range kinds {
+		if errors.Is(err, k.err) {
+			res := Problem{Type: "/problems/" + k.slug, Title: k.title, Status: k.status, Detail: err.Error()}
+			if k.detail != "" {
+				res.Detail = k.detail
+			}
+
+			var validation *models.ValidationError
+			if errors.As(err, &validation) {
+				res.Errors = validation.Fields
+			}
+
+			return res
+		}
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		res := Problem{Type: "about:blank", Title: http.StatusText(httpErr.Code), Status: httpErr.Code}
+
+		if msg, ok := httpErr.Message.(string); ok && msg != res.Title {
+			res.Detail = msg
+		}
+
+		return res
+	}
+
+	return Problem{
+		Type:   "/problems/internal",
+		Title:  "Internal server error",
+		Status: http.StatusInternalServerError,
+		Detail: internalDetail,
+	}
+}
+
+// ErrorHandler is the echo.HTTPErrorHandler writing every error as application/problem+json.
+func ErrorHandler(logger *slog.Logger) echo.HTTPErrorHandler {
+	return func(err error, echo echo.Context) {
+		if echo.Response().Committed {
+			return
+		}
+
+		res := From(err)
+		res.Instance = echo.Request().URL.Path
+
+		res.RequestID = echo.Response().Header().Get(requestIDHeader)
+		if res.RequestID == "" {
+			res.RequestID = echo.Request().Header.Get(requestIDHeader)
+		}
+
+		log := logger
+		if res.RequestID != "" {
+			log = logger.With("request_id", res.RequestID)
+		}
+
+		if res.Status >= http.StatusInternalServerError {
+			log.Error("Request failed",
+				"Method", echo.Request().Method,
+				"URL", echo.Request().URL,
+				slog.Any("error_details", err))
+		}
+
+		if echo.Request().Method == http.MethodHead {
+			err = echo.NoContent(res.Status)
+		} else {
+			echo.Response().Header().Set("Content-Type", ContentType)
+			err = echo.JSON(res.Status, res)
+		}
+
+		if err != nil {
+			log.Error("Couldn't write error response", slog.Any("error_details", err))
+		}
+	}
+}
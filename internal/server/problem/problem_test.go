@@ -0,0 +1,109 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/problem"
+	"tradeservice/internal/server/request"
+	"tradeservice/internal/server/utils"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, handler echo.HandlerFunc, req *http.Request) (int, problem.Problem, http.Header) {
+	t.Helper()
+
+	server := echo.New()
+	server.HTTPErrorHandler = problem.ErrorHandler(utils.NewTestLogger())
+	server.POST("/things", handler)
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	var res problem.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+
+	return rec.Code, res, rec.Header()
+}
+
+func TestErrorHandler_MapsSentinels(t *testing.T) {
+	t.Parallel()
+
+	for err, status := range map[error]int{
+		fmt.Errorf("%w: name is required", models.ErrValidation):       http.StatusBadRequest,
+		fmt.Errorf("%w: missing bearer token", models.ErrUnauthorized): http.StatusUnauthorized,
+		fmt.Errorf("%w: no role", models.ErrForbidden):                 http.StatusForbidden,
+		fmt.Errorf("failed to get category %w", models.ErrNotFound):    http.StatusNotFound,
+		fmt.Errorf("failed to add product %w", models.ErrUnique):       http.StatusConflict,
+		fmt.Errorf("%w: insufficient stock", models.ErrConflict):       http.StatusConflict,
+		fmt.Errorf("%w: retry in 2s", models.ErrRateLimited):           http.StatusTooManyRequests,
+		fmt.Errorf("failed to get product %w", models.ErrDB):           http.StatusServiceUnavailable,
+		echo.ErrMethodNotAllowed:                                       http.StatusMethodNotAllowed,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/things", nil)
+		req.Header.Set("X-Request-ID", "req-1")
+
+		code, res, header := serve(t, func(echo.Context) error { return err }, req)
+
+		assert.Equal(t, status, code, err.Error())
+		assert.Equal(t, status, res.Status)
+		assert.Equal(t, problem.ContentType, header.Get("Content-Type"))
+		assert.Equal(t, "/things", res.Instance)
+		assert.Equal(t, "req-1", res.RequestID)
+		assert.NotEmpty(t, res.Type)
+		assert.NotEmpty(t, res.Title)
+	}
+}
+
+func TestErrorHandler_HidesInternalDetails(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/things", nil)
+
+	code, res, _ := serve(t, func(echo.Context) error {
+		return fmt.Errorf("failed to query DB %w", errors.New("password authentication failed for user admin"))
+	}, req)
+
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Equal(t, "/problems/internal", res.Type)
+	assert.NotContains(t, res.Detail, "password")
+}
+
+func TestErrorHandler_HidesDatabaseDetails(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/things", nil)
+
+	code, res, _ := serve(t, func(echo.Context) error {
+		return fmt.Errorf("failed to query DB %w: %w", models.ErrDB, errors.New("connection to 10.0.0.5 refused"))
+	}, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "/problems/database", res.Type)
+	assert.NotContains(t, res.Detail, "10.0.0.5")
+}
+
+func TestErrorHandler_FieldErrors(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(`{"unitPrice":"free"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	code, res, _ := serve(t, func(echo echo.Context) error {
+		var product models.ProductDto
+
+		return request.Bind(echo, &product)
+	}, req)
+
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "/problems/validation", res.Type)
+	assert.Equal(t, []models.FieldError{{Field: "unitPrice", Message: "must be of type int64"}}, res.Errors)
+}
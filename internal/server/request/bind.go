@@ -0,0 +1,34 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tradeservice/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Bind decodes the request into target. Malformed bodies are reported as validation errors, naming the
+// offending field when the JSON decoder knows it.
+func Bind(echo echo.Context, target any) error {
+	err := echo.Bind(target)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return &models.ValidationError{Fields: []models.FieldError{{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type),
+		}}}
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Errorf("%w: malformed JSON at offset %d", models.ErrValidation, syntaxErr.Offset)
+	}
+
+	return fmt.Errorf("%w: malformed request", models.ErrValidation)
+}
@@ -0,0 +1,49 @@
+package request
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"tradeservice/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListParams reads the pagination, sorting and filtering query parameters shared by list endpoints.
+func ListParams(echo echo.Context) (models.ListParams, error) {
+	params := models.ListParams{
+		Cursor:     echo.QueryParam("cursor"),
+		Sort:       echo.QueryParam("sort"),
+		NamePrefix: echo.QueryParam("name_prefix"),
+		CategoryID: echo.QueryParam("category_id"),
+	}
+
+	if limit := echo.QueryParam("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return params, fmt.Errorf("%w: limit must be an integer", models.ErrValidation)
+		}
+
+		params.Limit = value
+	}
+
+	if includeSubcategories := echo.QueryParam("include_subcategories"); includeSubcategories != "" {
+		value, err := strconv.ParseBool(includeSubcategories)
+		if err != nil {
+			return params, fmt.Errorf("%w: include_subcategories must be a boolean", models.ErrValidation)
+		}
+
+		params.IncludeSubcategories = value
+	}
+
+	if createdAfter := echo.QueryParam("created_after"); createdAfter != "" {
+		value, err := time.Parse(time.RFC3339, createdAfter)
+		if err != nil {
+			return params, fmt.Errorf("%w: created_after must be an RFC 3339 timestamp", models.ErrValidation)
+		}
+
+		params.CreatedAfter = &value
+	}
+
+	return params, nil
+}
@@ -7,43 +7,142 @@ import (
 	"log/slog"
 	"net/http"
 	"tradeservice/internal/config"
+	"tradeservice/internal/metrics"
+	"tradeservice/internal/server/apidoc"
+	"tradeservice/internal/server/handler/apikeys"
 	"tradeservice/internal/server/handler/categories"
+	"tradeservice/internal/server/handler/health"
+	"tradeservice/internal/server/handler/inventory"
+	metricshandler "tradeservice/internal/server/handler/metrics"
+	"tradeservice/internal/server/handler/orders"
 	"tradeservice/internal/server/handler/products"
+	"tradeservice/internal/server/handler/webhooks"
 	"tradeservice/internal/server/middleware"
-	"tradeservice/internal/storage/postgres"
+	"tradeservice/internal/server/problem"
+	"tradeservice/internal/storage"
+	"tradeservice/internal/tracing"
 
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 )
 
 type Server struct {
 	server  *echo.Echo
 	logger  *slog.Logger
-	storage *postgres.Storage
+	storage storage.Closer
 	port    int
 }
 
 func New(logger *slog.Logger,
 	cfg *config.ServerConfig,
-	db *postgres.Storage,
+	metric *metrics.Metrics,
+	ipRateLimit echo.MiddlewareFunc,
+	apiKeyAuth echo.MiddlewareFunc,
+	auth echo.MiddlewareFunc,
+	rateLimit echo.MiddlewareFunc,
+	db storage.Closer,
 	categoryHandler *categories.CategoriesController,
-	productHandler *products.ProductController) *Server {
+	productHandler *products.ProductController,
+	inventoryHandler *inventory.InventoryController,
+	orderHandler *orders.OrderController,
+	apiKeyHandler *apikeys.APIKeyController,
+	webhookHandler *webhooks.WebhookController,
+	healthHandler *health.HealthController,
+	metricsHandler *metricshandler.MetricsController) *Server {
 	server := echo.New()
+	server.HTTPErrorHandler = problem.ErrorHandler(logger)
 
-	server.Use(middleware.LogRequest(logger))
+	server.IPExtractor = echo.ExtractIPDirect()
+	if cfg.TrustProxy {
+		server.IPExtractor = echo.ExtractIPFromXFFHeader()
+	}
 
-	categoryGroup := server.Group("categories")
+	server.Use(middleware.RequestID(logger))
+	server.Use(middleware.LogRequest())
+	server.Use(otelecho.Middleware(tracing.Name))
+	server.Use(middleware.Metrics(metric))
+	server.Use(ipRateLimit)
+	server.Use(apiKeyAuth)
+	server.Use(auth)
+	server.Use(rateLimit)
 
-	categoryGroup.GET("", categoryHandler.GetCategory)
-	categoryGroup.DELETE("/:categoryId", categoryHandler.DeleteCategory)
-	categoryGroup.POST("/create/:categoryName/:productId", categoryHandler.AddCategory)
-	categoryGroup.POST("/update/:categoryId/:categoryName", categoryHandler.SetCategory)
+	server.GET("/healthz", healthHandler.Healthz)
+	server.GET("/readyz", healthHandler.Readyz)
+	server.GET("/metrics", metricsHandler.GetMetrics)
+	server.GET(apidoc.SpecPath, apidoc.GetSpec)
+
+	docs := apidoc.UI()
 
-	productGroup := server.Group("product")
+	server.GET(apidoc.UIPath, docs)
+	server.GET(apidoc.UIPath+"/*", docs)
+
+	categoryGroup := server.Group("/categories")
+
+	categoryGroup.GET("", categoryHandler.GetCategory)
+	categoryGroup.POST("", categoryHandler.CreateCategory)
+	categoryGroup.GET("/:id", categoryHandler.GetCategoryByID)
+	categoryGroup.PUT("/:id", categoryHandler.UpdateCategory)
+	categoryGroup.PATCH("/:id", categoryHandler.UpdateCategory)
+	categoryGroup.DELETE("/:id", categoryHandler.DeleteCategory)
+	categoryGroup.GET("/:id/subtree", categoryHandler.GetCategorySubtree)
+	categoryGroup.GET("/:id/ancestors", categoryHandler.GetCategoryAncestors)
+	categoryGroup.POST("/:id/move", categoryHandler.MoveCategory)
+	categoryGroup.GET("/:id/products", productHandler.GetCategoryProducts)
+	categoryGroup.PUT("/:id/products/:productId", categoryHandler.AssignProduct)
+	categoryGroup.DELETE("/:id/products/:productId", categoryHandler.UnassignProduct)
+
+	productGroup := server.Group("/products")
 
 	productGroup.GET("", productHandler.GetProduct)
-	productGroup.DELETE("/:productId", productHandler.DeleteProduct)
-	productGroup.POST("/create/:productName", productHandler.AddProduct)
-	productGroup.POST("/update/:productName/:productId", productHandler.SetProduct)
+	productGroup.POST("", productHandler.CreateProduct)
+	productGroup.GET("/:id", productHandler.GetProductByID)
+	productGroup.PUT("/:id", productHandler.UpdateProduct)
+	productGroup.PATCH("/:id", productHandler.PatchProduct)
+	productGroup.DELETE("/:id", productHandler.DeleteProduct)
+	productGroup.GET("/:id/categories", categoryHandler.GetProductCategories)
+	productGroup.GET("/:id/stock", inventoryHandler.GetStockLevel)
+	productGroup.GET("/:id/stock/movements", inventoryHandler.GetStockMovements)
+	productGroup.POST("/:id/stock/movements", inventoryHandler.RecordMovement)
+	productGroup.POST("/:id/stock/reserve", inventoryHandler.Reserve)
+	productGroup.POST("/:id/stock/release", inventoryHandler.Release)
+
+	orderGroup := server.Group("/orders")
+
+	orderGroup.GET("", orderHandler.GetOrder)
+	orderGroup.POST("", orderHandler.CreateOrder)
+	orderGroup.GET("/:id", orderHandler.GetOrderByID)
+	orderGroup.POST("/:id/status", orderHandler.SetOrderStatus)
+
+	apiKeyGroup := server.Group("/admin/api-keys")
+
+	apiKeyGroup.GET("", apiKeyHandler.GetAPIKeys)
+	apiKeyGroup.POST("", apiKeyHandler.CreateAPIKey)
+	apiKeyGroup.POST("/:id/rotate", apiKeyHandler.RotateAPIKey)
+	apiKeyGroup.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+
+	webhookGroup := server.Group("/admin/webhooks")
+
+	webhookGroup.GET("", webhookHandler.GetWebhooks)
+	webhookGroup.POST("", webhookHandler.CreateWebhook)
+	webhookGroup.GET("/:id", webhookHandler.GetWebhookByID)
+	webhookGroup.PUT("/:id", webhookHandler.UpdateWebhook)
+	webhookGroup.DELETE("/:id", webhookHandler.DeleteWebhook)
+	webhookGroup.GET("/:id/deliveries", webhookHandler.GetWebhookDeliveries)
+	webhookGroup.GET("/:id/deliveries/:deliveryId", webhookHandler.GetWebhookDelivery)
+	webhookGroup.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverWebhookDelivery)
+
+	// Legacy routes kept for the transition period; they carry user data in the URL.
+	deprecatedCategory := middleware.Deprecated("/categories")
+
+	categoryGroup.POST("/create/:categoryName/:productId", categoryHandler.AddCategory, deprecatedCategory)
+	categoryGroup.POST("/update/:categoryId/:categoryName", categoryHandler.SetCategory, deprecatedCategory)
+
+	legacyProductGroup := server.Group("/product", middleware.Deprecated("/products"))
+
+	legacyProductGroup.GET("", productHandler.ListProducts)
+	legacyProductGroup.DELETE("/:id", productHandler.DeleteProduct)
+	legacyProductGroup.POST("/create/:productName", productHandler.AddProduct)
+	legacyProductGroup.POST("/update/:productName/:productId", productHandler.SetProduct)
 
 	return &Server{
 		logger:  logger,
@@ -52,6 +151,12 @@ func New(logger *slog.Logger,
 		port:    cfg.Port,
 	}
 }
+
+// Routes lists the registered routes, e.g. to check them against the API description.
+func (s Server) Routes() []*echo.Route {
+	return s.server.Routes()
+}
+
 func (s Server) Run() {
 	s.logger.Info("Server is running on: localhost", "Port", s.port)
 
@@ -62,11 +167,9 @@ func (s Server) Run() {
 	}
 }
 
+// Stop stops accepting requests and waits for the in-flight ones; the storage stays open for them and
+// for the background workers until Close.
 func (s Server) Stop(ctx context.Context) error {
-	s.logger.Info("Stopping DB Connection")
-
-	s.storage.Close()
-
 	s.logger.Info("Stopping server...")
 	err := s.server.Shutdown(ctx)
 
@@ -78,3 +181,9 @@ func (s Server) Stop(ctx context.Context) error {
 
 	return nil
 }
+
+func (s Server) Close() {
+	s.logger.Info("Stopping DB Connection")
+
+	s.storage.Close()
+}
@@ -48,3 +48,12 @@ func CreateContext(method, path string, params map[string]string) (*httptest.Res
 
 	return rec, req, keys, vals
 }
+
+func CreateJSONContext(method, path, body string, params map[string]string) (*httptest.ResponseRecorder, *http.Request, []string, []string) {
+	rec, _, keys, vals := CreateContext(method, path, params)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	return rec, req, keys, vals
+}
@@ -0,0 +1,162 @@
+package apikeys_test
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+	"tradeservice/internal/models"
+	"tradeservice/internal/services/apikeys"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type storedKey struct {
+	key  models.APIKeyDto
+	hash []byte
+}
+
+type fakeAPIKeys struct {
+	keys []storedKey
+}
+
+func (f *fakeAPIKeys) AddAPIKey(_ context.Context, key models.APIKeyDto, hash []byte) (models.APIKeyDto, error) {
+	key.ID = strconv.Itoa(len(f.keys) + 1)
+	f.keys = append(f.keys, storedKey{key: key, hash: hash})
+
+	return key, nil
+}
+
+func (f *fakeAPIKeys) GetAPIKeys(context.Context) ([]models.APIKeyDto, error) {
+	keys := make([]models.APIKeyDto, 0, len(f.keys))
+	for _, stored := range f.keys {
+		keys = append(keys, stored.key)
+	}
+
+	return keys, nil
+}
+
+func (f *fakeAPIKeys) GetAPIKeyByHash(_ context.Context, hash []byte) (models.APIKeyDto, error) {
+	for _, stored := range f.keys {
+		if bytes.Equal(stored.hash, hash) {
+			return stored.key, nil
+		}
+	}
+
+	return models.APIKeyDto{}, models.ErrNotFound
+}
+
+func (f *fakeAPIKeys) find(id string) *storedKey {
+	for i := range f.keys {
+		if f.keys[i].key.ID == id {
+			return &f.keys[i]
+		}
+	}
+
+	return nil
+}
+
+func (f *fakeAPIKeys) RotateAPIKey(_ context.Context, id string, prefix string, hash []byte) (models.APIKeyDto, error) {
+	stored := f.find(id)
+	if stored == nil || stored.key.Revoked != nil {
+		return models.APIKeyDto{}, models.ErrNotFound
+	}
+
+	stored.key.Prefix, stored.hash = prefix, hash
+
+	return stored.key, nil
+}
+
+func (f *fakeAPIKeys) RevokeAPIKey(_ context.Context, id string) error {
+	stored := f.find(id)
+	if stored == nil {
+		return models.ErrNotFound
+	}
+
+	now := time.Now()
+	stored.key.Revoked = &now
+
+	return nil
+}
+
+func (f *fakeAPIKeys) TouchAPIKey(_ context.Context, id string, usedAt time.Time) error {
+	f.find(id).key.LastUsed = &usedAt
+
+	return nil
+}
+
+func TestStorageAPIKeys_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeAPIKeys{}
+	manager := apikeys.New(store, []string{"products.read", "products.write"})
+	ctx := context.Background()
+
+	created, err := manager.AddAPIKey(ctx, models.APIKeyDto{Name: " nightly import ", Scopes: []string{"products.write"}})
+	require.NoError(t, err)
+	assert.Equal(t, "nightly import", created.Name)
+	assert.Equal(t, created.Key[:len(created.Prefix)], created.Prefix)
+	assert.NotContains(t, string(store.keys[0].hash), created.Key)
+
+	key, err := manager.Authenticate(ctx, created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"products.write"}, key.Scopes)
+	assert.NotNil(t, store.keys[0].key.LastUsed)
+
+	rotated, err := manager.RotateAPIKey(ctx, created.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, created.Key, rotated.Key)
+
+	_, err = manager.Authenticate(ctx, created.Key)
+	require.ErrorIs(t, err, models.ErrUnauthorized)
+
+	_, err = manager.Authenticate(ctx, rotated.Key)
+	require.NoError(t, err)
+
+	require.NoError(t, manager.RevokeAPIKey(ctx, created.ID))
+
+	_, err = manager.Authenticate(ctx, rotated.Key)
+	require.ErrorIs(t, err, models.ErrUnauthorized)
+
+	_, err = manager.RotateAPIKey(ctx, created.ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestStorageAPIKeys_Expired(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeAPIKeys{}
+	manager := apikeys.New(store, []string{"products.read"})
+	ctx := context.Background()
+
+	expires := time.Now().Add(time.Hour)
+
+	created, err := manager.AddAPIKey(ctx, models.APIKeyDto{Name: "job", Scopes: []string{"products.read"},
+		Expires: &expires})
+	require.NoError(t, err)
+
+	past := time.Now().Add(-time.Second)
+	store.keys[0].key.Expires = &past
+
+	_, err = manager.Authenticate(ctx, created.Key)
+	require.ErrorIs(t, err, models.ErrUnauthorized)
+}
+
+func TestStorageAPIKeys_AddAPIKey_Validation(t *testing.T) {
+	t.Parallel()
+
+	manager := apikeys.New(&fakeAPIKeys{}, []string{"products.read"})
+	past := time.Now().Add(-time.Hour)
+
+	for name, key := range map[string]models.APIKeyDto{
+		"no name":       {Scopes: []string{"products.read"}},
+		"no scopes":     {Name: "job"},
+		"unknown scope": {Name: "job", Scopes: []string{"apikeys.manage"}},
+		"expired":       {Name: "job", Scopes: []string{"products.read"}, Expires: &past},
+	} {
+		_, err := manager.AddAPIKey(context.Background(), key)
+		require.ErrorIs(t, err, models.ErrValidation, name)
+	}
+}
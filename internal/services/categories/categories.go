@@ -2,56 +2,312 @@ package categories
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"tradeservice/internal/logger"
 	"tradeservice/internal/models"
+	"tradeservice/internal/services/validate"
 	"tradeservice/internal/storage"
+	"tradeservice/internal/tracing"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+const maxNameLength = 200
+
+var nameRules = []validate.Rule[string]{
+	validate.Required(), validate.MaxLength(maxNameLength), validate.SingleLine(),
+}
+
 type StorageCategories struct {
-	storage storage.CategoryRepository
+	storage  storage.CategoryRepository
+	products storage.ProductRepository
+	deleted  prometheus.Counter
 }
 
-func New(storage storage.CategoryRepository) *StorageCategories {
+func New(storage storage.CategoryRepository, products storage.ProductRepository, deleted prometheus.Counter) *StorageCategories {
 	return &StorageCategories{
-		storage: storage,
+		storage:  storage,
+		products: products,
+		deleted:  deleted,
 	}
 }
 
-func (c StorageCategories) AddCategory(ctx context.Context, name string, productID string) (id string, err error) {
-	id, err = c.storage.AddCategory(ctx, name, productID)
+func (c StorageCategories) AddCategory(ctx context.Context, category models.CategoryDto) (_ models.CategoryDto, err error) {
+	ctx, span := tracing.Start(ctx, "categories.AddCategory")
+	defer tracing.End(span, &err)
 
+	category.Name = strings.TrimSpace(category.Name)
+
+	var v validate.Validator
+
+	validate.Check(&v, "name", category.Name, nameRules...)
+	validate.Check(&v, "parentId", category.ParentID, validate.Optional(validate.ID()))
+
+	if err := v.Err(); err != nil {
+		return models.CategoryDto{}, err
+	}
+
+	res, err := c.storage.AddCategory(ctx, category)
 	if err != nil {
-		return id, fmt.Errorf("failed to add category %w", err)
+		return res, fmt.Errorf("failed to add category %w", err)
 	}
 
-	return id, nil
+	logger.FromContext(ctx).Info("Category created", "id", res.ID)
+
+	return res, nil
 }
 
-func (c StorageCategories) SetCategory(ctx context.Context, id string, name string) error {
-	err := c.storage.SetCategory(ctx, id, name)
+// AddCategoryForProduct assigns an existing product to the top-level category called category.Name, creating
+// the category unless it exists, in one storage call. The product is looked up first so that a bad reference
+// is reported with the other violations.
+func (c StorageCategories) AddCategoryForProduct(ctx context.Context, category models.CategoryDto,
+	productID string) (_ models.CategoryDto, err error) {
+	ctx, span := tracing.Start(ctx, "categories.AddCategoryForProduct")
+	defer tracing.End(span, &err)
+
+	category.Name = strings.TrimSpace(category.Name)
+
+	var v validate.Validator
+
+	validate.Check(&v, "name", category.Name, nameRules...)
+	validate.Check(&v, "productId", productID, validate.ID())
+
+	if !v.Has("productId") {
+		if _, err := c.products.GetProductByID(ctx, productID); err != nil {
+			if !errors.Is(err, models.ErrNotFound) {
+				return models.CategoryDto{}, fmt.Errorf("failed to get product %w", err)
+			}
+
+			v.Add("productId", "product does not exist")
+		}
+	}
+
+	if err := v.Err(); err != nil {
+		return models.CategoryDto{}, err
+	}
 
+	res, err := c.storage.AddCategoryForProduct(ctx, category.Name, productID)
 	if err != nil {
-		return fmt.Errorf("failed to set category %w", err)
+		return res, fmt.Errorf("failed to add category for product %w", err)
 	}
 
-	return nil
+	logger.FromContext(ctx).Info("Product assigned to category", "id", res.ID, "product_id", productID)
+
+	return res, nil
 }
 
-func (c StorageCategories) GetCategory(ctx context.Context) ([]models.CategoryDto, error) {
-	category, err := c.storage.GetCategory(ctx)
+func (c StorageCategories) SetCategory(ctx context.Context, id string, name string) (_ models.CategoryDto, err error) {
+	ctx, span := tracing.Start(ctx, "categories.SetCategory")
+	defer tracing.End(span, &err)
+
+	name = strings.TrimSpace(name)
+
+	var v validate.Validator
+
+	validate.Check(&v, "id", id, validate.ID())
+	validate.Check(&v, "name", name, nameRules...)
 
+	if err := v.Err(); err != nil {
+		return models.CategoryDto{}, err
+	}
+
+	res, err := c.storage.SetCategory(ctx, id, name)
 	if err != nil {
-		return []models.CategoryDto{}, fmt.Errorf("failed to get categories %w", err)
+		return res, fmt.Errorf("failed to set category %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Category renamed", "id", id)
+
+	return res, nil
+}
+
+func (c StorageCategories) GetCategory(ctx context.Context, params models.ListParams) (_ models.Page[models.CategoryDto], err error) {
+	ctx, span := tracing.Start(ctx, "categories.GetCategory")
+	defer tracing.End(span, &err)
+
+	params, err = params.Normalize()
+	if err != nil {
+		return models.Page[models.CategoryDto]{}, err
+	}
+
+	category, err := c.storage.GetCategory(ctx, params)
+
+	if err != nil {
+		return models.Page[models.CategoryDto]{}, fmt.Errorf("failed to get categories %w", err)
+	}
+
+	return category, nil
+}
+
+func (c StorageCategories) GetCategoryByID(ctx context.Context, id string) (_ models.CategoryDto, err error) {
+	ctx, span := tracing.Start(ctx, "categories.GetCategoryByID")
+	defer tracing.End(span, &err)
+
+	if err := validate.Value("id", id, validate.ID()); err != nil {
+		return models.CategoryDto{}, err
+	}
+
+	category, err := c.storage.GetCategoryByID(ctx, id)
+	if err != nil {
+		return category, fmt.Errorf("failed to get category %w", err)
 	}
 
 	return category, nil
 }
 
-func (c StorageCategories) DeleteCategory(ctx context.Context, id string) error {
-	err := c.storage.DeleteCategory(ctx, id)
+func (c StorageCategories) GetCategorySubtree(ctx context.Context, id string) (_ models.CategoryTree, err error) {
+	ctx, span := tracing.Start(ctx, "categories.GetCategorySubtree")
+	defer tracing.End(span, &err)
+
+	if err := validate.Value("id", id, validate.ID()); err != nil {
+		return models.CategoryTree{}, err
+	}
+
+	categories, err := c.storage.GetCategorySubtree(ctx, id)
+	if err != nil {
+		return models.CategoryTree{}, fmt.Errorf("failed to get category subtree %w", err)
+	}
+
+	children := make(map[string][]models.CategoryDto, len(categories))
+
+	for _, category := range categories[1:] {
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	return buildTree(categories[0], children), nil
+}
+
+func (c StorageCategories) GetCategoryAncestors(ctx context.Context, id string) (_ []models.CategoryDto, err error) {
+	ctx, span := tracing.Start(ctx, "categories.GetCategoryAncestors")
+	defer tracing.End(span, &err)
+
+	if err := validate.Value("id", id, validate.ID()); err != nil {
+		return nil, err
+	}
+
+	ancestors, err := c.storage.GetCategoryAncestors(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category ancestors %w", err)
+	}
+
+	return ancestors, nil
+}
+
+// MoveCategory reparents a category; a nil parentID turns it into a root category.
+func (c StorageCategories) MoveCategory(ctx context.Context, id string, parentID *string) (_ models.CategoryDto, err error) {
+	ctx, span := tracing.Start(ctx, "categories.MoveCategory")
+	defer tracing.End(span, &err)
+
+	var v validate.Validator
+
+	validate.Check(&v, "id", id, validate.ID())
+	validate.Check(&v, "parentId", parentID, validate.Optional(validate.ID()))
+
+	if err := v.Err(); err != nil {
+		return models.CategoryDto{}, err
+	}
+
+	if parentID != nil && *parentID == id {
+		return models.CategoryDto{}, fmt.Errorf("%w: category cannot be its own parent", models.ErrConflict)
+	}
+
+	res, err := c.storage.MoveCategory(ctx, id, parentID)
+	if err != nil {
+		return res, fmt.Errorf("failed to move category %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Category moved", "id", id)
+
+	return res, nil
+}
+
+func (c StorageCategories) AssignProduct(ctx context.Context, categoryID string, productID string) (err error) {
+	ctx, span := tracing.Start(ctx, "categories.AssignProduct")
+	defer tracing.End(span, &err)
+
+	if err := checkAssignment(categoryID, productID); err != nil {
+		return err
+	}
+
+	if err := c.storage.AssignProduct(ctx, categoryID, productID); err != nil {
+		return fmt.Errorf("failed to assign product %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Product assigned to category", "category_id", categoryID, "product_id", productID)
+
+	return nil
+}
+
+func (c StorageCategories) UnassignProduct(ctx context.Context, categoryID string, productID string) (err error) {
+	ctx, span := tracing.Start(ctx, "categories.UnassignProduct")
+	defer tracing.End(span, &err)
+
+	if err := checkAssignment(categoryID, productID); err != nil {
+		return err
+	}
+
+	if err := c.storage.UnassignProduct(ctx, categoryID, productID); err != nil {
+		return fmt.Errorf("failed to unassign product %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Product removed from category", "category_id", categoryID, "product_id", productID)
+
+	return nil
+}
+
+func (c StorageCategories) GetProductCategories(ctx context.Context, productID string) (_ []models.CategoryDto, err error) {
+	ctx, span := tracing.Start(ctx, "categories.GetProductCategories")
+	defer tracing.End(span, &err)
+
+	if err := validate.Value("id", productID, validate.ID()); err != nil {
+		return nil, err
+	}
+
+	categories, err := c.storage.GetProductCategories(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product categories %w", err)
+	}
+
+	return categories, nil
+}
+
+func (c StorageCategories) DeleteCategory(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "categories.DeleteCategory")
+	defer tracing.End(span, &err)
+
+	if err := validate.Value("id", id, validate.ID()); err != nil {
+		return err
+	}
+
+	err = c.storage.DeleteCategory(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete category %w", err)
 	}
 
+	c.deleted.Inc()
+
+	logger.FromContext(ctx).Info("Category deleted", "id", id)
+
 	return nil
 }
+
+func checkAssignment(categoryID string, productID string) error {
+	var v validate.Validator
+
+	validate.Check(&v, "id", categoryID, validate.ID())
+	validate.Check(&v, "productId", productID, validate.ID())
+
+	return v.Err()
+}
+
+func buildTree(category models.CategoryDto, children map[string][]models.CategoryDto) models.CategoryTree {
+	tree := models.CategoryTree{CategoryDto: category, Children: []models.CategoryTree{}}
+
+	for _, child := range children[category.ID] {
+		tree.Children = append(tree.Children, buildTree(child, children))
+	}
+
+	return tree
+}
@@ -0,0 +1,88 @@
+package categories_test
+
+import (
+	"context"
+	"testing"
+	"tradeservice/internal/models"
+	"tradeservice/internal/services/categories"
+	"tradeservice/internal/storage/memory"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixture struct {
+	manager  *categories.StorageCategories
+	products *memory.Products
+	store    *memory.Categories
+}
+
+func newFixture() fixture {
+	db := memory.New()
+	f := fixture{products: memory.NewProducts(db), store: memory.NewCategories(db)}
+	f.manager = categories.New(f.store, f.products, prometheus.NewCounter(prometheus.CounterOpts{Name: "deleted"}))
+
+	return f
+}
+
+func (f fixture) addProduct(t *testing.T, sku string) models.ProductDto {
+	t.Helper()
+
+	product, err := f.products.AddProduct(context.Background(), models.ProductDto{
+		Name: sku, SKU: sku, Currency: "EUR", Active: true,
+	})
+	require.NoError(t, err)
+
+	return product
+}
+
+func TestStorageCategories_AddCategoryForProduct_ReusesRootCategory(t *testing.T) {
+	t.Parallel()
+
+	f := newFixture()
+	ctx := context.Background()
+
+	chair := f.addProduct(t, "C-1")
+	stool := f.addProduct(t, "S-1")
+
+	created, err := f.manager.AddCategoryForProduct(ctx, models.CategoryDto{Name: "furniture"}, chair.ID)
+	require.NoError(t, err)
+
+	reused, err := f.manager.AddCategoryForProduct(ctx, models.CategoryDto{Name: " furniture "}, stool.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, reused.ID)
+
+	page, err := f.products.GetProduct(ctx, models.ListParams{
+		Limit: 10, Sort: models.SortByName, CategoryID: created.ID,
+	})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+}
+
+func TestStorageCategories_AddCategoryForProduct_ReportsAllFieldErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := newFixture().manager.AddCategoryForProduct(context.Background(), models.CategoryDto{Name: ""}, "abc")
+
+	var validationErr *models.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Len(t, validationErr.Fields, 2)
+	assert.Equal(t, "name", validationErr.Fields[0].Field)
+	assert.Equal(t, "productId", validationErr.Fields[1].Field)
+}
+
+func TestStorageCategories_AddCategory_ReportsAllFieldErrors(t *testing.T) {
+	t.Parallel()
+
+	parentID := "-1"
+	_, err := newFixture().manager.AddCategory(context.Background(), models.CategoryDto{
+		Name: "line\nbreak", ParentID: &parentID,
+	})
+
+	var validationErr *models.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Len(t, validationErr.Fields, 2)
+	assert.Equal(t, "name", validationErr.Fields[0].Field)
+	assert.Equal(t, "parentId", validationErr.Fields[1].Field)
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+	"tradeservice/internal/models"
+)
+
+const shutdownCheck = "shutdown"
+
+var ErrShuttingDown = errors.New("shutting down")
+
+// Check probes one dependency and returns why it isn't usable, or nil.
+type Check func(ctx context.Context) error
+
+type named struct {
+	name  string
+	check Check
+}
+
+// Probes runs the readiness checks concurrently, each bounded by timeout.
+type Probes struct {
+	checks   []named
+	timeout  time.Duration
+	stopping atomic.Bool
+}
+
+func New(timeout time.Duration) *Probes {
+	return &Probes{timeout: timeout}
+}
+
+// AddCheck registers a readiness check; it is not safe to call once probes are served.
+func (p *Probes) AddCheck(name string, check Check) {
+	p.checks = append(p.checks, named{name, check})
+}
+
+// Shutdown makes every following readiness probe fail so load balancers drain the instance.
+func (p *Probes) Shutdown() {
+	p.stopping.Store(true)
+}
+
+// Live only reports that the process serves requests.
+func (p *Probes) Live(_ context.Context) models.HealthReport {
+	return models.HealthReport{Status: models.HealthOK}
+}
+
+func (p *Probes) Ready(ctx context.Context) models.HealthReport {
+	checks := append([]named{{shutdownCheck, p.checkShutdown}}, p.checks...)
+	results := make([]models.CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i, check := range checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i] = p.run(ctx, check.check)
+		}()
+	}
+
+	wg.Wait()
+
+	report := models.HealthReport{Status: models.HealthOK, Checks: make(map[string]models.CheckResult, len(checks))}
+
+	for i, check := range checks {
+		report.Checks[check.name] = results[i]
+
+		if results[i].Status != models.HealthOK {
+			report.Status = models.HealthFail
+		}
+	}
+
+	return report
+}
+
+func (p *Probes) run(ctx context.Context, check Check) models.CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := models.CheckResult{
+		Status:    models.HealthOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		result.Status = models.HealthFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func (p *Probes) checkShutdown(_ context.Context) error {
+	if p.stopping.Load() {
+		return ErrShuttingDown
+	}
+
+	return nil
+}
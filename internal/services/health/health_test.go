@@ -0,0 +1,62 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"tradeservice/internal/models"
+	"tradeservice/internal/services/health"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbes_Ready(t *testing.T) {
+	t.Parallel()
+
+	probes := health.New(time.Second)
+	probes.AddCheck("database", func(context.Context) error { return nil })
+
+	report := probes.Ready(context.Background())
+	assert.Equal(t, models.HealthOK, report.Status)
+	assert.Equal(t, models.HealthOK, report.Checks["database"].Status)
+	assert.Equal(t, models.HealthOK, report.Checks["shutdown"].Status)
+}
+
+func TestProbes_ReadyFailsOnCheckError(t *testing.T) {
+	t.Parallel()
+
+	probes := health.New(time.Second)
+	probes.AddCheck("database", func(context.Context) error { return errors.New("connection refused") })
+
+	report := probes.Ready(context.Background())
+	assert.Equal(t, models.HealthFail, report.Status)
+	assert.Equal(t, "connection refused", report.Checks["database"].Error)
+}
+
+func TestProbes_ReadyFailsOnTimeout(t *testing.T) {
+	t.Parallel()
+
+	probes := health.New(10 * time.Millisecond)
+	probes.AddCheck("database", func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+
+	report := probes.Ready(context.Background())
+	assert.Equal(t, models.HealthFail, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["database"].Error)
+}
+
+func TestProbes_ShutdownFlipsReadiness(t *testing.T) {
+	t.Parallel()
+
+	probes := health.New(time.Second)
+	probes.Shutdown()
+
+	report := probes.Ready(context.Background())
+	assert.Equal(t, models.HealthFail, report.Status)
+	assert.Equal(t, health.ErrShuttingDown.Error(), report.Checks["shutdown"].Error)
+	assert.Equal(t, models.HealthOK, probes.Live(context.Background()).Status)
+}
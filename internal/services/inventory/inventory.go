@@ -0,0 +1,162 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"tradeservice/internal/logger"
+	"tradeservice/internal/models"
+	"tradeservice/internal/services/validate"
+	"tradeservice/internal/storage"
+	"tradeservice/internal/tracing"
+)
+
+const maxReasonLength = 500
+
+type StorageInventory struct {
+	storage storage.InventoryRepository
+}
+
+func New(storage storage.InventoryRepository) *StorageInventory {
+	return &StorageInventory{
+		storage: storage,
+	}
+}
+
+func (c StorageInventory) GetStockLevel(ctx context.Context, productID string) (_ models.StockLevelDto, err error) {
+	ctx, span := tracing.Start(ctx, "inventory.GetStockLevel")
+	defer tracing.End(span, &err)
+
+	if err := validate.Value("id", productID, validate.ID()); err != nil {
+		return models.StockLevelDto{}, err
+	}
+
+	level, err := c.storage.GetStockLevel(ctx, productID)
+	if err != nil {
+		return level, fmt.Errorf("failed to get stock level %w", err)
+	}
+
+	return level, nil
+}
+
+// RecordMovement converts the client quantity into a signed on-hand change and appends it to the ledger.
+func (c StorageInventory) RecordMovement(ctx context.Context, productID string,
+	movement models.StockMovementDto) (_ models.StockMovementDto, err error) {
+	ctx, span := tracing.Start(ctx, "inventory.RecordMovement")
+	defer tracing.End(span, &err)
+
+	movement.ProductID = productID
+	movement.Reason = strings.TrimSpace(movement.Reason)
+
+	reasonRules := []validate.Rule[string]{validate.MaxLength(maxReasonLength), validate.Text()}
+	quantityRules := []validate.Rule[int64]{validate.Min(1)}
+
+	if movement.Type == models.MovementAdjustment {
+		reasonRules = append([]validate.Rule[string]{validate.Required()}, reasonRules...)
+		quantityRules = []validate.Rule[int64]{validate.NotZero()}
+	}
+
+	var v validate.Validator
+
+	validate.Check(&v, "id", productID, validate.ID())
+	validate.Check(&v, "type", movement.Type, validate.OneOf(models.MovementReceipt, models.MovementSale,
+		models.MovementAdjustment, models.MovementReturn))
+	validate.Check(&v, "quantity", movement.Quantity, quantityRules...)
+	validate.Check(&v, "reason", movement.Reason, reasonRules...)
+
+	if movement.FulfilsReservation && movement.Type != models.MovementSale {
+		v.Add("fulfilsReservation", "is only allowed for sales")
+	}
+
+	if err := v.Err(); err != nil {
+		return models.StockMovementDto{}, err
+	}
+
+	if movement.Type == models.MovementSale {
+		movement.Quantity = -movement.Quantity
+	}
+
+	res, err := c.storage.RecordMovement(ctx, movement)
+	if err != nil {
+		return res, fmt.Errorf("failed to record stock movement %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Stock movement recorded", "product_id", productID, "type", res.Type, "quantity", res.Quantity)
+
+	return res, nil
+}
+
+// GetStockMovements lists the ledger of a product, newest first.
+func (c StorageInventory) GetStockMovements(ctx context.Context, productID string,
+	params models.ListParams) (_ models.Page[models.StockMovementDto], err error) {
+	ctx, span := tracing.Start(ctx, "inventory.GetStockMovements")
+	defer tracing.End(span, &err)
+
+	params = models.ListParams{
+		Limit:        params.Limit,
+		Cursor:       params.Cursor,
+		Sort:         "-" + models.SortByCreatedAt,
+		CreatedAfter: params.CreatedAfter,
+	}
+
+	if err := validate.Value("id", productID, validate.ID()); err != nil {
+		return models.Page[models.StockMovementDto]{}, err
+	}
+
+	params, err = params.Normalize()
+	if err != nil {
+		return models.Page[models.StockMovementDto]{}, err
+	}
+
+	movements, err := c.storage.GetStockMovements(ctx, productID, params)
+	if err != nil {
+		return movements, fmt.Errorf("failed to get stock movements %w", err)
+	}
+
+	return movements, nil
+}
+
+func (c StorageInventory) Reserve(ctx context.Context, productID string, quantity int64) (_ models.StockLevelDto, err error) {
+	ctx, span := tracing.Start(ctx, "inventory.Reserve")
+	defer tracing.End(span, &err)
+
+	if err := checkQuantity(productID, quantity); err != nil {
+		return models.StockLevelDto{}, err
+	}
+
+	level, err := c.storage.Reserve(ctx, productID, quantity)
+	if err != nil {
+		return level, fmt.Errorf("failed to reserve stock %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Stock reserved", "product_id", productID, "quantity", quantity)
+
+	return level, nil
+}
+
+func (c StorageInventory) Release(ctx context.Context, productID string, quantity int64) (_ models.StockLevelDto, err error) {
+	ctx, span := tracing.Start(ctx, "inventory.Release")
+	defer tracing.End(span, &err)
+
+	if err := checkQuantity(productID, quantity); err != nil {
+		return models.StockLevelDto{}, err
+	}
+
+	level, err := c.storage.Release(ctx, productID, quantity)
+	if err != nil {
+		return level, fmt.Errorf("failed to release stock %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Stock released", "product_id", productID, "quantity", quantity)
+
+	return level, nil
+}
+
+func checkQuantity(productID string, quantity int64) error {
+	var v validate.Validator
+
+	validate.Check(&v, "id", productID, validate.ID())
+	validate.Check(&v, "quantity", quantity, validate.Min(1))
+
+	return v.Err()
+}
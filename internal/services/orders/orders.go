@@ -0,0 +1,208 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"tradeservice/internal/logger"
+	"tradeservice/internal/models"
+	"tradeservice/internal/services/validate"
+	"tradeservice/internal/storage"
+	"tradeservice/internal/tracing"
+)
+
+// transitions lists the statuses an order may move to from each status.
+var transitions = map[string][]string{
+	models.OrderDraft:     {models.OrderPlaced, models.OrderCancelled},
+	models.OrderPlaced:    {models.OrderPaid, models.OrderCancelled},
+	models.OrderPaid:      {models.OrderShipped, models.OrderCancelled, models.OrderRefunded},
+	models.OrderShipped:   {models.OrderDelivered, models.OrderRefunded},
+	models.OrderDelivered: {models.OrderRefunded},
+	models.OrderCancelled: {},
+	models.OrderRefunded:  {},
+}
+
+var statuses = []string{
+	models.OrderDraft, models.OrderPlaced, models.OrderPaid, models.OrderShipped,
+	models.OrderDelivered, models.OrderCancelled, models.OrderRefunded,
+}
+
+type StorageOrders struct {
+	storage  storage.OrderRepository
+	products storage.ProductRepository
+}
+
+func New(storage storage.OrderRepository, products storage.ProductRepository) *StorageOrders {
+	return &StorageOrders{
+		storage:  storage,
+		products: products,
+	}
+}
+
+// AddOrder prices the lines from the current product prices and stores the order as a draft.
+func (c StorageOrders) AddOrder(ctx context.Context, lines []models.OrderLineDto) (_ models.OrderDto, err error) {
+	ctx, span := tracing.Start(ctx, "orders.AddOrder")
+	defer tracing.End(span, &err)
+
+	products, err := c.lineProducts(ctx, lines)
+	if err != nil {
+		return models.OrderDto{}, err
+	}
+
+	order := models.OrderDto{Status: models.OrderDraft, Lines: make([]models.OrderLineDto, 0, len(lines))}
+
+	for i, line := range lines {
+		product := products[i]
+
+		if !product.Active {
+			return models.OrderDto{}, fmt.Errorf("%w: product %s is not active", models.ErrConflict, product.ID)
+		}
+
+		order.Currency = product.Currency
+
+		if line.Quantity > math.MaxInt64/max(product.UnitPrice, 1) {
+			return models.OrderDto{}, fmt.Errorf("%w: order total is too large", models.ErrValidation)
+		}
+
+		line.UnitPrice = product.UnitPrice
+		line.LineTotal = product.UnitPrice * line.Quantity
+
+		if order.Total > math.MaxInt64-line.LineTotal {
+			return models.OrderDto{}, fmt.Errorf("%w: order total is too large", models.ErrValidation)
+		}
+
+		order.Total += line.LineTotal
+		order.Lines = append(order.Lines, line)
+	}
+
+	res, err := c.storage.AddOrder(ctx, order)
+	if err != nil {
+		return res, fmt.Errorf("failed to add order %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Order created", "id", res.ID)
+
+	return res, nil
+}
+
+func (c StorageOrders) GetOrder(ctx context.Context, params models.ListParams) (_ models.Page[models.OrderDto], err error) {
+	ctx, span := tracing.Start(ctx, "orders.GetOrder")
+	defer tracing.End(span, &err)
+
+	params, err = params.Normalize()
+	if err != nil {
+		return models.Page[models.OrderDto]{}, err
+	}
+
+	if params.Sort == models.SortByName || params.NamePrefix != "" || params.CategoryID != "" {
+		return models.Page[models.OrderDto]{}, fmt.Errorf("%w: orders support sorting by created_at and updated_at only",
+			models.ErrValidation)
+	}
+
+	orders, err := c.storage.GetOrder(ctx, params)
+	if err != nil {
+		return orders, fmt.Errorf("failed to get orders %w", err)
+	}
+
+	return orders, nil
+}
+
+func (c StorageOrders) GetOrderByID(ctx context.Context, id string) (_ models.OrderDto, err error) {
+	ctx, span := tracing.Start(ctx, "orders.GetOrderByID")
+	defer tracing.End(span, &err)
+
+	if err := validate.Value("id", id, validate.BigID()); err != nil {
+		return models.OrderDto{}, err
+	}
+
+	order, err := c.storage.GetOrderByID(ctx, id)
+	if err != nil {
+		return order, fmt.Errorf("failed to get order %w", err)
+	}
+
+	return order, nil
+}
+
+// lineProducts validates the lines and loads the product each of them references. All lines are checked
+// before failing, so unknown products and bad quantities are reported together.
+func (c StorageOrders) lineProducts(ctx context.Context, lines []models.OrderLineDto) ([]models.ProductDto, error) {
+	var v validate.Validator
+
+	validate.Check(&v, "lines", lines, validate.NotEmpty[models.OrderLineDto]())
+
+	products := make([]models.ProductDto, len(lines))
+	currency := ""
+
+	for i, line := range lines {
+		field := fmt.Sprintf("lines[%d].", i)
+
+		validate.Check(&v, field+"quantity", line.Quantity, validate.Min(1))
+		validate.Check(&v, field+"productId", line.ProductID, validate.ID())
+
+		if v.Has(field + "productId") {
+			continue
+		}
+
+		product, err := c.products.GetProductByID(ctx, line.ProductID)
+		if err != nil {
+			if !errors.Is(err, models.ErrNotFound) {
+				return nil, fmt.Errorf("failed to get product %w", err)
+			}
+
+			v.Add(field+"productId", "product does not exist")
+
+			continue
+		}
+
+		if currency == "" {
+			currency = product.Currency
+		}
+
+		if product.Currency != currency {
+			v.Add(field+"productId", fmt.Sprintf("is priced in %s but the order in %s", product.Currency, currency))
+		}
+
+		products[i] = product
+	}
+
+	return products, v.Err()
+}
+
+// SetOrderStatus moves the order along its lifecycle and rejects transitions the lifecycle does not allow.
+func (c StorageOrders) SetOrderStatus(ctx context.Context, id string, status string) (_ models.OrderDto, err error) {
+	ctx, span := tracing.Start(ctx, "orders.SetOrderStatus")
+	defer tracing.End(span, &err)
+
+	var v validate.Validator
+
+	validate.Check(&v, "id", id, validate.BigID())
+	validate.Check(&v, "status", status, validate.OneOf(statuses...))
+
+	if err := v.Err(); err != nil {
+		return models.OrderDto{}, err
+	}
+
+	order, err := c.storage.GetOrderByID(ctx, id)
+	if err != nil {
+		return order, fmt.Errorf("failed to get order %w", err)
+	}
+
+	if !CanTransition(order.Status, status) {
+		return models.OrderDto{}, fmt.Errorf("%w: order cannot move from %s to %s", models.ErrConflict, order.Status, status)
+	}
+
+	res, err := c.storage.SetOrderStatus(ctx, id, order.Status, status)
+	if err != nil {
+		return res, fmt.Errorf("failed to set order status %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Order status changed", "id", id, "from", order.Status, "to", status)
+
+	return res, nil
+}
+
+func CanTransition(from string, to string) bool {
+	return slices.Contains(transitions[from], to)
+}
@@ -0,0 +1,185 @@
+package orders_test
+
+import (
+	"context"
+	"testing"
+	"tradeservice/internal/models"
+	"tradeservice/internal/services/orders"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProducts struct {
+	products map[string]models.ProductDto
+}
+
+func (f fakeProducts) AddProduct(context.Context, models.ProductDto) (models.ProductDto, error) {
+	return models.ProductDto{}, nil
+}
+
+func (f fakeProducts) GetProduct(context.Context, models.ListParams) (models.Page[models.ProductDto], error) {
+	return models.Page[models.ProductDto]{}, nil
+}
+
+func (f fakeProducts) GetProductByID(_ context.Context, id string) (models.ProductDto, error) {
+	product, ok := f.products[id]
+	if !ok {
+		return models.ProductDto{}, models.ErrNotFound
+	}
+
+	return product, nil
+}
+
+func (f fakeProducts) SetProduct(context.Context, string, models.ProductDto) (models.ProductDto, error) {
+	return models.ProductDto{}, nil
+}
+
+func (f fakeProducts) PatchProduct(context.Context, string, models.ProductPatch) (models.ProductDto, error) {
+	return models.ProductDto{}, nil
+}
+
+func (f fakeProducts) DeleteProduct(context.Context, string) error {
+	return nil
+}
+
+type fakeOrders struct {
+	order models.OrderDto
+}
+
+func (f *fakeOrders) AddOrder(_ context.Context, order models.OrderDto) (models.OrderDto, error) {
+	order.ID = "1"
+	f.order = order
+
+	return order, nil
+}
+
+func (f *fakeOrders) GetOrder(context.Context, models.ListParams) (models.Page[models.OrderDto], error) {
+	return models.Page[models.OrderDto]{Items: []models.OrderDto{f.order}}, nil
+}
+
+func (f *fakeOrders) GetOrderByID(_ context.Context, id string) (models.OrderDto, error) {
+	if id != f.order.ID {
+		return models.OrderDto{}, models.ErrNotFound
+	}
+
+	return f.order, nil
+}
+
+func (f *fakeOrders) SetOrderStatus(_ context.Context, _ string, from string, to string) (models.OrderDto, error) {
+	if f.order.Status != from {
+		return models.OrderDto{}, models.ErrConflict
+	}
+
+	f.order.Status = to
+
+	return f.order, nil
+}
+
+func newManager() (*orders.StorageOrders, *fakeOrders) {
+	store := &fakeOrders{}
+	products := fakeProducts{products: map[string]models.ProductDto{
+		"1": {ID: "1", UnitPrice: 129900, Currency: "EUR", Active: true},
+		"2": {ID: "2", UnitPrice: 2500, Currency: "EUR", Active: true},
+		"3": {ID: "3", UnitPrice: 100, Currency: "USD", Active: true},
+		"4": {ID: "4", UnitPrice: 100, Currency: "EUR", Active: false},
+	}}
+
+	return orders.New(store, products), store
+}
+
+func TestStorageOrders_AddOrder_ComputesTotals(t *testing.T) {
+	t.Parallel()
+
+	manager, _ := newManager()
+
+	order, err := manager.AddOrder(context.Background(), []models.OrderLineDto{
+		{ProductID: "1", Quantity: 1},
+		{ProductID: "2", Quantity: 3},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderDraft, order.Status)
+	assert.Equal(t, "EUR", order.Currency)
+	assert.Equal(t, int64(7500), order.Lines[1].LineTotal)
+	assert.Equal(t, int64(137400), order.Total)
+}
+
+func TestStorageOrders_AddOrder_Rejects(t *testing.T) {
+	t.Parallel()
+
+	manager, _ := newManager()
+
+	for name, tc := range map[string]struct {
+		lines []models.OrderLineDto
+		err   error
+	}{
+		"no lines":        {lines: nil, err: models.ErrValidation},
+		"zero quantity":   {lines: []models.OrderLineDto{{ProductID: "1"}}, err: models.ErrValidation},
+		"unknown product": {lines: []models.OrderLineDto{{ProductID: "9", Quantity: 1}}, err: models.ErrValidation},
+		"mixed currency": {
+			lines: []models.OrderLineDto{{ProductID: "1", Quantity: 1}, {ProductID: "3", Quantity: 1}},
+			err:   models.ErrValidation,
+		},
+		"inactive product": {lines: []models.OrderLineDto{{ProductID: "4", Quantity: 1}}, err: models.ErrConflict},
+	} {
+		_, err := manager.AddOrder(context.Background(), tc.lines)
+		require.ErrorIs(t, err, tc.err, name)
+	}
+}
+
+func TestStorageOrders_SetOrderStatus_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	manager, _ := newManager()
+	ctx := context.Background()
+
+	order, err := manager.AddOrder(ctx, []models.OrderLineDto{{ProductID: "2", Quantity: 1}})
+	require.NoError(t, err)
+
+	_, err = manager.SetOrderStatus(ctx, order.ID, models.OrderShipped)
+	require.ErrorIs(t, err, models.ErrConflict)
+
+	for _, status := range []string{models.OrderPlaced, models.OrderPaid, models.OrderShipped, models.OrderDelivered} {
+		order, err = manager.SetOrderStatus(ctx, order.ID, status)
+		require.NoError(t, err)
+		assert.Equal(t, status, order.Status)
+	}
+
+	_, err = manager.SetOrderStatus(ctx, order.ID, models.OrderCancelled)
+	require.ErrorIs(t, err, models.ErrConflict)
+
+	_, err = manager.SetOrderStatus(ctx, order.ID, "lost")
+	require.ErrorIs(t, err, models.ErrValidation)
+}
+
+func TestCanTransition(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, orders.CanTransition(models.OrderDraft, models.OrderCancelled))
+	assert.True(t, orders.CanTransition(models.OrderDelivered, models.OrderRefunded))
+	assert.False(t, orders.CanTransition(models.OrderCancelled, models.OrderPlaced))
+	assert.False(t, orders.CanTransition(models.OrderRefunded, models.OrderPaid))
+	assert.False(t, orders.CanTransition(models.OrderDraft, models.OrderPaid))
+}
+
+func TestStorageOrders_AddOrder_ReportsAllLines(t *testing.T) {
+	t.Parallel()
+
+	manager, _ := newManager()
+
+	_, err := manager.AddOrder(context.Background(), []models.OrderLineDto{
+		{ProductID: "1", Quantity: 1},
+		{ProductID: "abc", Quantity: 0},
+		{ProductID: "9", Quantity: 1},
+		{ProductID: "3", Quantity: 1},
+	})
+
+	var validation *models.ValidationError
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, []models.FieldError{
+		{Field: "lines[1].quantity", Message: "must be at least 1"},
+		{Field: "lines[1].productId", Message: "must be a positive integer id"},
+		{Field: "lines[2].productId", Message: "product does not exist"},
+		{Field: "lines[3].productId", Message: "is priced in USD but the order in EUR"},
+	}, validation.Fields)
+}
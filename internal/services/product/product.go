@@ -3,55 +3,221 @@ package product
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strings"
+	"tradeservice/internal/logger"
 	"tradeservice/internal/models"
+	"tradeservice/internal/services/validate"
 	"tradeservice/internal/storage"
+	"tradeservice/internal/tracing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	maxNameLength        = 200
+	maxSKULength         = 64
+	maxDescriptionLength = 5000
+)
+
+var (
+	skuPattern      = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// Rules of the product fields, shared by full writes and patches.
+var (
+	nameRules = []validate.Rule[string]{
+		validate.Required(), validate.MaxLength(maxNameLength), validate.SingleLine(),
+	}
+	skuRules = []validate.Rule[string]{
+		validate.Required(), validate.MaxLength(maxSKULength),
+		validate.Pattern(skuPattern, "may contain only letters, digits, '.', '_' and '-'"),
+	}
+	descriptionRules = []validate.Rule[string]{validate.MaxLength(maxDescriptionLength), validate.Text()}
+	priceRules       = []validate.Rule[int64]{validate.Min(0)}
+	currencyRules    = []validate.Rule[string]{validate.Pattern(currencyPattern, "must be an ISO-4217 code")}
 )
 
 type StorageProducts struct {
 	storage storage.ProductRepository
+	created prometheus.Counter
 }
 
-func New(storage storage.ProductRepository) *StorageProducts {
+func New(storage storage.ProductRepository, created prometheus.Counter) *StorageProducts {
 	return &StorageProducts{
 		storage: storage,
+		created: created,
 	}
 }
 
-func (c StorageProducts) AddProduct(ctx context.Context, name string) (id string, err error) {
-	id, err = c.storage.AddProduct(ctx, name)
+func (c StorageProducts) AddProduct(ctx context.Context, product models.ProductDto) (_ models.ProductDto, err error) {
+	ctx, span := tracing.Start(ctx, "product.AddProduct")
+	defer tracing.End(span, &err)
 
+	product, err = normalizeProduct(product)
 	if err != nil {
-		return id, fmt.Errorf("failed to add product %w", err)
+		return models.ProductDto{}, err
 	}
 
-	return id, nil
+	res, err := c.storage.AddProduct(ctx, product)
+	if err != nil {
+		return res, fmt.Errorf("failed to add product %w", err)
+	}
+
+	c.created.Inc()
+
+	logger.FromContext(ctx).Info("Product created", "id", res.ID, "sku", res.SKU)
+
+	return res, nil
 }
 
-func (c StorageProducts) SetProduct(ctx context.Context, id string, name string) error {
-	err := c.storage.SetProduct(ctx, id, name)
+func (c StorageProducts) SetProduct(ctx context.Context, id string, product models.ProductDto) (_ models.ProductDto, err error) {
+	ctx, span := tracing.Start(ctx, "product.SetProduct")
+	defer tracing.End(span, &err)
+
+	if err := validate.Value("id", id, validate.ID()); err != nil {
+		return models.ProductDto{}, err
+	}
 
+	product, err = normalizeProduct(product)
 	if err != nil {
-		return fmt.Errorf("failed to set product %w", err)
+		return models.ProductDto{}, err
 	}
 
-	return nil
+	res, err := c.storage.SetProduct(ctx, id, product)
+	if err != nil {
+		return res, fmt.Errorf("failed to set product %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Product updated", "id", id)
+
+	return res, nil
 }
 
-func (c StorageProducts) GetProduct(ctx context.Context) ([]models.ProductDto, error) {
-	product, err := c.storage.GetProduct(ctx)
+func (c StorageProducts) PatchProduct(ctx context.Context, id string, patch models.ProductPatch) (_ models.ProductDto, err error) {
+	ctx, span := tracing.Start(ctx, "product.PatchProduct")
+	defer tracing.End(span, &err)
+
+	if err := validate.Value("id", id, validate.ID()); err != nil {
+		return models.ProductDto{}, err
+	}
+
+	patch, err = normalizeProductPatch(patch)
+	if err != nil {
+		return models.ProductDto{}, err
+	}
 
+	res, err := c.storage.PatchProduct(ctx, id, patch)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get product %w", err)
+		return res, fmt.Errorf("failed to patch product %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Product patched", "id", id)
+
+	return res, nil
+}
+
+func (c StorageProducts) GetProduct(ctx context.Context, params models.ListParams) (_ models.Page[models.ProductDto], err error) {
+	ctx, span := tracing.Start(ctx, "product.GetProduct")
+	defer tracing.End(span, &err)
+
+	if params.CategoryID != "" {
+		if err := validate.Value("categoryId", params.CategoryID, validate.ID()); err != nil {
+			return models.Page[models.ProductDto]{}, err
+		}
+	}
+
+	params, err = params.Normalize()
+	if err != nil {
+		return models.Page[models.ProductDto]{}, err
+	}
+
+	product, err := c.storage.GetProduct(ctx, params)
+
+	if err != nil {
+		return models.Page[models.ProductDto]{}, fmt.Errorf("failed to get product %w", err)
 	}
 
 	return product, nil
 }
 
-func (c StorageProducts) DeleteProduct(ctx context.Context, id string) error {
-	err := c.storage.DeleteProduct(ctx, id)
+func (c StorageProducts) GetProductByID(ctx context.Context, id string) (_ models.ProductDto, err error) {
+	ctx, span := tracing.Start(ctx, "product.GetProductByID")
+	defer tracing.End(span, &err)
+
+	if err := validate.Value("id", id, validate.ID()); err != nil {
+		return models.ProductDto{}, err
+	}
+
+	product, err := c.storage.GetProductByID(ctx, id)
+	if err != nil {
+		return product, fmt.Errorf("failed to get product %w", err)
+	}
+
+	return product, nil
+}
+
+func (c StorageProducts) DeleteProduct(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "product.DeleteProduct")
+	defer tracing.End(span, &err)
+
+	if err := validate.Value("id", id, validate.ID()); err != nil {
+		return err
+	}
+
+	err = c.storage.DeleteProduct(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete product %w", err)
 	}
 
+	logger.FromContext(ctx).Info("Product deleted", "id", id)
+
 	return nil
 }
+
+// normalizeProduct trims user input and checks it against the product rules.
+func normalizeProduct(product models.ProductDto) (models.ProductDto, error) {
+	product.Name = strings.TrimSpace(product.Name)
+	product.SKU = strings.TrimSpace(product.SKU)
+	product.Description = strings.TrimSpace(product.Description)
+	product.Currency = strings.ToUpper(strings.TrimSpace(product.Currency))
+
+	var v validate.Validator
+
+	validate.Check(&v, "name", product.Name, nameRules...)
+	validate.Check(&v, "sku", product.SKU, skuRules...)
+	validate.Check(&v, "description", product.Description, descriptionRules...)
+	validate.Check(&v, "unitPrice", product.UnitPrice, priceRules...)
+	validate.Check(&v, "currency", product.Currency, currencyRules...)
+
+	return product, v.Err()
+}
+
+// normalizeProductPatch applies the normalizeProduct rules to the fields present in patch.
+func normalizeProductPatch(patch models.ProductPatch) (models.ProductPatch, error) {
+	patch.Name = trimmed(patch.Name, strings.TrimSpace)
+	patch.SKU = trimmed(patch.SKU, strings.TrimSpace)
+	patch.Description = trimmed(patch.Description, strings.TrimSpace)
+	patch.Currency = trimmed(patch.Currency, func(s string) string { return strings.ToUpper(strings.TrimSpace(s)) })
+
+	var v validate.Validator
+
+	validate.Check(&v, "name", patch.Name, validate.Optional(nameRules...))
+	validate.Check(&v, "sku", patch.SKU, validate.Optional(skuRules...))
+	validate.Check(&v, "description", patch.Description, validate.Optional(descriptionRules...))
+	validate.Check(&v, "unitPrice", patch.UnitPrice, validate.Optional(priceRules...))
+	validate.Check(&v, "currency", patch.Currency, validate.Optional(currencyRules...))
+
+	return patch, v.Err()
+}
+
+func trimmed(value *string, trim func(string) string) *string {
+	if value == nil {
+		return nil
+	}
+
+	res := trim(*value)
+
+	return &res
+}
@@ -0,0 +1,69 @@
+package product_test
+
+import (
+	"context"
+	"testing"
+	"tradeservice/internal/models"
+	"tradeservice/internal/services/product"
+	"tradeservice/internal/storage/memory"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newManager() *product.StorageProducts {
+	return product.New(memory.NewProducts(memory.New()), prometheus.NewCounter(prometheus.CounterOpts{Name: "created"}))
+}
+
+func fields(t *testing.T, err error) []string {
+	t.Helper()
+
+	var validationErr *models.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	names := make([]string, 0, len(validationErr.Fields))
+	for _, field := range validationErr.Fields {
+		names = append(names, field.Field)
+	}
+
+	return names
+}
+
+func TestStorageProducts_AddProduct_ReportsAllFieldErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := newManager().AddProduct(context.Background(), models.ProductDto{
+		Name: " ", SKU: "not a sku", UnitPrice: -1, Currency: "euro",
+	})
+
+	require.ErrorIs(t, err, models.ErrValidation)
+	assert.Equal(t, []string{"name", "sku", "unitPrice", "currency"}, fields(t, err))
+}
+
+func TestStorageProducts_PatchProduct_ReportsAllFieldErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	manager := newManager()
+
+	created, err := manager.AddProduct(ctx, models.ProductDto{Name: "chair", SKU: "C-1", Currency: "EUR"})
+	require.NoError(t, err)
+
+	name, sku := "", "C 1"
+	_, err = manager.PatchProduct(ctx, created.ID, models.ProductPatch{Name: &name, SKU: &sku})
+
+	require.ErrorIs(t, err, models.ErrValidation)
+	assert.Equal(t, []string{"name", "sku"}, fields(t, err))
+}
+
+func TestStorageProducts_GetProduct_ChecksCategoryID(t *testing.T) {
+	t.Parallel()
+
+	for _, categoryID := range []string{"abc", "0", "99999999999"} {
+		_, err := newManager().GetProduct(context.Background(), models.ListParams{CategoryID: categoryID})
+
+		require.ErrorIs(t, err, models.ErrValidation, categoryID)
+		assert.Equal(t, []string{"categoryId"}, fields(t, err), categoryID)
+	}
+}
@@ -0,0 +1,261 @@
+// Package validate checks service input against declarative per-field rules and reports every
+// violation of a request at once.
+package validate
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"tradeservice/internal/models"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rule checks one value and returns the violation message, or "" when the value is fine.
+type Rule[T any] func(value T) string
+
+// Validator collects the violations of a single request.
+type Validator struct {
+	fields []models.FieldError
+}
+
+// Check applies rules to value in order and records the first one that fails for field.
+func Check[T any](v *Validator, field string, value T, rules ...Rule[T]) {
+	for _, rule := range rules {
+		if msg := rule(value); msg != "" {
+			v.Add(field, msg)
+
+			return
+		}
+	}
+}
+
+// Add records a violation found outside of a rule, e.g. by a lookup.
+func (v *Validator) Add(field string, message string) {
+	v.fields = append(v.fields, models.FieldError{Field: field, Message: message})
+}
+
+// Has reports whether field already has a violation.
+func (v *Validator) Has(field string) bool {
+	return slices.ContainsFunc(v.fields, func(f models.FieldError) bool { return f.Field == field })
+}
+
+// Err returns a *models.ValidationError with all recorded violations, or nil.
+func (v *Validator) Err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	return &models.ValidationError{Fields: v.fields}
+}
+
+// Value validates a single value, e.g. a path parameter.
+func Value[T any](field string, value T, rules ...Rule[T]) error {
+	var v Validator
+
+	Check(&v, field, value, rules...)
+
+	return v.Err()
+}
+
+func Required() Rule[string] {
+	return func(value string) string {
+		if value == "" {
+			return "is required"
+		}
+
+		return ""
+	}
+}
+
+// MaxLength limits the number of characters, not bytes.
+func MaxLength(n int) Rule[string] {
+	return func(value string) string {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Sprintf("must be at most %d characters", n)
+		}
+
+		return ""
+	}
+}
+
+// SingleLine accepts valid UTF-8 printable text without line breaks or other control characters.
+func SingleLine() Rule[string] {
+	return func(value string) string {
+		if !utf8.ValidString(value) || strings.IndexFunc(value, func(r rune) bool { return !unicode.IsPrint(r) }) >= 0 {
+			return "must not contain control characters"
+		}
+
+		return ""
+	}
+}
+
+// Text is SingleLine that also allows line breaks and tabs.
+func Text() Rule[string] {
+	return func(value string) string {
+		if !utf8.ValidString(value) || strings.IndexFunc(value, func(r rune) bool {
+			return !unicode.IsPrint(r) && r != '\n' && r != '\r' && r != '\t'
+		}) >= 0 {
+			return "must not contain control characters"
+		}
+
+		return ""
+	}
+}
+
+func Pattern(re *regexp.Regexp, message string) Rule[string] {
+	return func(value string) string {
+		if !re.MatchString(value) {
+			return message
+		}
+
+		return ""
+	}
+}
+
+// HTTPURL accepts an absolute http or https URL with a host.
+func HTTPURL() Rule[string] {
+	return func(value string) string {
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return "must be an absolute http or https URL"
+		}
+
+		return ""
+	}
+}
+
+// PublicHost refuses a URL whose host is localhost or a loopback, private or link-local address. Other host
+// names pass, as they are only resolved when they are dialed.
+func PublicHost() Rule[string] {
+	return func(value string) string {
+		parsed, err := url.Parse(value)
+		if err != nil {
+			return "must be an absolute http or https URL"
+		}
+
+		host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return "must not be a loopback, private or link-local host"
+		}
+
+		if addr, err := netip.ParseAddr(host); err == nil && !models.PublicAddr(addr) {
+			return "must not be a loopback, private or link-local host"
+		}
+
+		return ""
+	}
+}
+
+func OneOf(values ...string) Rule[string] {
+	return func(value string) string {
+		if !slices.Contains(values, value) {
+			return "must be one of " + strings.Join(values, ", ")
+		}
+
+		return ""
+	}
+}
+
+// ID accepts the string form of a SERIAL key: a decimal integer between 1 and 2^31-1.
+func ID() Rule[string] {
+	return func(value string) string {
+		id, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || id < 1 {
+			return "must be a positive integer id"
+		}
+
+		return ""
+	}
+}
+
+// BigID accepts the string form of a BIGSERIAL key.
+func BigID() Rule[string] {
+	return func(value string) string {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id < 1 {
+			return "must be a positive integer id"
+		}
+
+		return ""
+	}
+}
+
+func Min(n int64) Rule[int64] {
+	return func(value int64) string {
+		if value < n {
+			return fmt.Sprintf("must be at least %d", n)
+		}
+
+		return ""
+	}
+}
+
+func NotZero() Rule[int64] {
+	return func(value int64) string {
+		if value == 0 {
+			return "must not be zero"
+		}
+
+		return ""
+	}
+}
+
+// NotEmpty requires at least one element.
+func NotEmpty[T any]() Rule[[]T] {
+	return func(value []T) string {
+		if len(value) == 0 {
+			return "must not be empty"
+		}
+
+		return ""
+	}
+}
+
+// Each applies rules to every element and reports the first failing element.
+func Each[T any](rules ...Rule[T]) Rule[[]T] {
+	return func(values []T) string {
+		for i, value := range values {
+			for _, rule := range rules {
+				if msg := rule(value); msg != "" {
+					return fmt.Sprintf("element %d %s", i, msg)
+				}
+			}
+		}
+
+		return ""
+	}
+}
+
+// Future accepts nil or a time after now.
+func Future() Rule[*time.Time] {
+	return func(value *time.Time) string {
+		if value != nil && !value.After(time.Now()) {
+			return "must be in the future"
+		}
+
+		return ""
+	}
+}
+
+// Optional applies rules only when the pointer is set, for patch DTOs.
+func Optional[T any](rules ...Rule[T]) Rule[*T] {
+	return func(value *T) string {
+		if value == nil {
+			return ""
+		}
+
+		for _, rule := range rules {
+			if msg := rule(*value); msg != "" {
+				return msg
+			}
+		}
+
+		return ""
+	}
+}
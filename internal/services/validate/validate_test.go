@@ -0,0 +1,73 @@
+package validate_test
+
+import (
+	"strings"
+	"testing"
+	"tradeservice/internal/models"
+	"tradeservice/internal/services/validate"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator_ReportsEveryField(t *testing.T) {
+	t.Parallel()
+
+	var v validate.Validator
+
+	validate.Check(&v, "name", strings.Repeat("x", 10_000), validate.Required(), validate.MaxLength(200))
+	validate.Check(&v, "sku", "", validate.Required(), validate.MaxLength(64))
+	validate.Check(&v, "unitPrice", int64(-1), validate.Min(0))
+	validate.Check(&v, "currency", "EUR", validate.OneOf("EUR", "USD"))
+
+	err := v.Err()
+	require.ErrorIs(t, err, models.ErrValidation)
+
+	var validation *models.ValidationError
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, []models.FieldError{
+		{Field: "name", Message: "must be at most 200 characters"},
+		{Field: "sku", Message: "is required"},
+		{Field: "unitPrice", Message: "must be at least 0"},
+	}, validation.Fields)
+}
+
+func TestRules(t *testing.T) {
+	t.Parallel()
+
+	for value, ok := range map[string]bool{
+		"1": true, "2147483647": true, "0": false, "-3": false, "abc": false, "2147483648": false, "": false,
+	} {
+		assert.Equal(t, ok, validate.ID()(value) == "", "id %q", value)
+	}
+
+	assert.Empty(t, validate.SingleLine()("Kaffee & Tee – 250 g"))
+	assert.NotEmpty(t, validate.SingleLine()("two\nlines"))
+	assert.NotEmpty(t, validate.SingleLine()("bell\a"))
+	assert.Empty(t, validate.Text()("two\nlines"))
+	assert.Empty(t, validate.MaxLength(3)("äöü"))
+
+	for value, ok := range map[string]bool{
+		"https://partner.example.com/hooks?v=2": true, "http://localhost:8080": true, "ftp://example.com": false,
+		"https://": false, "/hooks": false, "": false,
+	} {
+		assert.Equal(t, ok, validate.HTTPURL()(value) == "", "url %q", value)
+	}
+
+	for value, ok := range map[string]bool{
+		"https://partner.example.com/hooks": true, "https://203.0.113.7": true, "http://[2001:db8::1]:8080": true,
+		"http://localhost:8080": false, "http://api.localhost": false, "http://127.0.0.1": false,
+		"http://10.1.2.3": false, "http://192.168.0.1": false, "http://169.254.169.254/latest": false,
+		"http://[::1]": false, "http://[fe80::1%25eth0]": false, "http://[::ffff:127.0.0.1]": false,
+		"http://0.0.0.0": false,
+	} {
+		assert.Equal(t, ok, validate.PublicHost()(value) == "", "url %q", value)
+	}
+
+	name := "  "
+	assert.Empty(t, validate.Optional(validate.MaxLength(1))(nil))
+	assert.NotEmpty(t, validate.Optional(validate.MaxLength(1))(&name))
+
+	assert.Empty(t, validate.Each(validate.OneOf("a", "b"))([]string{"a", "b"}))
+	assert.Equal(t, "element 1 must be one of a, b", validate.Each(validate.OneOf("a", "b"))([]string{"a", "c"}))
+}
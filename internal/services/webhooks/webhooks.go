@@ -0,0 +1,268 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"tradeservice/internal/logger"
+	"tradeservice/internal/models"
+	"tradeservice/internal/services/validate"
+	"tradeservice/internal/storage"
+	"tradeservice/internal/tracing"
+)
+
+const (
+	secretPrefix = "whsec_"
+	secretBytes  = 32
+	maxURLLength = 2048
+)
+
+var secretPattern = regexp.MustCompile(`^[\x21-\x7e]{16,200}$`)
+
+type StorageWebhooks struct {
+	storage  storage.WebhookRepository
+	urlRules []validate.Rule[string]
+}
+
+// New manages the webhooks of storage. Unless allowPrivateHosts is set, webhook URLs on loopback, private and
+// link-local hosts are refused.
+func New(storage storage.WebhookRepository, allowPrivateHosts bool) *StorageWebhooks {
+	urlRules := []validate.Rule[string]{validate.Required(), validate.MaxLength(maxURLLength), validate.HTTPURL()}
+	if !allowPrivateHosts {
+		urlRules = append(urlRules, validate.PublicHost())
+	}
+
+	return &StorageWebhooks{
+		storage:  storage,
+		urlRules: urlRules,
+	}
+}
+
+// AddWebhook creates an active webhook and returns its signing secret once. Without a secret of the caller's
+// choice, one is generated.
+func (c StorageWebhooks) AddWebhook(ctx context.Context, webhook models.WebhookSecret) (_ models.WebhookSecret,
+	err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.AddWebhook")
+	defer tracing.End(span, &err)
+
+	var v validate.Validator
+
+	dto := c.checkWebhook(&v, webhook.WebhookDto)
+	if webhook.Secret != "" {
+		validate.Check(&v, "secret", webhook.Secret,
+			validate.Pattern(secretPattern, "must be 16 to 200 printable ASCII characters without spaces"))
+	}
+
+	if err := v.Err(); err != nil {
+		return models.WebhookSecret{}, err
+	}
+
+	secret := webhook.Secret
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return models.WebhookSecret{}, err
+		}
+	}
+
+	dto.Active = true
+
+	res, err := c.storage.AddWebhook(ctx, dto, secret)
+	if err != nil {
+		return models.WebhookSecret{}, fmt.Errorf("failed to add webhook %w", err)
+	}
+
+	// The URL may carry credentials or tokens of the partner, so only its host is logged.
+	logger.FromContext(ctx).Info("Webhook created", "id", res.ID, "host", urlHost(res.URL))
+
+	return models.WebhookSecret{WebhookDto: res, Secret: secret}, nil
+}
+
+func (c StorageWebhooks) GetWebhooks(ctx context.Context) (_ []models.WebhookDto, err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.GetWebhooks")
+	defer tracing.End(span, &err)
+
+	webhooks, err := c.storage.GetWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (c StorageWebhooks) GetWebhookByID(ctx context.Context, id string) (_ models.WebhookDto, err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.GetWebhookByID")
+	defer tracing.End(span, &err)
+
+	if err := validate.Value("id", id, validate.BigID()); err != nil {
+		return models.WebhookDto{}, err
+	}
+
+	webhook, err := c.storage.GetWebhookByID(ctx, id)
+	if err != nil {
+		return models.WebhookDto{}, fmt.Errorf("failed to get webhook %w", err)
+	}
+
+	return webhook, nil
+}
+
+// SetWebhook replaces the URL, event types and active flag of a webhook; its secret stays the same.
+func (c StorageWebhooks) SetWebhook(ctx context.Context, id string,
+	webhook models.WebhookDto) (_ models.WebhookDto, err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.SetWebhook")
+	defer tracing.End(span, &err)
+
+	var v validate.Validator
+
+	validate.Check(&v, "id", id, validate.BigID())
+	dto := c.checkWebhook(&v, webhook)
+
+	if err := v.Err(); err != nil {
+		return models.WebhookDto{}, err
+	}
+
+	res, err := c.storage.SetWebhook(ctx, id, dto)
+	if err != nil {
+		return models.WebhookDto{}, fmt.Errorf("failed to update webhook %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Webhook updated", "id", id, "active", res.Active)
+
+	return res, nil
+}
+
+func (c StorageWebhooks) DeleteWebhook(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.DeleteWebhook")
+	defer tracing.End(span, &err)
+
+	if err := validate.Value("id", id, validate.BigID()); err != nil {
+		return err
+	}
+
+	if err := c.storage.DeleteWebhook(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Webhook deleted", "id", id)
+
+	return nil
+}
+
+// GetWebhookDeliveries lists the deliveries of a webhook, newest first.
+func (c StorageWebhooks) GetWebhookDeliveries(ctx context.Context, webhookID string,
+	params models.ListParams) (_ models.Page[models.WebhookDeliveryDto], err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.GetWebhookDeliveries")
+	defer tracing.End(span, &err)
+
+	params = models.ListParams{
+		Limit:        params.Limit,
+		Cursor:       params.Cursor,
+		Sort:         "-" + models.SortByCreatedAt,
+		CreatedAfter: params.CreatedAfter,
+	}
+
+	if err := validate.Value("id", webhookID, validate.BigID()); err != nil {
+		return models.Page[models.WebhookDeliveryDto]{}, err
+	}
+
+	params, err = params.Normalize()
+	if err != nil {
+		return models.Page[models.WebhookDeliveryDto]{}, err
+	}
+
+	// An unknown webhook is not found rather than without deliveries.
+	if _, err := c.storage.GetWebhookByID(ctx, webhookID); err != nil {
+		return models.Page[models.WebhookDeliveryDto]{}, fmt.Errorf("failed to get webhook %w", err)
+	}
+
+	deliveries, err := c.storage.GetWebhookDeliveries(ctx, webhookID, params)
+	if err != nil {
+		return deliveries, fmt.Errorf("failed to get webhook deliveries %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// GetWebhookDelivery returns a delivery of a webhook with the history of its attempts.
+func (c StorageWebhooks) GetWebhookDelivery(ctx context.Context, webhookID string,
+	id string) (_ models.WebhookDeliveryDto, err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.GetWebhookDelivery")
+	defer tracing.End(span, &err)
+
+	if err := checkDeliveryIDs(webhookID, id); err != nil {
+		return models.WebhookDeliveryDto{}, err
+	}
+
+	delivery, err := c.storage.GetWebhookDelivery(ctx, webhookID, id)
+	if err != nil {
+		return models.WebhookDeliveryDto{}, fmt.Errorf("failed to get webhook delivery %w", err)
+	}
+
+	return delivery, nil
+}
+
+// RedeliverWebhookDelivery queues a delivery of a webhook to be sent again right away, whether it was
+// delivered, failed for good or is still being retried. Its attempts so far are kept.
+func (c StorageWebhooks) RedeliverWebhookDelivery(ctx context.Context, webhookID string,
+	id string) (_ models.WebhookDeliveryDto, err error) {
+	ctx, span := tracing.Start(ctx, "webhooks.RedeliverWebhookDelivery")
+	defer tracing.End(span, &err)
+
+	if err := checkDeliveryIDs(webhookID, id); err != nil {
+		return models.WebhookDeliveryDto{}, err
+	}
+
+	delivery, err := c.storage.RedeliverWebhookDelivery(ctx, webhookID, id)
+	if err != nil {
+		return models.WebhookDeliveryDto{}, fmt.Errorf("failed to redeliver webhook delivery %w", err)
+	}
+
+	logger.FromContext(ctx).Info("Webhook delivery queued again", "id", id, "webhook_id", webhookID)
+
+	return delivery, nil
+}
+
+// checkWebhook validates the URL and event types of webhook and returns them trimmed and with the event
+// types sorted and deduplicated.
+func (c StorageWebhooks) checkWebhook(v *validate.Validator, webhook models.WebhookDto) models.WebhookDto {
+	webhook.URL = strings.TrimSpace(webhook.URL)
+	webhook.EventTypes = slices.Compact(slices.Sorted(slices.Values(webhook.EventTypes)))
+
+	validate.Check(v, "url", webhook.URL, c.urlRules...)
+	validate.Check(v, "eventTypes", webhook.EventTypes, validate.NotEmpty[string](),
+		validate.Each(validate.OneOf(models.EventTypes...)))
+
+	return webhook
+}
+
+func checkDeliveryIDs(webhookID string, id string) error {
+	var v validate.Validator
+
+	validate.Check(&v, "id", webhookID, validate.BigID())
+	validate.Check(&v, "deliveryId", id, validate.BigID())
+
+	return v.Err()
+}
+
+// urlHost is the host of a validated webhook URL.
+func urlHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Host
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret %w", err)
+	}
+
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
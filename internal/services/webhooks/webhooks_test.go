@@ -0,0 +1,147 @@
+package webhooks_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"tradeservice/internal/models"
+	"tradeservice/internal/services/webhooks"
+	"tradeservice/internal/storage/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageWebhooks_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	store := memory.NewWebhooks(memory.New())
+	manager := webhooks.New(store, false)
+	ctx := context.Background()
+
+	created, err := manager.AddWebhook(ctx, models.WebhookSecret{WebhookDto: models.WebhookDto{
+		URL:        " https://partner.example.com/hooks ",
+		EventTypes: []string{models.EventProductDeleted, models.EventProductCreated, models.EventProductDeleted},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "https://partner.example.com/hooks", created.URL)
+	assert.Equal(t, []string{models.EventProductCreated, models.EventProductDeleted}, created.EventTypes)
+	assert.True(t, created.Active)
+	assert.True(t, strings.HasPrefix(created.Secret, "whsec_"))
+
+	chosen, err := manager.AddWebhook(ctx, models.WebhookSecret{
+		WebhookDto: models.WebhookDto{URL: "http://partner.example.com:9000", EventTypes: models.EventTypes},
+		Secret:     "a-secret-of-our-own",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "a-secret-of-our-own", chosen.Secret)
+
+	updated, err := manager.SetWebhook(ctx, created.ID, models.WebhookDto{
+		URL:        "https://partner.example.com/v2",
+		EventTypes: []string{models.EventCategoryCreated},
+	})
+	require.NoError(t, err)
+	assert.False(t, updated.Active)
+
+	got, err := manager.GetWebhookByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated, got)
+
+	all, err := manager.GetWebhooks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	require.NoError(t, manager.DeleteWebhook(ctx, created.ID))
+
+	_, err = manager.GetWebhookByID(ctx, created.ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = manager.GetWebhookDeliveries(ctx, created.ID, models.ListParams{})
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestStorageWebhooks_Redeliver(t *testing.T) {
+	t.Parallel()
+
+	store := memory.NewWebhooks(memory.New())
+	manager := webhooks.New(store, false)
+	ctx := context.Background()
+
+	created, err := manager.AddWebhook(ctx, models.WebhookSecret{WebhookDto: models.WebhookDto{
+		URL:        "https://partner.example.com/hooks",
+		EventTypes: []string{models.EventProductCreated},
+	}})
+	require.NoError(t, err)
+
+	_, err = store.AddWebhookDeliveries(ctx, models.Event{ID: "1", Type: models.EventProductCreated}, []byte(`{}`))
+	require.NoError(t, err)
+
+	_, err = store.AddWebhookDeliveries(ctx, models.Event{ID: "2", Type: models.EventProductCreated}, []byte(`{}`))
+	require.NoError(t, err)
+
+	first, err := manager.GetWebhookDeliveries(ctx, created.ID, models.ListParams{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, first.Items, 1)
+	assert.Equal(t, "2", first.Items[0].EventID, "newest first")
+	require.NotEmpty(t, first.NextCursor)
+
+	deliveries, err := manager.GetWebhookDeliveries(ctx, created.ID,
+		models.ListParams{Limit: 1, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, deliveries.Items, 1)
+	assert.Equal(t, "1", deliveries.Items[0].EventID)
+	assert.Empty(t, deliveries.NextCursor)
+
+	require.NoError(t, store.RecordWebhookAttempt(ctx, deliveries.Items[0].ID,
+		models.WebhookAttemptDto{StatusCode: 410, Error: "gone"}, nil))
+
+	delivery, err := manager.GetWebhookDelivery(ctx, created.ID, deliveries.Items[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryFailed, delivery.Status)
+	assert.Len(t, delivery.History, 1)
+
+	delivery, err = manager.RedeliverWebhookDelivery(ctx, created.ID, deliveries.Items[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.NotNil(t, delivery.NextAttempt)
+
+	_, err = manager.RedeliverWebhookDelivery(ctx, created.ID, "999")
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = manager.RedeliverWebhookDelivery(ctx, created.ID, "latest")
+	require.ErrorIs(t, err, models.ErrValidation)
+}
+
+func TestStorageWebhooks_AddWebhook_Validation(t *testing.T) {
+	t.Parallel()
+
+	manager := webhooks.New(memory.NewWebhooks(memory.New()), false)
+	products := []string{models.EventProductCreated}
+
+	for name, webhook := range map[string]models.WebhookSecret{
+		"no url":        {WebhookDto: models.WebhookDto{EventTypes: products}},
+		"relative url":  {WebhookDto: models.WebhookDto{URL: "/hooks", EventTypes: products}},
+		"ftp url":       {WebhookDto: models.WebhookDto{URL: "ftp://example.com", EventTypes: products}},
+		"no events":     {WebhookDto: models.WebhookDto{URL: "https://example.com"}},
+		"unknown event": {WebhookDto: models.WebhookDto{URL: "https://example.com", EventTypes: []string{"order.paid"}}},
+		"short secret":  {WebhookDto: models.WebhookDto{URL: "https://example.com", EventTypes: products}, Secret: "s"},
+		"localhost":     {WebhookDto: models.WebhookDto{URL: "http://localhost:8080", EventTypes: products}},
+		"private host":  {WebhookDto: models.WebhookDto{URL: "http://10.0.0.5/hooks", EventTypes: products}},
+		"metadata host": {WebhookDto: models.WebhookDto{URL: "http://169.254.169.254", EventTypes: products}},
+	} {
+		_, err := manager.AddWebhook(context.Background(), webhook)
+		require.ErrorIs(t, err, models.ErrValidation, name)
+	}
+}
+
+func TestStorageWebhooks_AllowPrivateHosts(t *testing.T) {
+	t.Parallel()
+
+	manager := webhooks.New(memory.NewWebhooks(memory.New()), true)
+
+	created, err := manager.AddWebhook(context.Background(), models.WebhookSecret{WebhookDto: models.WebhookDto{
+		URL: "http://127.0.0.1:9000/hooks", EventTypes: []string{models.EventProductCreated},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:9000/hooks", created.URL)
+}
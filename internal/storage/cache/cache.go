@@ -0,0 +1,173 @@
+// Package cache keeps catalog reads in process memory in front of a storage backend.
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"tradeservice/internal/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var ErrCacheConfig = errors.New("invalid cache config")
+
+type entry struct {
+	key     string
+	value   any
+	expires time.Time
+
+	prev, next *entry
+}
+
+// Cache is an LRU of read results whose entries also expire after a TTL. The product and category
+// decorators share one Cache and purge it on every write, as a write to either can change the result
+// of any list read, e.g. products filtered by category.
+type Cache struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu    sync.Mutex
+	items map[string]*entry
+	// order is the sentinel of a ring of the entries, most recently used first.
+	order entry
+	// generation counts purges, so a read that overlaps one doesn't store what the write changed.
+	generation uint64
+}
+
+func New(cfg config.CacheConfig) (*Cache, error) {
+	if cfg.Size < 1 || cfg.TTL <= 0 {
+		return nil, fmt.Errorf("%w: size must be at least 1 and ttl positive", ErrCacheConfig)
+	}
+
+	c := &Cache{
+		size:  cfg.Size,
+		ttl:   cfg.TTL,
+		now:   time.Now,
+		items: make(map[string]*entry),
+	}
+
+	c.order.prev, c.order.next = &c.order, &c.order
+
+	return c, nil
+}
+
+// Purge drops every entry, e.g. after a write.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.items)
+	c.order.prev, c.order.next = &c.order, &c.order
+	c.generation++
+}
+
+// Len is the number of entries, including expired ones not evicted yet.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.items)
+}
+
+// get returns the live value of key and the generation to store a freshly loaded one with.
+func (c *Cache) get(key string) (any, bool, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ent, ok := c.items[key]
+	if !ok {
+		return nil, false, c.generation
+	}
+
+	if !c.now().Before(ent.expires) {
+		c.remove(ent)
+
+		return nil, false, c.generation
+	}
+
+	c.unlink(ent)
+	c.pushFront(ent)
+
+	return ent.value, true, c.generation
+}
+
+// add stores value unless the cache was purged since generation was read.
+func (c *Cache) add(key string, value any, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if ent, ok := c.items[key]; ok {
+		c.remove(ent)
+	}
+
+	ent := &entry{key: key, value: value, expires: c.now().Add(c.ttl)}
+
+	c.items[key] = ent
+	c.pushFront(ent)
+
+	for len(c.items) > c.size {
+		c.remove(c.order.prev)
+	}
+}
+
+func (c *Cache) remove(ent *entry) {
+	c.unlink(ent)
+	delete(c.items, ent.key)
+}
+
+func (c *Cache) pushFront(ent *entry) {
+	ent.prev, ent.next = &c.order, c.order.next
+	ent.next.prev = ent
+	c.order.next = ent
+}
+
+func (c *Cache) unlink(ent *entry) {
+	ent.prev.next = ent.next
+	ent.next.prev = ent.prev
+}
+
+// counters are the hit and miss counters of one decorator.
+type counters struct {
+	hits   prometheus.Counter
+	misses prometheus.Counter
+}
+
+// read returns the cached result of key or loads and caches it. Errors aren't cached, so a missing row
+// is looked up again on the next read.
+func read[T any](c *Cache, stats counters, key string, load func() (T, error)) (T, error) {
+	cached, ok, generation := c.get(key)
+	if value, isT := cached.(T); ok && isT {
+		stats.hits.Inc()
+
+		return value, nil
+	}
+
+	stats.misses.Inc()
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	c.add(key, value, generation)
+
+	return value, nil
+}
+
+// listKey identifies a list read by its normalized parameters.
+func listKey(prefix string, params any) (string, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cache key %w", err)
+	}
+
+	return prefix + string(raw), nil
+}
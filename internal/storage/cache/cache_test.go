@@ -0,0 +1,248 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tradeservice/internal/config"
+	"tradeservice/internal/models"
+	"tradeservice/internal/storage"
+	"tradeservice/internal/storage/cache"
+	"tradeservice/internal/storage/memory"
+	"tradeservice/internal/storage/storagetest"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var catalogConfig = config.CacheConfig{Enabled: true, Size: 100, TTL: time.Minute}
+
+// countingProducts counts the reads that reach the storage.
+type countingProducts struct {
+	storage.ProductRepository
+	reads int
+}
+
+func (c *countingProducts) GetProduct(ctx context.Context, params models.ListParams) (models.Page[models.ProductDto], error) {
+	c.reads++
+
+	return c.ProductRepository.GetProduct(ctx, params)
+}
+
+func (c *countingProducts) GetProductByID(ctx context.Context, id string) (models.ProductDto, error) {
+	c.reads++
+
+	return c.ProductRepository.GetProductByID(ctx, id)
+}
+
+type counters struct {
+	hits   prometheus.Counter
+	misses prometheus.Counter
+}
+
+func newCounters() counters {
+	return counters{
+		hits:   prometheus.NewCounter(prometheus.CounterOpts{Name: "hits"}),
+		misses: prometheus.NewCounter(prometheus.CounterOpts{Name: "misses"}),
+	}
+}
+
+func newCache(t *testing.T, cfg config.CacheConfig) *cache.Cache {
+	t.Helper()
+
+	catalog, err := cache.New(cfg)
+	require.NoError(t, err)
+
+	return catalog
+}
+
+func newProducts(t *testing.T, cfg config.CacheConfig) (*cache.Products, *countingProducts, counters) {
+	t.Helper()
+
+	backend := &countingProducts{ProductRepository: memory.NewProducts(memory.New())}
+	stats := newCounters()
+
+	return cache.NewProducts(backend, newCache(t, cfg), stats.hits, stats.misses), backend, stats
+}
+
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	storagetest.Run(t, func(t *testing.T) storage.Repositories {
+		t.Helper()
+
+		db := memory.New()
+		catalog := newCache(t, catalogConfig)
+		products, categories := newCounters(), newCounters()
+
+		return storage.Repositories{
+			Categories: cache.NewCategories(memory.NewCategories(db), catalog, categories.hits, categories.misses),
+			Products:   cache.NewProducts(memory.NewProducts(db), catalog, products.hits, products.misses),
+			Inventory:  memory.NewInventory(db),
+			Orders:     memory.NewOrders(db),
+			APIKeys:    memory.NewAPIKeys(db),
+			Outbox:     memory.NewOutbox(db),
+			Webhooks:   memory.NewWebhooks(db),
+		}
+	})
+}
+
+func TestNew_RejectsInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	for _, cfg := range []config.CacheConfig{{Size: 0, TTL: time.Minute}, {Size: 10, TTL: 0}} {
+		_, err := cache.New(cfg)
+		require.ErrorIs(t, err, cache.ErrCacheConfig)
+	}
+}
+
+func TestProducts_ServesRepeatedReadsFromCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	products, backend, stats := newProducts(t, catalogConfig)
+
+	created, err := products.AddProduct(ctx, models.ProductDto{Name: "bolt", SKU: "B-1", Currency: "USD"})
+	require.NoError(t, err)
+
+	params, err := models.ListParams{}.Normalize()
+	require.NoError(t, err)
+
+	for range 3 {
+		got, err := products.GetProductByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created, got)
+
+		page, err := products.GetProduct(ctx, params)
+		require.NoError(t, err)
+		assert.Len(t, page.Items, 1)
+	}
+
+	assert.Equal(t, 2, backend.reads)
+	assert.InDelta(t, 4, testutil.ToFloat64(stats.hits), 0)
+	assert.InDelta(t, 2, testutil.ToFloat64(stats.misses), 0)
+}
+
+func TestProducts_WritesInvalidateReads(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	products, _, _ := newProducts(t, catalogConfig)
+
+	created, err := products.AddProduct(ctx, models.ProductDto{Name: "bolt", SKU: "B-1", Currency: "USD"})
+	require.NoError(t, err)
+
+	_, err = products.GetProductByID(ctx, created.ID)
+	require.NoError(t, err)
+
+	name := "nut"
+	_, err = products.PatchProduct(ctx, created.ID, models.ProductPatch{Name: &name})
+	require.NoError(t, err)
+
+	got, err := products.GetProductByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "nut", got.Name)
+
+	require.NoError(t, products.DeleteProduct(ctx, created.ID))
+
+	_, err = products.GetProductByID(ctx, created.ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestProducts_DoesNotCacheErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	products, backend, _ := newProducts(t, catalogConfig)
+
+	for range 2 {
+		_, err := products.GetProductByID(ctx, "1")
+		require.ErrorIs(t, err, models.ErrNotFound)
+	}
+
+	assert.Equal(t, 2, backend.reads)
+}
+
+func TestCategories_AssignmentInvalidatesProductList(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := memory.New()
+	catalog := newCache(t, catalogConfig)
+	stats := newCounters()
+	products := cache.NewProducts(memory.NewProducts(db), catalog, stats.hits, stats.misses)
+	categories := cache.NewCategories(memory.NewCategories(db), catalog, stats.hits, stats.misses)
+
+	product, err := products.AddProduct(ctx, models.ProductDto{Name: "bolt", SKU: "B-1", Currency: "USD"})
+	require.NoError(t, err)
+
+	category, err := categories.AddCategory(ctx, models.CategoryDto{Name: "hardware"})
+	require.NoError(t, err)
+
+	params, err := models.ListParams{CategoryID: category.ID}.Normalize()
+	require.NoError(t, err)
+
+	page, err := products.GetProduct(ctx, params)
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+
+	require.NoError(t, categories.AssignProduct(ctx, category.ID, product.ID))
+
+	page, err = products.GetProduct(ctx, params)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	products, backend, _ := newProducts(t, config.CacheConfig{Size: 2, TTL: time.Minute})
+
+	var ids []string
+
+	for _, sku := range []string{"A-1", "B-1", "C-1"} {
+		created, err := products.AddProduct(ctx, models.ProductDto{Name: sku, SKU: sku, Currency: "USD"})
+		require.NoError(t, err)
+
+		ids = append(ids, created.ID)
+	}
+
+	read := func(id string) {
+		_, err := products.GetProductByID(ctx, id)
+		require.NoError(t, err)
+	}
+
+	read(ids[0])
+	read(ids[1])
+	read(ids[0]) // A is now more recently used than B
+	read(ids[2]) // evicts B
+	assert.Equal(t, 3, backend.reads)
+
+	read(ids[0])
+	assert.Equal(t, 3, backend.reads)
+
+	read(ids[1])
+	assert.Equal(t, 4, backend.reads)
+}
+
+func TestCache_ExpiresEntries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	products, backend, _ := newProducts(t, config.CacheConfig{Size: 10, TTL: 20 * time.Millisecond})
+
+	created, err := products.AddProduct(ctx, models.ProductDto{Name: "bolt", SKU: "B-1", Currency: "USD"})
+	require.NoError(t, err)
+
+	_, err = products.GetProductByID(ctx, created.ID)
+	require.NoError(t, err)
+
+	time.Sleep(40 * time.Millisecond)
+
+	_, err = products.GetProductByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, backend.reads)
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"tradeservice/internal/models"
+	"tradeservice/internal/storage"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Categories caches the reads of a CategoryRepository. Cached results are shared between readers, which must
+// not modify them.
+type Categories struct {
+	storage storage.CategoryRepository
+	cache   *Cache
+	stats   counters
+}
+
+func NewCategories(storage storage.CategoryRepository, cache *Cache, hits prometheus.Counter,
+	misses prometheus.Counter) *Categories {
+	return &Categories{
+		storage: storage,
+		cache:   cache,
+		stats:   counters{hits: hits, misses: misses},
+	}
+}
+
+func (c *Categories) GetCategory(ctx context.Context, params models.ListParams) (models.Page[models.CategoryDto], error) {
+	key, err := listKey("categories:", params)
+	if err != nil {
+		return models.Page[models.CategoryDto]{}, err
+	}
+
+	return read(c.cache, c.stats, key, func() (models.Page[models.CategoryDto], error) {
+		return c.storage.GetCategory(ctx, params)
+	})
+}
+
+func (c *Categories) GetCategoryByID(ctx context.Context, id string) (models.CategoryDto, error) {
+	return read(c.cache, c.stats, "category:"+id, func() (models.CategoryDto, error) {
+		return c.storage.GetCategoryByID(ctx, id)
+	})
+}
+
+func (c *Categories) GetCategorySubtree(ctx context.Context, id string) ([]models.CategoryDto, error) {
+	return read(c.cache, c.stats, "category-subtree:"+id, func() ([]models.CategoryDto, error) {
+		return c.storage.GetCategorySubtree(ctx, id)
+	})
+}
+
+func (c *Categories) GetCategoryAncestors(ctx context.Context, id string) ([]models.CategoryDto, error) {
+	return read(c.cache, c.stats, "category-ancestors:"+id, func() ([]models.CategoryDto, error) {
+		return c.storage.GetCategoryAncestors(ctx, id)
+	})
+}
+
+func (c *Categories) GetProductCategories(ctx context.Context, productID string) ([]models.CategoryDto, error) {
+	return read(c.cache, c.stats, "product-categories:"+productID, func() ([]models.CategoryDto, error) {
+		return c.storage.GetProductCategories(ctx, productID)
+	})
+}
+
+func (c *Categories) AddCategory(ctx context.Context, category models.CategoryDto) (models.CategoryDto, error) {
+	defer c.cache.Purge()
+
+	return c.storage.AddCategory(ctx, category)
+}
+
+func (c *Categories) SetCategory(ctx context.Context, id string, name string) (models.CategoryDto, error) {
+	defer c.cache.Purge()
+
+	return c.storage.SetCategory(ctx, id, name)
+}
+
+func (c *Categories) MoveCategory(ctx context.Context, id string, parentID *string) (models.CategoryDto, error) {
+	defer c.cache.Purge()
+
+	return c.storage.MoveCategory(ctx, id, parentID)
+}
+
+func (c *Categories) AssignProduct(ctx context.Context, categoryID string, productID string) error {
+	defer c.cache.Purge()
+
+	return c.storage.AssignProduct(ctx, categoryID, productID)
+}
+
+func (c *Categories) AddCategoryForProduct(ctx context.Context, name string,
+	productID string) (models.CategoryDto, error) {
+	defer c.cache.Purge()
+
+	return c.storage.AddCategoryForProduct(ctx, name, productID)
+}
+
+func (c *Categories) UnassignProduct(ctx context.Context, categoryID string, productID string) error {
+	defer c.cache.Purge()
+
+	return c.storage.UnassignProduct(ctx, categoryID, productID)
+}
+
+func (c *Categories) DeleteCategory(ctx context.Context, id string) error {
+	defer c.cache.Purge()
+
+	return c.storage.DeleteCategory(ctx, id)
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"tradeservice/internal/models"
+	"tradeservice/internal/storage"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Products caches the reads of a ProductRepository. Cached results are shared between readers, which must
+// not modify them.
+type Products struct {
+	storage storage.ProductRepository
+	cache   *Cache
+	stats   counters
+}
+
+func NewProducts(storage storage.ProductRepository, cache *Cache, hits prometheus.Counter,
+	misses prometheus.Counter) *Products {
+	return &Products{
+		storage: storage,
+		cache:   cache,
+		stats:   counters{hits: hits, misses: misses},
+	}
+}
+
+func (c *Products) GetProduct(ctx context.Context, params models.ListParams) (models.Page[models.ProductDto], error) {
+	key, err := listKey("products:", params)
+	if err != nil {
+		return models.Page[models.ProductDto]{}, err
+	}
+
+	return read(c.cache, c.stats, key, func() (models.Page[models.ProductDto], error) {
+		return c.storage.GetProduct(ctx, params)
+	})
+}
+
+func (c *Products) GetProductByID(ctx context.Context, id string) (models.ProductDto, error) {
+	return read(c.cache, c.stats, "product:"+id, func() (models.ProductDto, error) {
+		return c.storage.GetProductByID(ctx, id)
+	})
+}
+
+func (c *Products) AddProduct(ctx context.Context, product models.ProductDto) (models.ProductDto, error) {
+	defer c.cache.Purge()
+
+	return c.storage.AddProduct(ctx, product)
+}
+
+func (c *Products) SetProduct(ctx context.Context, id string, product models.ProductDto) (models.ProductDto, error) {
+	defer c.cache.Purge()
+
+	return c.storage.SetProduct(ctx, id, product)
+}
+
+func (c *Products) PatchProduct(ctx context.Context, id string, patch models.ProductPatch) (models.ProductDto, error) {
+	defer c.cache.Purge()
+
+	return c.storage.PatchProduct(ctx, id, patch)
+}
+
+// DeleteProduct also drops the category links of the product, so it purges the category reads too.
+func (c *Products) DeleteProduct(ctx context.Context, id string) error {
+	defer c.cache.Purge()
+
+	return c.storage.DeleteProduct(ctx, id)
+}
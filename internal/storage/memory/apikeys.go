@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+	"tradeservice/internal/models"
+)
+
+type APIKeys struct {
+	db *Storage
+}
+
+func NewAPIKeys(db *Storage) *APIKeys {
+	return &APIKeys{
+		db: db,
+	}
+}
+
+func (c *APIKeys) AddAPIKey(_ context.Context, key models.APIKeyDto, hash []byte) (models.APIKeyDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if c.hashTaken("", hash) {
+		return models.APIKeyDto{}, models.ErrUnique
+	}
+
+	created := now()
+	res := models.APIKey{
+		ID:      c.db.apiKeyIDs.next(),
+		Name:    key.Name,
+		Prefix:  key.Prefix,
+		Hash:    bytes.Clone(hash),
+		Scopes:  slices.Clone(key.Scopes),
+		Expires: copyTime(key.Expires),
+		Created: created,
+		Updated: created,
+	}
+
+	c.db.apiKeys[res.ID] = res
+
+	return toAPIKeyDto(res), nil
+}
+
+func (c *APIKeys) GetAPIKeys(_ context.Context) ([]models.APIKeyDto, error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	keys := make([]models.APIKeyDto, 0, len(c.db.apiKeys))
+
+	for _, id := range slices.SortedFunc(maps.Keys(c.db.apiKeys), compareIDs) {
+		keys = append(keys, toAPIKeyDto(c.db.apiKeys[id]))
+	}
+
+	return keys, nil
+}
+
+func (c *APIKeys) GetAPIKeyByHash(_ context.Context, hash []byte) (models.APIKeyDto, error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	for _, key := range c.db.apiKeys {
+		if bytes.Equal(key.Hash, hash) {
+			return toAPIKeyDto(key), nil
+		}
+	}
+
+	return models.APIKeyDto{}, models.ErrNotFound
+}
+
+// RotateAPIKey replaces the secret of a key that hasn't been revoked; the old secret stops working immediately.
+func (c *APIKeys) RotateAPIKey(_ context.Context, id string, prefix string, hash []byte) (models.APIKeyDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	key, ok := c.db.apiKeys[id]
+	if !ok || key.Revoked != nil {
+		return models.APIKeyDto{}, fmt.Errorf("api key %w", models.ErrNotFound)
+	}
+
+	if c.hashTaken(id, hash) {
+		return models.APIKeyDto{}, models.ErrUnique
+	}
+
+	key.Prefix = prefix
+	key.Hash = bytes.Clone(hash)
+	key.LastUsed = nil
+	key.Updated = now()
+	c.db.apiKeys[id] = key
+
+	return toAPIKeyDto(key), nil
+}
+
+func (c *APIKeys) RevokeAPIKey(_ context.Context, id string) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	key, ok := c.db.apiKeys[id]
+	if !ok || key.Revoked != nil {
+		return fmt.Errorf("api key %w", models.ErrNotFound)
+	}
+
+	revoked := now()
+	key.Revoked = &revoked
+	key.Updated = revoked
+	c.db.apiKeys[id] = key
+
+	return nil
+}
+
+func (c *APIKeys) TouchAPIKey(_ context.Context, id string, usedAt time.Time) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if key, ok := c.db.apiKeys[id]; ok {
+		key.LastUsed = &usedAt
+		c.db.apiKeys[id] = key
+	}
+
+	return nil
+}
+
+// hashTaken reports whether a key other than id already has hash.
+func (c *APIKeys) hashTaken(id string, hash []byte) bool {
+	for _, key := range c.db.apiKeys {
+		if key.ID != id && bytes.Equal(key.Hash, hash) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toAPIKeyDto(key models.APIKey) models.APIKeyDto {
+	return models.APIKeyDto{
+		ID:       key.ID,
+		Name:     key.Name,
+		Prefix:   key.Prefix,
+		Scopes:   slices.Clone(key.Scopes),
+		Expires:  copyTime(key.Expires),
+		LastUsed: copyTime(key.LastUsed),
+		Revoked:  copyTime(key.Revoked),
+		Created:  key.Created,
+		Updated:  key.Updated,
+	}
+}
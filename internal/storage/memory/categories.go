@@ -0,0 +1,430 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"tradeservice/internal/models"
+)
+
+type Categories struct {
+	db *Storage
+}
+
+func NewCategories(db *Storage) *Categories {
+	return &Categories{
+		db: db,
+	}
+}
+
+func (c *Categories) GetCategory(_ context.Context, params models.ListParams) (models.Page[models.CategoryDto], error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	page, err := list(slices.Collect(maps.Values(c.db.categories)), params, categoryRow)
+	if err != nil {
+		return models.Page[models.CategoryDto]{}, err
+	}
+
+	return models.Page[models.CategoryDto]{Items: toCategoryDtos(page.Items), NextCursor: page.NextCursor}, nil
+}
+
+func (c *Categories) GetCategoryByID(_ context.Context, id string) (models.CategoryDto, error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	cat, ok := c.db.categories[id]
+	if !ok {
+		return models.CategoryDto{}, models.ErrNotFound
+	}
+
+	return toCategoryDto(cat), nil
+}
+
+func (c *Categories) AddCategory(_ context.Context, category models.CategoryDto) (models.CategoryDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	return c.addCategory(category)
+}
+
+// addCategory creates category; the caller holds the write lock.
+func (c *Categories) addCategory(category models.CategoryDto) (models.CategoryDto, error) {
+	if c.nameTaken("", category.ParentID, category.Name) {
+		return models.CategoryDto{}, models.ErrUnique
+	}
+
+	if category.ParentID != nil {
+		if _, ok := c.db.categories[*category.ParentID]; !ok {
+			return models.CategoryDto{}, fmt.Errorf("parent category %w", models.ErrNotFound)
+		}
+	}
+
+	created := now()
+	cat := models.Category{
+		ID:       c.db.categoryIDs.next(),
+		Name:     category.Name,
+		ParentID: copyString(category.ParentID),
+		Created:  created,
+		Updated:  created,
+	}
+
+	res := toCategoryDto(cat)
+
+	event, err := models.NewEvent(models.EventCategoryCreated, res.ID, res)
+	if err != nil {
+		return models.CategoryDto{}, err
+	}
+
+	c.db.categories[cat.ID] = cat
+	c.db.addEvents(event)
+
+	return res, nil
+}
+
+func (c *Categories) DeleteCategory(_ context.Context, id string) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if _, ok := c.db.categories[id]; !ok {
+		return models.ErrNotFound
+	}
+
+	if len(c.children(id)) > 0 {
+		return fmt.Errorf("%w: category has subcategories", models.ErrConflict)
+	}
+
+	event, err := models.NewEvent(models.EventCategoryDeleted, id, models.EventDeleteData{ID: id})
+	if err != nil {
+		return err
+	}
+
+	delete(c.db.categories, id)
+
+	for link := range c.db.productCategories {
+		if link.categoryID == id {
+			delete(c.db.productCategories, link)
+		}
+	}
+
+	c.db.addEvents(event)
+
+	return nil
+}
+
+func (c *Categories) SetCategory(_ context.Context, id string, name string) (models.CategoryDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	cat, ok := c.db.categories[id]
+	if !ok {
+		return models.CategoryDto{}, models.ErrNotFound
+	}
+
+	if c.nameTaken(id, cat.ParentID, name) {
+		return models.CategoryDto{}, models.ErrUnique
+	}
+
+	oldName := cat.Name
+
+	cat.Name = name
+	cat.Updated = now()
+	c.db.categories[id] = cat
+
+	if name != oldName {
+		event, err := models.NewEvent(models.EventCategoryRenamed, id,
+			models.EventRenameData{ID: id, OldName: oldName, Name: name})
+		if err != nil {
+			return models.CategoryDto{}, err
+		}
+
+		c.db.addEvents(event)
+	}
+
+	return toCategoryDto(cat), nil
+}
+
+// GetCategorySubtree returns the category and all of its descendants, parents before children.
+func (c *Categories) GetCategorySubtree(_ context.Context, id string) ([]models.CategoryDto, error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	root, ok := c.db.categories[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+
+	var subtree []models.Category
+
+	// Walk the tree a level at a time; each level is ordered by name like the ORDER BY depth, name, id.
+	for level := []models.Category{root}; len(level) > 0; {
+		slices.SortFunc(level, compareCategories)
+		subtree = append(subtree, level...)
+
+		var next []models.Category
+		for _, cat := range level {
+			next = append(next, c.children(cat.ID)...)
+		}
+
+		level = next
+	}
+
+	return toCategoryDtos(subtree), nil
+}
+
+// GetCategoryAncestors returns the breadcrumb from the root category down to and including the category.
+func (c *Categories) GetCategoryAncestors(_ context.Context, id string) ([]models.CategoryDto, error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	cat, ok := c.db.categories[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+
+	ancestors := []models.Category{cat}
+	for cat.ParentID != nil {
+		cat = c.db.categories[*cat.ParentID]
+		ancestors = append(ancestors, cat)
+	}
+
+	slices.Reverse(ancestors)
+
+	return toCategoryDtos(ancestors), nil
+}
+
+// MoveCategory reparents the category. The write lock makes the cycle check and the update atomic.
+func (c *Categories) MoveCategory(_ context.Context, id string, parentID *string) (models.CategoryDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if parentID != nil {
+		if err := c.checkCycle(id, *parentID); err != nil {
+			return models.CategoryDto{}, err
+		}
+	}
+
+	cat, ok := c.db.categories[id]
+	if !ok {
+		return models.CategoryDto{}, models.ErrNotFound
+	}
+
+	if c.nameTaken(id, parentID, cat.Name) {
+		return models.CategoryDto{}, models.ErrUnique
+	}
+
+	oldParentID := cat.ParentID
+
+	cat.ParentID = copyString(parentID)
+	cat.Updated = now()
+	c.db.categories[id] = cat
+
+	if parentKey(oldParentID) != parentKey(parentID) {
+		event, err := models.NewEvent(models.EventCategoryMoved, id,
+			models.EventMoveData{ID: id, OldParentID: oldParentID, ParentID: copyString(parentID)})
+		if err != nil {
+			return models.CategoryDto{}, err
+		}
+
+		c.db.addEvents(event)
+	}
+
+	return toCategoryDto(cat), nil
+}
+
+// checkCycle walks up from the new parent and fails if it meets the category being moved.
+func (c *Categories) checkCycle(id string, parentID string) error {
+	if _, ok := c.db.categories[parentID]; !ok {
+		return fmt.Errorf("parent category %w", models.ErrNotFound)
+	}
+
+	for ancestor := &parentID; ancestor != nil; ancestor = c.db.categories[*ancestor].ParentID {
+		if *ancestor == id {
+			return fmt.Errorf("%w: category cannot be moved under itself or its descendant", models.ErrConflict)
+		}
+	}
+
+	return nil
+}
+
+func (c *Categories) AssignProduct(_ context.Context, categoryID string, productID string) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	return c.assignProduct(categoryID, productID)
+}
+
+// AddCategoryForProduct assigns the product to the top-level category called name, creating the category
+// unless it exists. Nothing is created for a product that doesn't exist.
+func (c *Categories) AddCategoryForProduct(_ context.Context, name string,
+	productID string) (models.CategoryDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if _, ok := c.db.products[productID]; !ok {
+		return models.CategoryDto{}, fmt.Errorf("product %w", models.ErrNotFound)
+	}
+
+	res, ok := c.rootCategory(name)
+	if !ok {
+		var err error
+		if res, err = c.addCategory(models.CategoryDto{Name: name}); err != nil {
+			return models.CategoryDto{}, err
+		}
+	}
+
+	if err := c.assignProduct(res.ID, productID); err != nil {
+		return models.CategoryDto{}, err
+	}
+
+	return res, nil
+}
+
+func (c *Categories) rootCategory(name string) (models.CategoryDto, bool) {
+	for _, cat := range c.db.categories {
+		if cat.ParentID == nil && cat.Name == name {
+			return toCategoryDto(cat), true
+		}
+	}
+
+	return models.CategoryDto{}, false
+}
+
+// assignProduct links the product to the category; the caller holds the write lock.
+func (c *Categories) assignProduct(categoryID string, productID string) error {
+	if _, ok := c.db.products[productID]; !ok {
+		return fmt.Errorf("product %w", models.ErrNotFound)
+	}
+
+	if _, ok := c.db.categories[categoryID]; !ok {
+		return fmt.Errorf("category %w", models.ErrNotFound)
+	}
+
+	link := productCategory{productID: productID, categoryID: categoryID}
+	if _, ok := c.db.productCategories[link]; ok {
+		return nil
+	}
+
+	event, err := assignmentEvent(models.EventProductAssigned, link)
+	if err != nil {
+		return err
+	}
+
+	c.db.productCategories[link] = struct{}{}
+	c.db.addEvents(event)
+
+	return nil
+}
+
+func (c *Categories) UnassignProduct(_ context.Context, categoryID string, productID string) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	link := productCategory{productID: productID, categoryID: categoryID}
+	if _, ok := c.db.productCategories[link]; !ok {
+		return models.ErrNotFound
+	}
+
+	event, err := assignmentEvent(models.EventProductUnassigned, link)
+	if err != nil {
+		return err
+	}
+
+	delete(c.db.productCategories, link)
+	c.db.addEvents(event)
+
+	return nil
+}
+
+func assignmentEvent(eventType string, link productCategory) (models.Event, error) {
+	return models.NewEvent(eventType, link.categoryID,
+		models.EventAssignmentData{CategoryID: link.categoryID, ProductID: link.productID})
+}
+
+func (c *Categories) GetProductCategories(_ context.Context, productID string) ([]models.CategoryDto, error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	if _, ok := c.db.products[productID]; !ok {
+		return nil, fmt.Errorf("product %w", models.ErrNotFound)
+	}
+
+	categories := make([]models.Category, 0)
+
+	for link := range c.db.productCategories {
+		if link.productID == productID {
+			categories = append(categories, c.db.categories[link.categoryID])
+		}
+	}
+
+	slices.SortFunc(categories, compareCategories)
+
+	return toCategoryDtos(categories), nil
+}
+
+func (c *Categories) children(id string) []models.Category {
+	var res []models.Category
+
+	for _, cat := range c.db.categories {
+		if cat.ParentID != nil && *cat.ParentID == id {
+			res = append(res, cat)
+		}
+	}
+
+	return res
+}
+
+// nameTaken reports whether a category other than id already has name under parentID. Like the
+// categories_parent_name_key index, it treats root categories as children of parent "0".
+func (c *Categories) nameTaken(id string, parentID *string, name string) bool {
+	parent := parentKey(parentID)
+
+	for _, cat := range c.db.categories {
+		if cat.ID != id && cat.Name == name && parentKey(cat.ParentID) == parent {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parentKey(parentID *string) string {
+	if parentID == nil {
+		return "0"
+	}
+
+	return *parentID
+}
+
+func compareCategories(a models.Category, b models.Category) int {
+	if res := cmp.Compare(a.Name, b.Name); res != 0 {
+		return res
+	}
+
+	return compareIDs(a.ID, b.ID)
+}
+
+func categoryRow(cat models.Category) row {
+	return row{id: cat.ID, name: cat.Name, created: cat.Created, updated: cat.Updated}
+}
+
+func toCategoryDtos(categories []models.Category) []models.CategoryDto {
+	res := make([]models.CategoryDto, 0, len(categories))
+	for _, cat := range categories {
+		res = append(res, toCategoryDto(cat))
+	}
+
+	return res
+}
+
+func toCategoryDto(cat models.Category) models.CategoryDto {
+	return models.CategoryDto{
+		ID:       cat.ID,
+		Name:     cat.Name,
+		ParentID: copyString(cat.ParentID),
+		Created:  cat.Created,
+		Updated:  cat.Updated,
+	}
+}
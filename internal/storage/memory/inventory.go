@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"tradeservice/internal/models"
+)
+
+type Inventory struct {
+	db *Storage
+}
+
+func NewInventory(db *Storage) *Inventory {
+	return &Inventory{
+		db: db,
+	}
+}
+
+func (c *Inventory) GetStockLevel(_ context.Context, productID string) (models.StockLevelDto, error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	prod, ok := c.db.products[productID]
+	if !ok {
+		return models.StockLevelDto{}, fmt.Errorf("product %w", models.ErrNotFound)
+	}
+
+	level, ok := c.db.levels[productID]
+	if !ok {
+		level = models.StockLevel{ProductID: productID, Updated: prod.Created}
+	}
+
+	return toStockLevelDto(level), nil
+}
+
+// RecordMovement applies movement.Quantity to the on-hand stock and appends it to the ledger. A movement
+// that would take on-hand stock below the reserved quantity is rejected.
+func (c *Inventory) RecordMovement(_ context.Context, movement models.StockMovementDto) (models.StockMovementDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	level, err := c.stockLevel(movement.ProductID)
+	if err != nil {
+		return models.StockMovementDto{}, err
+	}
+
+	released := movement.Released()
+	if level.Reserved < released || level.OnHand+movement.Quantity < level.Reserved-released {
+		return models.StockMovementDto{}, fmt.Errorf("%w: insufficient stock", models.ErrConflict)
+	}
+
+	level.OnHand += movement.Quantity
+	level.Reserved -= released
+	level.Updated = now()
+	c.db.levels[level.ProductID] = level
+
+	recorded := models.StockMovement{
+		ID:          c.db.movementIDs.next(),
+		ProductID:   movement.ProductID,
+		Type:        movement.Type,
+		Quantity:    movement.Quantity,
+		Reason:      movement.Reason,
+		OnHandAfter: level.OnHand,
+		Created:     level.Updated,
+	}
+
+	c.db.movements[recorded.ID] = recorded
+
+	return toStockMovementDto(recorded), nil
+}
+
+func (c *Inventory) GetStockMovements(_ context.Context, productID string,
+	params models.ListParams) (models.Page[models.StockMovementDto], error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	if _, ok := c.db.products[productID]; !ok {
+		return models.Page[models.StockMovementDto]{}, fmt.Errorf("product %w", models.ErrNotFound)
+	}
+
+	movements := slices.DeleteFunc(slices.Collect(maps.Values(c.db.movements)), func(movement models.StockMovement) bool {
+		return movement.ProductID != productID
+	})
+
+	page, err := list(movements, params, func(movement models.StockMovement) row {
+		return row{id: movement.ID, created: movement.Created, updated: movement.Created}
+	})
+	if err != nil {
+		return models.Page[models.StockMovementDto]{}, err
+	}
+
+	items := make([]models.StockMovementDto, 0, len(page.Items))
+	for _, movement := range page.Items {
+		items = append(items, toStockMovementDto(movement))
+	}
+
+	return models.Page[models.StockMovementDto]{Items: items, NextCursor: page.NextCursor}, nil
+}
+
+func (c *Inventory) Reserve(_ context.Context, productID string, quantity int64) (models.StockLevelDto, error) {
+	return c.updateReserved(productID, func(level models.StockLevel) bool {
+		return level.OnHand-level.Reserved >= quantity
+	}, quantity)
+}
+
+func (c *Inventory) Release(_ context.Context, productID string, quantity int64) (models.StockLevelDto, error) {
+	return c.updateReserved(productID, func(level models.StockLevel) bool {
+		return level.Reserved >= quantity
+	}, -quantity)
+}
+
+// updateReserved adds delta to the reserved quantity when allowed accepts the current level.
+func (c *Inventory) updateReserved(productID string, allowed func(models.StockLevel) bool,
+	delta int64) (models.StockLevelDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	level, err := c.stockLevel(productID)
+	if err != nil {
+		return models.StockLevelDto{}, err
+	}
+
+	if !allowed(level) {
+		return models.StockLevelDto{}, fmt.Errorf("%w: insufficient stock", models.ErrConflict)
+	}
+
+	level.Reserved += delta
+	level.Updated = now()
+	c.db.levels[productID] = level
+
+	return toStockLevelDto(level), nil
+}
+
+// stockLevel returns the stock level of a product, a zero one before its first movement.
+func (c *Inventory) stockLevel(productID string) (models.StockLevel, error) {
+	if _, ok := c.db.products[productID]; !ok {
+		return models.StockLevel{}, fmt.Errorf("product %w", models.ErrNotFound)
+	}
+
+	level, ok := c.db.levels[productID]
+	if !ok {
+		level = models.StockLevel{ProductID: productID, Updated: now()}
+	}
+
+	return level, nil
+}
+
+func toStockLevelDto(level models.StockLevel) models.StockLevelDto {
+	return models.StockLevelDto{
+		ProductID: level.ProductID,
+		OnHand:    level.OnHand,
+		Reserved:  level.Reserved,
+		Available: level.OnHand - level.Reserved,
+		Updated:   level.Updated,
+	}
+}
+
+func toStockMovementDto(movement models.StockMovement) models.StockMovementDto {
+	return models.StockMovementDto{
+		ID:          movement.ID,
+		ProductID:   movement.ProductID,
+		Type:        movement.Type,
+		Quantity:    movement.Quantity,
+		Reason:      movement.Reason,
+		OnHandAfter: movement.OnHandAfter,
+		Created:     movement.Created,
+	}
+}
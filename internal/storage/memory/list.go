@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"tradeservice/internal/models"
+)
+
+// row holds the columns a list is filtered, sorted and paginated by.
+type row struct {
+	id      string
+	name    string
+	created time.Time
+	updated time.Time
+}
+
+// compare orders rows by the sort column and then by ID, like the ORDER BY of the postgres backend.
+// Names compare bytewise, as under the C collation.
+func (r row) compare(other row, sort string) int {
+	var res int
+
+	switch sort {
+	case models.SortByName:
+		res = cmp.Compare(r.name, other.name)
+	case models.SortByUpdatedAt:
+		res = r.updated.Compare(other.updated)
+	default:
+		res = r.created.Compare(other.created)
+	}
+
+	if res != 0 {
+		return res
+	}
+
+	return compareIDs(r.id, other.id)
+}
+
+// list returns the page of items selected by params with the same keyset pagination as the postgres backend.
+func list[T any](items []T, params models.ListParams, key func(T) row) (models.Page[T], error) {
+	switch params.Sort {
+	case models.SortByName, models.SortByCreatedAt, models.SortByUpdatedAt:
+	default:
+		return models.Page[T]{}, fmt.Errorf("%w: unsupported sort %q", models.ErrValidation, params.Sort)
+	}
+
+	var after *row
+
+	if params.After != nil {
+		cursor, err := cursorRow(params.Sort, *params.After)
+		if err != nil {
+			return models.Page[T]{}, err
+		}
+
+		after = &cursor
+	}
+
+	direction := 1
+	if params.Desc {
+		direction = -1
+	}
+
+	selected := make([]T, 0, min(len(items), params.Limit+1))
+
+	for _, item := range items {
+		r := key(item)
+
+		if params.NamePrefix != "" && !strings.HasPrefix(r.name, params.NamePrefix) {
+			continue
+		}
+
+		if params.CreatedAfter != nil && !r.created.After(*params.CreatedAfter) {
+			continue
+		}
+
+		if after != nil && r.compare(*after, params.Sort)*direction <= 0 {
+			continue
+		}
+
+		selected = append(selected, item)
+	}
+
+	slices.SortFunc(selected, func(a T, b T) int {
+		return key(a).compare(key(b), params.Sort) * direction
+	})
+
+	if len(selected) <= params.Limit {
+		return models.Page[T]{Items: selected}, nil
+	}
+
+	selected = selected[:params.Limit]
+	last := key(selected[len(selected)-1])
+	cursor := models.Cursor{Sort: params.Sort, Desc: params.Desc, Value: sortValue(params.Sort, last), ID: last.id}
+
+	return models.Page[T]{Items: selected, NextCursor: models.EncodeCursor(cursor)}, nil
+}
+
+func sortValue(sort string, r row) string {
+	switch sort {
+	case models.SortByName:
+		return r.name
+	case models.SortByUpdatedAt:
+		return r.updated.Format(time.RFC3339Nano)
+	default:
+		return r.created.Format(time.RFC3339Nano)
+	}
+}
+
+// cursorRow turns a cursor into the row it points at; only the sort column and the ID are set.
+func cursorRow(sort string, cursor models.Cursor) (row, error) {
+	res := row{id: cursor.ID}
+
+	if sort == models.SortByName {
+		res.name = cursor.Value
+
+		return res, nil
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, cursor.Value)
+	if err != nil {
+		return row{}, fmt.Errorf("%w: malformed cursor", models.ErrValidation)
+	}
+
+	res.created, res.updated = ts, ts
+
+	return res, nil
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"cmp"
+	"strconv"
+	"sync"
+	"time"
+	"tradeservice/internal/models"
+)
+
+// Storage keeps every entity in maps guarded by one lock, so a change that touches several of them, e.g. a
+// cascading delete, is atomic like a transaction of the postgres backend. The data is lost when the process exits.
+type Storage struct {
+	mu sync.RWMutex
+
+	categories        map[string]models.Category
+	products          map[string]models.Product
+	productCategories map[productCategory]struct{}
+	levels            map[string]models.StockLevel
+	movements         map[string]models.StockMovement
+	orders            map[string]models.Order
+	orderLines        map[string][]models.OrderLineDto
+	apiKeys           map[string]models.APIKey
+	outbox            map[string]outboxEntry
+	webhooks          map[string]webhook
+	deliveries        map[string]webhookDelivery
+
+	categoryIDs sequence
+	productIDs  sequence
+	movementIDs sequence
+	orderIDs    sequence
+	apiKeyIDs   sequence
+	eventIDs    sequence
+	webhookIDs  sequence
+	deliveryIDs sequence
+}
+
+type productCategory struct {
+	productID  string
+	categoryID string
+}
+
+func New() *Storage {
+	return &Storage{
+		categories:        make(map[string]models.Category),
+		products:          make(map[string]models.Product),
+		productCategories: make(map[productCategory]struct{}),
+		levels:            make(map[string]models.StockLevel),
+		movements:         make(map[string]models.StockMovement),
+		orders:            make(map[string]models.Order),
+		orderLines:        make(map[string][]models.OrderLineDto),
+		apiKeys:           make(map[string]models.APIKey),
+		outbox:            make(map[string]outboxEntry),
+		webhooks:          make(map[string]webhook),
+		deliveries:        make(map[string]webhookDelivery),
+	}
+}
+
+// Close exists for parity with the postgres backend; there is nothing to release.
+func (store *Storage) Close() {}
+
+// sequence hands out IDs like a serial column: 1, 2, 3, ...
+type sequence int64
+
+func (s *sequence) next() string {
+	*s++
+
+	return strconv.FormatInt(int64(*s), 10)
+}
+
+// now is truncated to the microsecond precision of timestamptz.
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+// compareIDs orders the decimal IDs handed out by sequence numerically.
+func compareIDs(a string, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+
+	return cmp.Compare(a, b)
+}
+
+func copyString(value *string) *string {
+	if value == nil {
+		return nil
+	}
+
+	res := *value
+
+	return &res
+}
+
+func copyTime(value *time.Time) *time.Time {
+	if value == nil {
+		return nil
+	}
+
+	res := *value
+
+	return &res
+}
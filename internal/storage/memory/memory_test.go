@@ -0,0 +1,70 @@
+package memory_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"tradeservice/internal/models"
+	"tradeservice/internal/storage"
+	"tradeservice/internal/storage/memory"
+	"tradeservice/internal/storage/storagetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	storagetest.Run(t, func(*testing.T) storage.Repositories {
+		db := memory.New()
+
+		return storage.Repositories{
+			Categories: memory.NewCategories(db),
+			Products:   memory.NewProducts(db),
+			Inventory:  memory.NewInventory(db),
+			Orders:     memory.NewOrders(db),
+			APIKeys:    memory.NewAPIKeys(db),
+			Outbox:     memory.NewOutbox(db),
+			Webhooks:   memory.NewWebhooks(db),
+		}
+	})
+}
+
+func TestInventory_ConcurrentReservations(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := memory.New()
+	inventory := memory.NewInventory(db)
+
+	product, err := memory.NewProducts(db).AddProduct(ctx, models.ProductDto{Name: "bolt", SKU: "B-1", Currency: "USD"})
+	require.NoError(t, err)
+
+	_, err = inventory.RecordMovement(ctx, models.StockMovementDto{
+		ProductID: product.ID, Type: models.MovementReceipt, Quantity: 20,
+	})
+	require.NoError(t, err)
+
+	var (
+		wg       sync.WaitGroup
+		reserved atomic.Int64
+	)
+
+	for range 50 {
+		wg.Go(func() {
+			if _, err := inventory.Reserve(ctx, product.ID, 1); err == nil {
+				reserved.Add(1)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	level, err := inventory.GetStockLevel(ctx, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), reserved.Load())
+	assert.Equal(t, int64(20), level.Reserved)
+	assert.Zero(t, level.Available)
+}
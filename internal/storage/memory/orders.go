@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"tradeservice/internal/models"
+)
+
+type Orders struct {
+	db *Storage
+}
+
+func NewOrders(db *Storage) *Orders {
+	return &Orders{
+		db: db,
+	}
+}
+
+// AddOrder stores an already priced order together with its lines.
+func (c *Orders) AddOrder(_ context.Context, order models.OrderDto) (models.OrderDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	for _, line := range order.Lines {
+		if _, ok := c.db.products[line.ProductID]; !ok {
+			return models.OrderDto{}, fmt.Errorf("product %w", models.ErrNotFound)
+		}
+	}
+
+	created := now()
+	ord := models.Order{
+		ID:       c.db.orderIDs.next(),
+		Status:   order.Status,
+		Currency: order.Currency,
+		Total:    order.Total,
+		Created:  created,
+		Updated:  created,
+	}
+
+	c.db.orders[ord.ID] = ord
+	c.db.orderLines[ord.ID] = slices.Clone(order.Lines)
+
+	res := toOrderDto(ord)
+	res.Lines = order.Lines
+
+	return res, nil
+}
+
+func (c *Orders) GetOrder(_ context.Context, params models.ListParams) (models.Page[models.OrderDto], error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	page, err := list(slices.Collect(maps.Values(c.db.orders)), params, func(ord models.Order) row {
+		return row{id: ord.ID, created: ord.Created, updated: ord.Updated}
+	})
+	if err != nil {
+		return models.Page[models.OrderDto]{}, err
+	}
+
+	items := make([]models.OrderDto, 0, len(page.Items))
+	for _, ord := range page.Items {
+		items = append(items, toOrderDto(ord))
+	}
+
+	return models.Page[models.OrderDto]{Items: items, NextCursor: page.NextCursor}, nil
+}
+
+func (c *Orders) GetOrderByID(_ context.Context, id string) (models.OrderDto, error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	ord, ok := c.db.orders[id]
+	if !ok {
+		return models.OrderDto{}, models.ErrNotFound
+	}
+
+	return c.withLines(ord), nil
+}
+
+// SetOrderStatus moves the order from status "from" to "to". It fails with models.ErrConflict when the
+// order is no longer in "from", so a transition validated by the caller cannot race with another one.
+func (c *Orders) SetOrderStatus(_ context.Context, id string, from string, to string) (models.OrderDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	ord, ok := c.db.orders[id]
+	if !ok {
+		return models.OrderDto{}, models.ErrNotFound
+	}
+
+	if ord.Status != from {
+		return models.OrderDto{}, fmt.Errorf("%w: order is no longer %s", models.ErrConflict, from)
+	}
+
+	ord.Status = to
+	ord.Updated = now()
+	c.db.orders[id] = ord
+
+	return c.withLines(ord), nil
+}
+
+func (c *Orders) withLines(ord models.Order) models.OrderDto {
+	res := toOrderDto(ord)
+
+	if lines := c.db.orderLines[ord.ID]; len(lines) > 0 {
+		res.Lines = slices.Clone(lines)
+	}
+
+	return res
+}
+
+func toOrderDto(ord models.Order) models.OrderDto {
+	return models.OrderDto{
+		ID:       ord.ID,
+		Status:   ord.Status,
+		Currency: ord.Currency,
+		Total:    ord.Total,
+		Created:  ord.Created,
+		Updated:  ord.Updated,
+	}
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+	"tradeservice/internal/models"
+)
+
+type outboxEntry struct {
+	event       models.OutboxEvent
+	nextAttempt time.Time
+	delivered   *time.Time
+}
+
+type Outbox struct {
+	db *Storage
+}
+
+func NewOutbox(db *Storage) *Outbox {
+	return &Outbox{
+		db: db,
+	}
+}
+
+func (c *Outbox) ClaimEvents(_ context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	claimed := now()
+
+	var due []models.OutboxEvent
+
+	for _, entry := range c.db.outbox {
+		if entry.delivered == nil && !entry.nextAttempt.After(claimed) {
+			due = append(due, entry.event)
+		}
+	}
+
+	slices.SortFunc(due, func(a models.OutboxEvent, b models.OutboxEvent) int {
+		return compareIDs(a.ID, b.ID)
+	})
+
+	due = due[:min(limit, len(due))]
+
+	for _, event := range due {
+		entry := c.db.outbox[event.ID]
+		entry.nextAttempt = claimed.Add(lease)
+		c.db.outbox[event.ID] = entry
+	}
+
+	return append(make([]models.OutboxEvent, 0, len(due)), due...), nil
+}
+
+func (c *Outbox) MarkEventDelivered(_ context.Context, id string) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	entry, ok := c.db.outbox[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+
+	delivered := now()
+	entry.delivered = &delivered
+	c.db.outbox[id] = entry
+
+	return nil
+}
+
+func (c *Outbox) MarkEventFailed(_ context.Context, id string, retryAt time.Time, reason string) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	entry, ok := c.db.outbox[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+
+	entry.event.Attempts++
+	entry.event.LastError = reason
+	entry.nextAttempt = retryAt
+	c.db.outbox[id] = entry
+
+	return nil
+}
+
+func (c *Outbox) DeleteDeliveredEvents(_ context.Context, before time.Time) (int64, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	var deleted int64
+
+	for id, entry := range c.db.outbox {
+		if entry.delivered != nil && entry.delivered.Before(before) {
+			delete(c.db.outbox, id)
+
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// addEvents records the events of a write; the caller holds the write lock, which makes them atomic with it.
+func (store *Storage) addEvents(events ...models.Event) {
+	created := now()
+
+	for _, event := range events {
+		event.ID = store.eventIDs.next()
+		event.Created = created
+
+		store.outbox[event.ID] = outboxEntry{event: models.OutboxEvent{Event: event}, nextAttempt: created}
+	}
+}
@@ -0,0 +1,277 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"tradeservice/internal/models"
+)
+
+type Products struct {
+	db *Storage
+}
+
+func NewProducts(db *Storage) *Products {
+	return &Products{
+		db: db,
+	}
+}
+
+func (c *Products) GetProduct(_ context.Context, params models.ListParams) (models.Page[models.ProductDto], error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	if _, ok := c.db.categories[params.CategoryID]; params.CategoryID != "" && !ok {
+		return models.Page[models.ProductDto]{}, fmt.Errorf("category %w", models.ErrNotFound)
+	}
+
+	products := make([]models.Product, 0, len(c.db.products))
+
+	categories := c.filterCategories(params)
+	for _, prod := range c.db.products {
+		if categories == nil || c.inCategories(prod.ID, categories) {
+			products = append(products, prod)
+		}
+	}
+
+	page, err := list(products, params, func(prod models.Product) row {
+		return row{id: prod.ID, name: prod.Name, created: prod.Created, updated: prod.Updated}
+	})
+	if err != nil {
+		return models.Page[models.ProductDto]{}, err
+	}
+
+	items := make([]models.ProductDto, 0, len(page.Items))
+	for _, prod := range page.Items {
+		items = append(items, toProductDto(prod))
+	}
+
+	return models.Page[models.ProductDto]{Items: items, NextCursor: page.NextCursor}, nil
+}
+
+// filterCategories returns the categories a listed product must belong to, or nil when params has no
+// category filter.
+func (c *Products) filterCategories(params models.ListParams) map[string]bool {
+	if params.CategoryID == "" {
+		return nil
+	}
+
+	res := map[string]bool{params.CategoryID: true}
+	if !params.IncludeSubcategories {
+		return res
+	}
+
+	for level := []string{params.CategoryID}; len(level) > 0; {
+		var next []string
+
+		for _, cat := range c.db.categories {
+			if cat.ParentID != nil && slices.Contains(level, *cat.ParentID) {
+				res[cat.ID] = true
+				next = append(next, cat.ID)
+			}
+		}
+
+		level = next
+	}
+
+	return res
+}
+
+func (c *Products) inCategories(productID string, categories map[string]bool) bool {
+	for link := range c.db.productCategories {
+		if link.productID == productID && categories[link.categoryID] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Products) GetProductByID(_ context.Context, id string) (models.ProductDto, error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	prod, ok := c.db.products[id]
+	if !ok {
+		return models.ProductDto{}, models.ErrNotFound
+	}
+
+	return toProductDto(prod), nil
+}
+
+func (c *Products) AddProduct(_ context.Context, product models.ProductDto) (models.ProductDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if c.skuTaken("", product.SKU) {
+		return models.ProductDto{}, models.ErrUnique
+	}
+
+	created := now()
+	prod := models.Product{
+		ID:          c.db.productIDs.next(),
+		Name:        product.Name,
+		SKU:         product.SKU,
+		Description: product.Description,
+		UnitPrice:   product.UnitPrice,
+		Currency:    product.Currency,
+		Active:      product.Active,
+		Created:     created,
+		Updated:     created,
+	}
+
+	res := toProductDto(prod)
+
+	event, err := models.NewEvent(models.EventProductCreated, res.ID, res)
+	if err != nil {
+		return models.ProductDto{}, err
+	}
+
+	c.db.products[prod.ID] = prod
+	c.db.addEvents(event)
+
+	return res, nil
+}
+
+// DeleteProduct removes the product together with its stock and category assignments, but not while
+// an order refers to it.
+func (c *Products) DeleteProduct(_ context.Context, id string) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if _, ok := c.db.products[id]; !ok {
+		return models.ErrNotFound
+	}
+
+	for _, lines := range c.db.orderLines {
+		for _, line := range lines {
+			if line.ProductID == id {
+				return fmt.Errorf("%w: product is referenced by orders", models.ErrConflict)
+			}
+		}
+	}
+
+	event, err := models.NewEvent(models.EventProductDeleted, id, models.EventDeleteData{ID: id})
+	if err != nil {
+		return err
+	}
+
+	delete(c.db.products, id)
+	delete(c.db.levels, id)
+
+	for movementID, movement := range c.db.movements {
+		if movement.ProductID == id {
+			delete(c.db.movements, movementID)
+		}
+	}
+
+	for link := range c.db.productCategories {
+		if link.productID == id {
+			delete(c.db.productCategories, link)
+		}
+	}
+
+	c.db.addEvents(event)
+
+	return nil
+}
+
+func (c *Products) SetProduct(_ context.Context, id string, product models.ProductDto) (models.ProductDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	prod, ok := c.db.products[id]
+	if !ok {
+		return models.ProductDto{}, models.ErrNotFound
+	}
+
+	prod.Name = product.Name
+	prod.SKU = product.SKU
+	prod.Description = product.Description
+	prod.UnitPrice = product.UnitPrice
+	prod.Currency = product.Currency
+	prod.Active = product.Active
+
+	return c.update(prod)
+}
+
+func (c *Products) PatchProduct(_ context.Context, id string, patch models.ProductPatch) (models.ProductDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	prod, ok := c.db.products[id]
+	if !ok {
+		return models.ProductDto{}, models.ErrNotFound
+	}
+
+	if patch.Name != nil {
+		prod.Name = *patch.Name
+	}
+
+	if patch.SKU != nil {
+		prod.SKU = *patch.SKU
+	}
+
+	if patch.Description != nil {
+		prod.Description = *patch.Description
+	}
+
+	if patch.UnitPrice != nil {
+		prod.UnitPrice = *patch.UnitPrice
+	}
+
+	if patch.Currency != nil {
+		prod.Currency = *patch.Currency
+	}
+
+	if patch.Active != nil {
+		prod.Active = *patch.Active
+	}
+
+	return c.update(prod)
+}
+
+// update stores the changed prod and records the events of the change.
+func (c *Products) update(prod models.Product) (models.ProductDto, error) {
+	if c.skuTaken(prod.ID, prod.SKU) {
+		return models.ProductDto{}, models.ErrUnique
+	}
+
+	prod.Updated = now()
+	res := toProductDto(prod)
+
+	events, err := models.ProductUpdateEvents(res, c.db.products[prod.ID].Name)
+	if err != nil {
+		return models.ProductDto{}, err
+	}
+
+	c.db.products[prod.ID] = prod
+	c.db.addEvents(events...)
+
+	return res, nil
+}
+
+// skuTaken reports whether a product other than id already has sku.
+func (c *Products) skuTaken(id string, sku string) bool {
+	for _, prod := range c.db.products {
+		if prod.ID != id && prod.SKU == sku {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toProductDto(prod models.Product) models.ProductDto {
+	return models.ProductDto{
+		ID:          prod.ID,
+		Name:        prod.Name,
+		SKU:         prod.SKU,
+		Description: prod.Description,
+		UnitPrice:   prod.UnitPrice,
+		Currency:    prod.Currency,
+		Active:      prod.Active,
+		Created:     prod.Created,
+		Updated:     prod.Updated,
+	}
+}
@@ -0,0 +1,303 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+	"tradeservice/internal/models"
+)
+
+type webhook struct {
+	dto    models.WebhookDto
+	secret string
+}
+
+type webhookDelivery struct {
+	dto         models.WebhookDeliveryDto
+	nextAttempt time.Time
+	payload     []byte
+	history     []models.WebhookAttemptDto
+}
+
+type Webhooks struct {
+	db *Storage
+}
+
+func NewWebhooks(db *Storage) *Webhooks {
+	return &Webhooks{
+		db: db,
+	}
+}
+
+func (c *Webhooks) AddWebhook(_ context.Context, dto models.WebhookDto, secret string) (models.WebhookDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	created := now()
+	res := models.WebhookDto{
+		ID:         c.db.webhookIDs.next(),
+		URL:        dto.URL,
+		EventTypes: slices.Clone(dto.EventTypes),
+		Active:     dto.Active,
+		Created:    created,
+		Updated:    created,
+	}
+
+	c.db.webhooks[res.ID] = webhook{dto: res, secret: secret}
+
+	return copyWebhook(res), nil
+}
+
+func (c *Webhooks) GetWebhooks(_ context.Context) ([]models.WebhookDto, error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	webhooks := make([]models.WebhookDto, 0, len(c.db.webhooks))
+
+	for _, id := range slices.SortedFunc(maps.Keys(c.db.webhooks), compareIDs) {
+		webhooks = append(webhooks, copyWebhook(c.db.webhooks[id].dto))
+	}
+
+	return webhooks, nil
+}
+
+func (c *Webhooks) GetWebhookByID(_ context.Context, id string) (models.WebhookDto, error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	stored, ok := c.db.webhooks[id]
+	if !ok {
+		return models.WebhookDto{}, fmt.Errorf("webhook %w", models.ErrNotFound)
+	}
+
+	return copyWebhook(stored.dto), nil
+}
+
+func (c *Webhooks) SetWebhook(_ context.Context, id string, dto models.WebhookDto) (models.WebhookDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	stored, ok := c.db.webhooks[id]
+	if !ok {
+		return models.WebhookDto{}, fmt.Errorf("webhook %w", models.ErrNotFound)
+	}
+
+	stored.dto.URL = dto.URL
+	stored.dto.EventTypes = slices.Clone(dto.EventTypes)
+	stored.dto.Active = dto.Active
+	stored.dto.Updated = now()
+	c.db.webhooks[id] = stored
+
+	return copyWebhook(stored.dto), nil
+}
+
+func (c *Webhooks) DeleteWebhook(_ context.Context, id string) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if _, ok := c.db.webhooks[id]; !ok {
+		return fmt.Errorf("webhook %w", models.ErrNotFound)
+	}
+
+	delete(c.db.webhooks, id)
+
+	for deliveryID, delivery := range c.db.deliveries {
+		if delivery.dto.WebhookID == id {
+			delete(c.db.deliveries, deliveryID)
+		}
+	}
+
+	return nil
+}
+
+func (c *Webhooks) AddWebhookDeliveries(_ context.Context, event models.Event, payload []byte) (int64, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	queued := make(map[string]bool)
+
+	for _, delivery := range c.db.deliveries {
+		if delivery.dto.EventID == event.ID {
+			queued[delivery.dto.WebhookID] = true
+		}
+	}
+
+	created := now()
+
+	var added int64
+
+	for _, id := range slices.SortedFunc(maps.Keys(c.db.webhooks), compareIDs) {
+		stored := c.db.webhooks[id]
+		if !stored.dto.Active || !slices.Contains(stored.dto.EventTypes, event.Type) || queued[id] {
+			continue
+		}
+
+		delivery := models.WebhookDeliveryDto{
+			ID:        c.db.deliveryIDs.next(),
+			WebhookID: id,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Status:    models.DeliveryPending,
+			Created:   created,
+			Updated:   created,
+		}
+
+		c.db.deliveries[delivery.ID] = webhookDelivery{dto: delivery, nextAttempt: created, payload: bytes.Clone(payload)}
+		added++
+	}
+
+	return added, nil
+}
+
+func (c *Webhooks) GetWebhookDeliveries(_ context.Context, webhookID string,
+	params models.ListParams) (models.Page[models.WebhookDeliveryDto], error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	deliveries := make([]models.WebhookDeliveryDto, 0)
+
+	for _, delivery := range c.db.deliveries {
+		if delivery.dto.WebhookID == webhookID {
+			deliveries = append(deliveries, toDeliveryDto(delivery))
+		}
+	}
+
+	return list(deliveries, params, func(delivery models.WebhookDeliveryDto) row {
+		return row{id: delivery.ID, created: delivery.Created, updated: delivery.Updated}
+	})
+}
+
+func (c *Webhooks) GetWebhookDelivery(_ context.Context, webhookID string,
+	id string) (models.WebhookDeliveryDto, error) {
+	c.db.mu.RLock()
+	defer c.db.mu.RUnlock()
+
+	delivery, ok := c.db.deliveries[id]
+	if !ok || delivery.dto.WebhookID != webhookID {
+		return models.WebhookDeliveryDto{}, fmt.Errorf("delivery %w", models.ErrNotFound)
+	}
+
+	res := toDeliveryDto(delivery)
+	res.History = append(make([]models.WebhookAttemptDto, 0, len(delivery.history)), delivery.history...)
+
+	return res, nil
+}
+
+func (c *Webhooks) RedeliverWebhookDelivery(_ context.Context, webhookID string,
+	id string) (models.WebhookDeliveryDto, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	delivery, ok := c.db.deliveries[id]
+	if !ok || delivery.dto.WebhookID != webhookID {
+		return models.WebhookDeliveryDto{}, fmt.Errorf("delivery %w", models.ErrNotFound)
+	}
+
+	redelivered := now()
+	delivery.dto.Status = models.DeliveryPending
+	delivery.dto.Updated = redelivered
+	delivery.nextAttempt = redelivered
+	c.db.deliveries[id] = delivery
+
+	return toDeliveryDto(delivery), nil
+}
+
+func (c *Webhooks) ClaimWebhookDeliveries(_ context.Context, limit int,
+	lease time.Duration) ([]models.WebhookDelivery, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	claimed := now()
+	deliveries := make([]models.WebhookDelivery, 0, limit)
+
+	for _, id := range slices.SortedFunc(maps.Keys(c.db.deliveries), compareIDs) {
+		if len(deliveries) == limit {
+			break
+		}
+
+		delivery := c.db.deliveries[id]
+		stored := c.db.webhooks[delivery.dto.WebhookID]
+
+		if delivery.dto.Status != models.DeliveryPending || delivery.nextAttempt.After(claimed) || !stored.dto.Active {
+			continue
+		}
+
+		delivery.nextAttempt = claimed.Add(lease)
+		c.db.deliveries[id] = delivery
+
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookDeliveryDto: toDeliveryDto(delivery),
+			URL:                stored.dto.URL,
+			Secret:             stored.secret,
+			Payload:            bytes.Clone(delivery.payload),
+		})
+	}
+
+	return deliveries, nil
+}
+
+func (c *Webhooks) RecordWebhookAttempt(_ context.Context, id string, attempt models.WebhookAttemptDto,
+	retryAt *time.Time) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	delivery, ok := c.db.deliveries[id]
+	if !ok {
+		return fmt.Errorf("delivery %w", models.ErrNotFound)
+	}
+
+	recorded := now()
+
+	delivery.dto.Attempts++
+	delivery.dto.LastError = attempt.Error
+	delivery.dto.Status = attempt.DeliveryStatus(retryAt)
+	delivery.dto.Updated = recorded
+
+	if retryAt != nil {
+		delivery.nextAttempt = *retryAt
+	}
+
+	attempt.Attempt = delivery.dto.Attempts
+	attempt.Created = recorded
+	delivery.history = append(slices.Clip(delivery.history), attempt)
+	c.db.deliveries[id] = delivery
+
+	return nil
+}
+
+func (c *Webhooks) DeleteWebhookDeliveries(_ context.Context, before time.Time) (int64, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	var deleted int64
+
+	for id, delivery := range c.db.deliveries {
+		if delivery.dto.Status != models.DeliveryPending && delivery.dto.Updated.Before(before) {
+			delete(c.db.deliveries, id)
+
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func copyWebhook(webhook models.WebhookDto) models.WebhookDto {
+	webhook.EventTypes = slices.Clone(webhook.EventTypes)
+
+	return webhook
+}
+
+// toDeliveryDto fills in the next attempt of a pending delivery; only a pending one has one.
+func toDeliveryDto(delivery webhookDelivery) models.WebhookDeliveryDto {
+	res := delivery.dto
+
+	if res.Status == models.DeliveryPending {
+		res.NextAttempt = copyTime(&delivery.nextAttempt)
+	}
+
+	return res
+}
@@ -1,30 +1,65 @@
 package storage
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
-	"tradeservice/internal/storage/postgres"
+	"os"
 
-	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
 )
 
-func RunMigration(db *postgres.Storage, logger *slog.Logger, path string) error {
-	logger.Info("Migrating")
+var ErrMigrationsPending = errors.New("migrations pending")
 
-	if err := goose.SetDialect(string(goose.DialectPostgres)); err != nil {
+// SQLBackend is a storage backend on a SQL database with its own goose migration set.
+type SQLBackend interface {
+	Closer
+	Ping(ctx context.Context) error
+	// Dialect is the goose dialect the migrations of the backend are written in.
+	Dialect() goose.Dialect
+	// SQLDB is a database/sql handle for goose. It is owned by the backend and closed by Close.
+	SQLDB() *sql.DB
+}
+
+func RunMigration(db SQLBackend, logger *slog.Logger, path string) error {
+	logger.Info("Migrating", "dialect", db.Dialect())
+
+	if err := goose.SetDialect(string(db.Dialect())); err != nil {
 		return fmt.Errorf("couldn't setup migration %w", err)
 	}
 
-	sqlDB := stdlib.OpenDBFromPool(db.DB)
-
-	if err := goose.Up(sqlDB, path); err != nil {
+	if err := goose.Up(db.SQLDB(), path); err != nil {
 		return fmt.Errorf("couldn't run migration %w", err)
 	}
 
-	if err := sqlDB.Close(); err != nil {
-		return fmt.Errorf("error creating migration %w", err)
+	return nil
+}
+
+// NewMigrationCheck returns a check that fails unless the DB schema is at the latest migration found in path.
+// The migrations are collected once; each check only reads the schema version.
+func NewMigrationCheck(db SQLBackend, path string) (func(ctx context.Context) error, error) {
+	provider, err := goose.NewProvider(db.Dialect(), db.SQLDB(), os.DirFS(path), goose.WithDisableGlobalRegistry(true))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't collect migrations %w", err)
+	}
+
+	var expected int64
+	if sources := provider.ListSources(); len(sources) > 0 {
+		expected = sources[len(sources)-1].Version
 	}
 
-	return nil
+	return func(ctx context.Context) error {
+		current, err := provider.GetDBVersion(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't get schema version %w", err)
+		}
+
+		if current != expected {
+			return fmt.Errorf("%w: schema is at version %d, expected %d", ErrMigrationsPending, current, expected)
+		}
+
+		return nil
+	}, nil
 }
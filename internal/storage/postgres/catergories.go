@@ -2,10 +2,20 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"tradeservice/internal/models"
+
+	"github.com/jackc/pgx/v5"
 )
 
+const categoryColumns = `id, name, parent_id, created_at, updated_at`
+
+// categoryTreeLock serialises reparenting so two concurrent moves cannot close a cycle together.
+const categoryTreeLock = 7_130_001
+
+// Categories stores categories and product assignments and records the events of their changes in the outbox.
+// Committed writes notify CatalogChannel through a trigger.
 type Categories struct {
 	db *Storage
 }
@@ -16,98 +26,460 @@ func NewCategories(db *Storage) (*Categories, error) {
 	}, nil
 }
 
-func (c *Categories) GetCategory(ctx context.Context) (categoryDto []models.CategoryDto, err error) {
-	sqlStatement := `SELECT * FROM public.categories`
+func (c *Categories) GetCategory(ctx context.Context, params models.ListParams) (models.Page[models.CategoryDto], error) {
+	query := listQuery{}
+
+	sqlStatement, err := query.build(`SELECT `+categoryColumns+` FROM public.categories`, params)
+	if err != nil {
+		return models.Page[models.CategoryDto]{}, err
+	}
 
-	rows, err := c.db.DB.Query(ctx, sqlStatement)
+	rows, err := c.db.DB.Query(ctx, sqlStatement, query.args...)
 	if err != nil {
-		return categoryDto, fmt.Errorf("failed to query DB %w", err)
+		return models.Page[models.CategoryDto]{}, fmt.Errorf("failed to query DB %w", err)
 	}
 
 	defer rows.Close()
 
+	categoryDto := make([]models.CategoryDto, 0, params.Limit+1)
+
+	for rows.Next() {
+		cat, err := scanCategory(rows)
+		if err != nil {
+			return models.Page[models.CategoryDto]{}, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		categoryDto = append(categoryDto, toCategoryDto(cat))
+	}
+
+	if err = rows.Err(); err != nil {
+		return models.Page[models.CategoryDto]{}, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	items, cursor := nextCursor(params, categoryDto, func(cat models.CategoryDto) (string, string) {
+		return sortValue(params.Sort, cat.Name, cat.Created, cat.Updated), cat.ID
+	})
+
+	return models.Page[models.CategoryDto]{Items: items, NextCursor: cursor}, nil
+}
+
+func (c *Categories) GetCategoryByID(ctx context.Context, id string) (models.CategoryDto, error) {
+	sqlStatement := `SELECT ` + categoryColumns + ` FROM public.categories WHERE id = $1`
+
+	cat, err := scanCategory(c.db.DB.QueryRow(ctx, sqlStatement, id))
 	if err != nil {
-		return categoryDto, fmt.Errorf("failed to query DB %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.CategoryDto{}, models.ErrNotFound
+		}
+
+		return models.CategoryDto{}, fmt.Errorf("failed to query DB %w", err)
 	}
 
-	for rows.Next() {
-		cat := models.Category{}
+	return toCategoryDto(cat), nil
+}
+
+func (c *Categories) AddCategory(ctx context.Context, category models.CategoryDto) (models.CategoryDto, error) {
+	sqlStatement := `INSERT INTO public.categories
+					(name,parent_id,created_at,updated_at)
+					values ($1,$2,now(),now())
+					RETURNING ` + categoryColumns
 
-		err = rows.Scan(&cat.ID, &cat.Name, &cat.Created, &cat.ProductID, &cat.Updated)
+	var res models.CategoryDto
 
+	err := c.db.inTx(ctx, func(tx pgx.Tx) error {
+		cat, err := scanCategory(tx.QueryRow(ctx, sqlStatement, category.Name, category.ParentID))
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse DB %w", err)
+			if isUniqueViolation(err) {
+				return models.ErrUnique
+			}
+
+			if isForeignKeyViolation(err) {
+				return fmt.Errorf("parent category %w", models.ErrNotFound)
+			}
+
+			return fmt.Errorf("error adding to DB %w", err)
 		}
 
-		categoryDto = append(categoryDto, models.CategoryDto{ID: cat.ID, ProductID: cat.ProductID, Name: cat.Name})
+		res = toCategoryDto(cat)
+
+		return addCategoryEvent(ctx, tx, res)
+	})
+	if err != nil {
+		return models.CategoryDto{}, err
 	}
 
-	return categoryDto, nil
+	return res, nil
 }
 
-func (c *Categories) AddCategory(ctx context.Context, name string, productID string) (id string, err error) {
-	sqlStatement := `INSERT INTO public.categories
-					(name,product_id,created_at,updated_at) 
-					values ($1,$2,now(),now());`
+// AddCategoryForProduct assigns the product to the top-level category called name in one transaction,
+// creating the category unless it exists. A concurrent creation of the same category is waited for and reused.
+func (c *Categories) AddCategoryForProduct(ctx context.Context, name string,
+	productID string) (models.CategoryDto, error) {
+	insertStatement := `INSERT INTO public.categories (name, parent_id, created_at, updated_at)
+					VALUES ($1, NULL, now(), now())
+					ON CONFLICT DO NOTHING
+					RETURNING ` + categoryColumns
+	selectStatement := `SELECT ` + categoryColumns + ` FROM public.categories WHERE parent_id IS NULL AND name = $1`
+
+	var res models.CategoryDto
 
-	result, err := c.db.DB.Exec(ctx, sqlStatement, name, productID)
+	err := c.db.inTx(ctx, func(tx pgx.Tx) error {
+		cat, err := scanCategory(tx.QueryRow(ctx, insertStatement, name))
 
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			if cat, err = scanCategory(tx.QueryRow(ctx, selectStatement, name)); err != nil {
+				return fmt.Errorf("failed to query DB %w", err)
+			}
+
+			res = toCategoryDto(cat)
+		case err != nil:
+			return fmt.Errorf("error adding to DB %w", err)
+		default:
+			res = toCategoryDto(cat)
+
+			if err = addCategoryEvent(ctx, tx, res); err != nil {
+				return err
+			}
+		}
+
+		return assignProduct(ctx, tx, res.ID, productID)
+	})
+	if err != nil {
+		return models.CategoryDto{}, err
+	}
+
+	return res, nil
+}
+
+func addCategoryEvent(ctx context.Context, tx pgx.Tx, category models.CategoryDto) error {
+	event, err := models.NewEvent(models.EventCategoryCreated, category.ID, category)
 	if err != nil {
-		if !result.Insert() {
-			return "", models.ErrUnique
+		return err
+	}
+
+	return addEvents(ctx, tx, event)
+}
+
+func (c *Categories) DeleteCategory(ctx context.Context, id string) error {
+	sqlStatement := `DELETE FROM public.categories WHERE id = $1;`
+
+	return c.db.inTx(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, sqlStatement, id)
+		if err != nil {
+			if isForeignKeyViolation(err) {
+				return fmt.Errorf("%w: category has subcategories", models.ErrConflict)
+			}
+
+			return fmt.Errorf("error deleting from DB %w", err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return models.ErrNotFound
+		}
+
+		event, err := models.NewEvent(models.EventCategoryDeleted, id, models.EventDeleteData{ID: id})
+		if err != nil {
+			return err
+		}
+
+		return addEvents(ctx, tx, event)
+	})
+}
+
+func (c *Categories) SetCategory(ctx context.Context, id string, name string) (models.CategoryDto, error) {
+	sqlStatement := `UPDATE public.categories SET name = $1, updated_at = now() WHERE id = $2
+					RETURNING ` + categoryColumns
+
+	var res models.CategoryDto
+
+	err := c.db.inTx(ctx, func(tx pgx.Tx) error {
+		old, err := lockCategory(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		cat, err := scanCategory(tx.QueryRow(ctx, sqlStatement, name, id))
+		if err != nil {
+			if isUniqueViolation(err) {
+				return models.ErrUnique
+			}
+
+			return fmt.Errorf("error updating DB %w", err)
+		}
+
+		res = toCategoryDto(cat)
+
+		if res.Name == old.Name {
+			return nil
 		}
 
-		return "", fmt.Errorf("error adding to DB %w", err)
+		event, err := models.NewEvent(models.EventCategoryRenamed, id,
+			models.EventRenameData{ID: id, OldName: old.Name, Name: res.Name})
+		if err != nil {
+			return err
+		}
+
+		return addEvents(ctx, tx, event)
+	})
+	if err != nil {
+		return models.CategoryDto{}, err
 	}
 
-	sqlStatement = `SELECT id FROM public.categories where name = $1`
+	return res, nil
+}
 
-	rows, err := c.db.DB.Query(ctx, sqlStatement)
+// GetCategorySubtree returns the category and all of its descendants, parents before children.
+func (c *Categories) GetCategorySubtree(ctx context.Context, id string) ([]models.CategoryDto, error) {
+	sqlStatement := `WITH RECURSIVE subtree AS (
+						SELECT ` + categoryColumns + `, 0 AS depth FROM public.categories WHERE id = $1
+						UNION ALL
+						SELECT c.id, c.name, c.parent_id, c.created_at, c.updated_at, s.depth + 1
+						FROM public.categories c JOIN subtree s ON c.parent_id = s.id
+					)
+					SELECT ` + categoryColumns + ` FROM subtree ORDER BY depth, name, id`
+
+	return c.queryCategories(ctx, sqlStatement, id)
+}
+
+// GetCategoryAncestors returns the breadcrumb from the root category down to and including the category.
+func (c *Categories) GetCategoryAncestors(ctx context.Context, id string) ([]models.CategoryDto, error) {
+	sqlStatement := `WITH RECURSIVE ancestors AS (
+						SELECT ` + categoryColumns + `, 0 AS depth FROM public.categories WHERE id = $1
+						UNION ALL
+						SELECT c.id, c.name, c.parent_id, c.created_at, c.updated_at, a.depth + 1
+						FROM public.categories c JOIN ancestors a ON c.id = a.parent_id
+					)
+					SELECT ` + categoryColumns + ` FROM ancestors ORDER BY depth DESC`
+
+	return c.queryCategories(ctx, sqlStatement, id)
+}
+
+// MoveCategory reparents the category. The cycle check and the update run in one transaction
+// under an advisory lock, so concurrent moves are validated against each other's results.
+func (c *Categories) MoveCategory(ctx context.Context, id string, parentID *string) (models.CategoryDto, error) {
+	tx, err := c.db.DB.Begin(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to query DB %w", err)
+		return models.CategoryDto{}, fmt.Errorf("failed to begin transaction %w", err)
 	}
 
-	defer rows.Close()
+	defer func() { _ = tx.Rollback(ctx) }()
 
+	if _, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, categoryTreeLock); err != nil {
+		return models.CategoryDto{}, fmt.Errorf("failed to lock category tree %w", err)
+	}
+
+	if parentID != nil {
+		if err = checkCategoryCycle(ctx, tx, id, *parentID); err != nil {
+			return models.CategoryDto{}, err
+		}
+	}
+
+	old, err := lockCategory(ctx, tx, id)
 	if err != nil {
-		return "", fmt.Errorf("failed to query DB %w", err)
+		return models.CategoryDto{}, err
 	}
 
-	err = rows.Scan(&id)
+	sqlStatement := `UPDATE public.categories SET parent_id = $1, updated_at = now() WHERE id = $2
+					RETURNING ` + categoryColumns
 
+	cat, err := scanCategory(tx.QueryRow(ctx, sqlStatement, parentID, id))
 	if err != nil {
-		return "", fmt.Errorf("failed to parse DB %w", err)
+		if isUniqueViolation(err) {
+			return models.CategoryDto{}, models.ErrUnique
+		}
+
+		return models.CategoryDto{}, fmt.Errorf("error updating DB %w", err)
 	}
 
-	return id, nil
+	if !sameID(old.ParentID, cat.ParentID) {
+		event, err := models.NewEvent(models.EventCategoryMoved, id,
+			models.EventMoveData{ID: id, OldParentID: old.ParentID, ParentID: cat.ParentID})
+		if err != nil {
+			return models.CategoryDto{}, err
+		}
+
+		if err = addEvents(ctx, tx, event); err != nil {
+			return models.CategoryDto{}, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.CategoryDto{}, fmt.Errorf("failed to commit transaction %w", err)
+	}
+
+	return toCategoryDto(cat), nil
 }
 
-func (c *Categories) DeleteCategory(ctx context.Context, id string) error {
-	sqlStatement := `DELETE FROM public.categories WHERE id = $1;`
+// lockCategory reads the category before an update and keeps others from changing it until the transaction ends.
+func lockCategory(ctx context.Context, tx pgx.Tx, id string) (models.Category, error) {
+	sqlStatement := `SELECT ` + categoryColumns + ` FROM public.categories WHERE id = $1 FOR UPDATE`
 
-	result, err := c.db.DB.Exec(ctx, sqlStatement, id)
+	cat, err := scanCategory(tx.QueryRow(ctx, sqlStatement, id))
 	if err != nil {
-		return fmt.Errorf("error deleting from DB %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Category{}, models.ErrNotFound
+		}
+
+		return models.Category{}, fmt.Errorf("failed to query DB %w", err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return models.ErrNotFound
+	return cat, nil
+}
+
+func sameID(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// checkCategoryCycle walks up from the new parent and fails if it meets the category being moved.
+func checkCategoryCycle(ctx context.Context, tx pgx.Tx, id string, parentID string) error {
+	sqlStatement := `WITH RECURSIVE ancestors AS (
+						SELECT id, parent_id FROM public.categories WHERE id = $1
+						UNION ALL
+						SELECT c.id, c.parent_id FROM public.categories c JOIN ancestors a ON c.id = a.parent_id
+					)
+					SELECT count(*), count(*) FILTER (WHERE id = $2) FROM ancestors`
+
+	var found, cycles int
+
+	if err := tx.QueryRow(ctx, sqlStatement, parentID, id).Scan(&found, &cycles); err != nil {
+		return fmt.Errorf("failed to query DB %w", err)
+	}
+
+	if found == 0 {
+		return fmt.Errorf("parent category %w", models.ErrNotFound)
+	}
+
+	if cycles > 0 {
+		return fmt.Errorf("%w: category cannot be moved under itself or its descendant", models.ErrConflict)
 	}
 
 	return nil
 }
 
-func (c *Categories) SetCategory(ctx context.Context, id string, name string) error {
-	sqlStatement := `UPDATE public.categories SET name = $1 WHERE id = $2;`
+func (c *Categories) AssignProduct(ctx context.Context, categoryID string, productID string) error {
+	return c.db.inTx(ctx, func(tx pgx.Tx) error {
+		return assignProduct(ctx, tx, categoryID, productID)
+	})
+}
 
-	result, err := c.db.DB.Exec(ctx, sqlStatement, name, id)
+func assignProduct(ctx context.Context, tx pgx.Tx, categoryID string, productID string) error {
+	sqlStatement := `INSERT INTO public.product_categories (product_id, category_id) VALUES ($1, $2)
+					ON CONFLICT DO NOTHING`
+
+	result, err := tx.Exec(ctx, sqlStatement, productID, categoryID)
 	if err != nil {
-		return fmt.Errorf("error updating DB %w", err)
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("%s %w", violatedReference(err), models.ErrNotFound)
+		}
+
+		return fmt.Errorf("error adding to DB %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return models.ErrNotFound
+		return nil
 	}
 
-	return nil
+	return addAssignmentEvent(ctx, tx, models.EventProductAssigned, categoryID, productID)
+}
+
+func (c *Categories) UnassignProduct(ctx context.Context, categoryID string, productID string) error {
+	sqlStatement := `DELETE FROM public.product_categories WHERE product_id = $1 AND category_id = $2`
+
+	return c.db.inTx(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, sqlStatement, productID, categoryID)
+		if err != nil {
+			return fmt.Errorf("error deleting from DB %w", err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return models.ErrNotFound
+		}
+
+		return addAssignmentEvent(ctx, tx, models.EventProductUnassigned, categoryID, productID)
+	})
+}
+
+func addAssignmentEvent(ctx context.Context, tx pgx.Tx, eventType string, categoryID string, productID string) error {
+	event, err := models.NewEvent(eventType, categoryID,
+		models.EventAssignmentData{CategoryID: categoryID, ProductID: productID})
+	if err != nil {
+		return err
+	}
+
+	return addEvents(ctx, tx, event)
+}
+
+func (c *Categories) GetProductCategories(ctx context.Context, productID string) ([]models.CategoryDto, error) {
+	var exists bool
+
+	err := c.db.DB.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM public.products WHERE id = $1)`, productID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("product %w", models.ErrNotFound)
+	}
+
+	sqlStatement := `SELECT ` + categoryColumns + ` FROM public.categories
+					WHERE id IN (SELECT category_id FROM public.product_categories WHERE product_id = $1)
+					ORDER BY name, id`
+
+	categories, err := c.queryCategories(ctx, sqlStatement, productID)
+	if errors.Is(err, models.ErrNotFound) {
+		return []models.CategoryDto{}, nil
+	}
+
+	return categories, err
+}
+
+func (c *Categories) queryCategories(ctx context.Context, sqlStatement string, args ...any) ([]models.CategoryDto, error) {
+	rows, err := c.db.DB.Query(ctx, sqlStatement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	var categoryDto []models.CategoryDto
+
+	for rows.Next() {
+		cat, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		categoryDto = append(categoryDto, toCategoryDto(cat))
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	if len(categoryDto) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return categoryDto, nil
+}
+
+func scanCategory(row pgx.Row) (cat models.Category, err error) {
+	err = row.Scan(&cat.ID, &cat.Name, &cat.ParentID, &cat.Created, &cat.Updated)
+
+	return cat, err
+}
+
+func toCategoryDto(cat models.Category) models.CategoryDto {
+	return models.CategoryDto{
+		ID:       cat.ID,
+		Name:     cat.Name,
+		ParentID: cat.ParentID,
+		Created:  cat.Created,
+		Updated:  cat.Updated,
+	}
 }
@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tradeservice/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	stockLevelColumns    = `product_id, on_hand, reserved, updated_at`
+	stockMovementColumns = `id, product_id, movement_type, quantity, reason, on_hand_after, created_at`
+)
+
+type Inventory struct {
+	db *Storage
+}
+
+func NewInventory(db *Storage) (*Inventory, error) {
+	return &Inventory{
+		db: db,
+	}, nil
+}
+
+func (c *Inventory) GetStockLevel(ctx context.Context, productID string) (models.StockLevelDto, error) {
+	sqlStatement := `SELECT p.id, COALESCE(l.on_hand, 0), COALESCE(l.reserved, 0), COALESCE(l.updated_at, p.created_at)
+					FROM public.products p LEFT JOIN public.inventory_levels l ON l.product_id = p.id
+					WHERE p.id = $1`
+
+	level, err := scanStockLevel(c.db.DB.QueryRow(ctx, sqlStatement, productID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.StockLevelDto{}, fmt.Errorf("product %w", models.ErrNotFound)
+		}
+
+		return models.StockLevelDto{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	return toStockLevelDto(level), nil
+}
+
+// RecordMovement applies movement.Quantity to the on-hand stock and appends it to the ledger in one
+// transaction. A sale that fulfils a reservation also releases its quantity of reserved stock, which must be
+// there. The guarded UPDATE locks the level row, so concurrent movements are applied one after another and a
+// movement that would take on-hand stock below the reserved quantity is rejected.
+func (c *Inventory) RecordMovement(ctx context.Context, movement models.StockMovementDto) (models.StockMovementDto, error) {
+	tx, err := c.db.DB.Begin(ctx)
+	if err != nil {
+		return models.StockMovementDto{}, fmt.Errorf("failed to begin transaction %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err = ensureStockLevel(ctx, tx, movement.ProductID); err != nil {
+		return models.StockMovementDto{}, err
+	}
+
+	sqlStatement := `UPDATE public.inventory_levels
+					SET on_hand = on_hand + $2, reserved = reserved - $3, updated_at = now()
+					WHERE product_id = $1 AND reserved >= $3 AND on_hand + $2 >= reserved - $3
+					RETURNING ` + stockLevelColumns
+
+	level, err := scanStockLevel(tx.QueryRow(ctx, sqlStatement, movement.ProductID, movement.Quantity,
+		movement.Released()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.StockMovementDto{}, fmt.Errorf("%w: insufficient stock", models.ErrConflict)
+		}
+
+		return models.StockMovementDto{}, fmt.Errorf("error updating DB %w", err)
+	}
+
+	sqlStatement = `INSERT INTO public.stock_movements
+					(product_id, movement_type, quantity, reason, on_hand_after, created_at)
+					VALUES ($1, $2, $3, $4, $5, now())
+					RETURNING ` + stockMovementColumns
+
+	recorded, err := scanStockMovement(tx.QueryRow(ctx, sqlStatement,
+		movement.ProductID, movement.Type, movement.Quantity, movement.Reason, level.OnHand))
+	if err != nil {
+		return models.StockMovementDto{}, fmt.Errorf("error adding to DB %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.StockMovementDto{}, fmt.Errorf("failed to commit transaction %w", err)
+	}
+
+	return toStockMovementDto(recorded), nil
+}
+
+func (c *Inventory) GetStockMovements(ctx context.Context, productID string,
+	params models.ListParams) (models.Page[models.StockMovementDto], error) {
+	var exists bool
+
+	err := c.db.DB.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM public.products WHERE id = $1)`, productID).Scan(&exists)
+	if err != nil {
+		return models.Page[models.StockMovementDto]{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	if !exists {
+		return models.Page[models.StockMovementDto]{}, fmt.Errorf("product %w", models.ErrNotFound)
+	}
+
+	query := listQuery{}
+	query.filter(`product_id = ` + query.arg(productID))
+
+	sqlStatement, err := query.build(`SELECT `+stockMovementColumns+` FROM public.stock_movements`, params)
+	if err != nil {
+		return models.Page[models.StockMovementDto]{}, err
+	}
+
+	rows, err := c.db.DB.Query(ctx, sqlStatement, query.args...)
+	if err != nil {
+		return models.Page[models.StockMovementDto]{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	movements := make([]models.StockMovementDto, 0, params.Limit+1)
+
+	for rows.Next() {
+		movement, err := scanStockMovement(rows)
+		if err != nil {
+			return models.Page[models.StockMovementDto]{}, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		movements = append(movements, toStockMovementDto(movement))
+	}
+
+	if err = rows.Err(); err != nil {
+		return models.Page[models.StockMovementDto]{}, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	items, cursor := nextCursor(params, movements, func(movement models.StockMovementDto) (string, string) {
+		return sortValue(params.Sort, "", movement.Created, movement.Created), movement.ID
+	})
+
+	return models.Page[models.StockMovementDto]{Items: items, NextCursor: cursor}, nil
+}
+
+func (c *Inventory) Reserve(ctx context.Context, productID string, quantity int64) (models.StockLevelDto, error) {
+	sqlStatement := `UPDATE public.inventory_levels SET reserved = reserved + $2, updated_at = now()
+					WHERE product_id = $1 AND on_hand - reserved >= $2
+					RETURNING ` + stockLevelColumns
+
+	return c.updateReserved(ctx, sqlStatement, productID, quantity)
+}
+
+func (c *Inventory) Release(ctx context.Context, productID string, quantity int64) (models.StockLevelDto, error) {
+	sqlStatement := `UPDATE public.inventory_levels SET reserved = reserved - $2, updated_at = now()
+					WHERE product_id = $1 AND reserved >= $2
+					RETURNING ` + stockLevelColumns
+
+	return c.updateReserved(ctx, sqlStatement, productID, quantity)
+}
+
+func (c *Inventory) updateReserved(ctx context.Context, sqlStatement string, productID string,
+	quantity int64) (models.StockLevelDto, error) {
+	tx, err := c.db.DB.Begin(ctx)
+	if err != nil {
+		return models.StockLevelDto{}, fmt.Errorf("failed to begin transaction %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err = ensureStockLevel(ctx, tx, productID); err != nil {
+		return models.StockLevelDto{}, err
+	}
+
+	level, err := scanStockLevel(tx.QueryRow(ctx, sqlStatement, productID, quantity))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.StockLevelDto{}, fmt.Errorf("%w: insufficient stock", models.ErrConflict)
+		}
+
+		return models.StockLevelDto{}, fmt.Errorf("error updating DB %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.StockLevelDto{}, fmt.Errorf("failed to commit transaction %w", err)
+	}
+
+	return toStockLevelDto(level), nil
+}
+
+// ensureStockLevel creates the zero stock level of a product on its first movement.
+func ensureStockLevel(ctx context.Context, tx pgx.Tx, productID string) error {
+	sqlStatement := `INSERT INTO public.inventory_levels (product_id) VALUES ($1) ON CONFLICT DO NOTHING`
+
+	if _, err := tx.Exec(ctx, sqlStatement, productID); err != nil {
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("product %w", models.ErrNotFound)
+		}
+
+		return fmt.Errorf("error adding to DB %w", err)
+	}
+
+	return nil
+}
+
+func scanStockLevel(row pgx.Row) (level models.StockLevel, err error) {
+	err = row.Scan(&level.ProductID, &level.OnHand, &level.Reserved, &level.Updated)
+
+	return level, err
+}
+
+func scanStockMovement(row pgx.Row) (movement models.StockMovement, err error) {
+	err = row.Scan(&movement.ID, &movement.ProductID, &movement.Type, &movement.Quantity,
+		&movement.Reason, &movement.OnHandAfter, &movement.Created)
+
+	return movement, err
+}
+
+func toStockLevelDto(level models.StockLevel) models.StockLevelDto {
+	return models.StockLevelDto{
+		ProductID: level.ProductID,
+		OnHand:    level.OnHand,
+		Reserved:  level.Reserved,
+		Available: level.OnHand - level.Reserved,
+		Updated:   level.Updated,
+	}
+}
+
+func toStockMovementDto(movement models.StockMovement) models.StockMovementDto {
+	return models.StockMovementDto{
+		ID:          movement.ID,
+		ProductID:   movement.ProductID,
+		Type:        movement.Type,
+		Quantity:    movement.Quantity,
+		Reason:      movement.Reason,
+		OnHandAfter: movement.OnHandAfter,
+		Created:     movement.Created,
+	}
+}
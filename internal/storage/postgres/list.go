@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"tradeservice/internal/models"
+)
+
+var sortColumns = map[string]string{
+	models.SortByName:      "name",
+	models.SortByCreatedAt: "created_at",
+	models.SortByUpdatedAt: "updated_at",
+}
+
+// listQuery assembles a keyset-paginated SELECT. It fetches one row more than the limit
+// so the caller can tell whether a next page exists.
+type listQuery struct {
+	where []string
+	args  []any
+}
+
+func (q *listQuery) arg(value any) string {
+	q.args = append(q.args, value)
+
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+func (q *listQuery) filter(condition string) {
+	q.where = append(q.where, condition)
+}
+
+func (q *listQuery) build(selectFrom string, params models.ListParams) (string, error) {
+	column, ok := sortColumns[params.Sort]
+	if !ok {
+		return "", fmt.Errorf("%w: unsupported sort %q", models.ErrValidation, params.Sort)
+	}
+
+	operator, order := ">", "ASC"
+	if params.Desc {
+		operator, order = "<", "DESC"
+	}
+
+	if params.NamePrefix != "" {
+		q.filter(`name LIKE ` + q.arg(escapeLike(params.NamePrefix)+"%"))
+	}
+
+	if params.CreatedAfter != nil {
+		q.filter(`created_at > ` + q.arg(*params.CreatedAfter))
+	}
+
+	if params.After != nil {
+		value, err := cursorValue(params.Sort, params.After.Value)
+		if err != nil {
+			return "", err
+		}
+
+		q.filter(fmt.Sprintf(`(%s, id) %s (%s, %s)`, column, operator, q.arg(value), q.arg(params.After.ID)))
+	}
+
+	sqlStatement := selectFrom
+	if len(q.where) > 0 {
+		sqlStatement += ` WHERE ` + strings.Join(q.where, ` AND `)
+	}
+
+	sqlStatement += fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT %s`, column, order, order, q.arg(params.Limit+1))
+
+	return sqlStatement, nil
+}
+
+// nextCursor returns the cursor for the page following items, or "" when items is the last page.
+func nextCursor[T any](params models.ListParams, items []T, key func(T) (value string, id string)) ([]T, string) {
+	if len(items) <= params.Limit {
+		return items, ""
+	}
+
+	items = items[:params.Limit]
+	value, id := key(items[len(items)-1])
+
+	return items, models.EncodeCursor(models.Cursor{Sort: params.Sort, Desc: params.Desc, Value: value, ID: id})
+}
+
+func sortValue(sort string, name string, created time.Time, updated time.Time) string {
+	switch sort {
+	case models.SortByName:
+		return name
+	case models.SortByUpdatedAt:
+		return updated.Format(time.RFC3339Nano)
+	default:
+		return created.Format(time.RFC3339Nano)
+	}
+}
+
+func cursorValue(sort string, value string) (any, error) {
+	if sort == models.SortByName {
+		return value, nil
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", models.ErrValidation)
+	}
+
+	return ts, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"tradeservice/internal/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CatalogChannel carries a notification, with the table name as payload, for every committed transaction
+// that writes products, categories or their links; see migration 0000014.
+const CatalogChannel = "catalog_changed"
+
+const (
+	minListenBackoff = 100 * time.Millisecond
+	maxListenBackoff = 30 * time.Second
+	// listenPingInterval bounds how long a silently dropped connection goes unnoticed.
+	listenPingInterval = 30 * time.Second
+)
+
+// Listen LISTENs on channel over a connection of its own, outside the pool, and calls notify with the
+// payload of every notification until ctx is done. A lost connection is reopened with exponential backoff.
+// Notifications sent while no connection listens are lost, so resync is called every time the LISTEN is
+// (re)established, including the first.
+func (store *Storage) Listen(ctx context.Context, channel string, notify func(payload string), resync func()) {
+	log := logger.FromContext(ctx).With("channel", channel)
+	backoff := minListenBackoff
+
+	for {
+		err := store.listen(ctx, channel, notify, func() {
+			backoff = minListenBackoff
+
+			log.Info("Listening for notifications")
+			resync()
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Warn("Lost notification listener, reconnecting", "retry_in", backoff, slog.Any("error_details", err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, maxListenBackoff)
+	}
+}
+
+// listen runs one connection of Listen until it fails.
+func (store *Storage) listen(ctx context.Context, channel string, notify func(payload string), listening func()) error {
+	conn, err := pgx.ConnectConfig(ctx, store.DB.Config().ConnConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect %w", err)
+	}
+
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second)
+		defer cancel()
+
+		_ = conn.Close(closeCtx)
+	}()
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen %w", err)
+	}
+
+	listening()
+
+	for {
+		waitCtx, cancel := context.WithTimeout(ctx, listenPingInterval)
+		notification, err := conn.WaitForNotification(waitCtx)
+
+		cancel()
+
+		switch {
+		case err == nil:
+			notify(notification.Payload)
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, context.DeadlineExceeded):
+			// A quiet channel; make sure the connection is still there to hear the next notification.
+			if err = conn.Ping(ctx); err != nil {
+				return fmt.Errorf("failed to ping %w", err)
+			}
+		default:
+			return fmt.Errorf("failed to wait for notification %w", err)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tradeservice/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	orderColumns     = `id, status, currency, total, created_at, updated_at`
+	orderLineColumns = `order_id, line_no, product_id, quantity, unit_price, line_total`
+)
+
+type Orders struct {
+	db *Storage
+}
+
+func NewOrders(db *Storage) (*Orders, error) {
+	return &Orders{
+		db: db,
+	}, nil
+}
+
+// AddOrder stores an already priced order together with its lines.
+func (c *Orders) AddOrder(ctx context.Context, order models.OrderDto) (models.OrderDto, error) {
+	tx, err := c.db.DB.Begin(ctx)
+	if err != nil {
+		return models.OrderDto{}, fmt.Errorf("failed to begin transaction %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sqlStatement := `INSERT INTO public.orders (status, currency, total, created_at, updated_at)
+					VALUES ($1, $2, $3, now(), now())
+					RETURNING ` + orderColumns
+
+	ord, err := scanOrder(tx.QueryRow(ctx, sqlStatement, order.Status, order.Currency, order.Total))
+	if err != nil {
+		return models.OrderDto{}, fmt.Errorf("error adding to DB %w", err)
+	}
+
+	sqlStatement = `INSERT INTO public.order_lines (` + orderLineColumns + `) VALUES ($1, $2, $3, $4, $5, $6)`
+
+	batch := &pgx.Batch{}
+	for i, line := range order.Lines {
+		batch.Queue(sqlStatement, ord.ID, i+1, line.ProductID, line.Quantity, line.UnitPrice, line.LineTotal)
+	}
+
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		if isForeignKeyViolation(err) {
+			return models.OrderDto{}, fmt.Errorf("product %w", models.ErrNotFound)
+		}
+
+		return models.OrderDto{}, fmt.Errorf("error adding to DB %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.OrderDto{}, fmt.Errorf("failed to commit transaction %w", err)
+	}
+
+	res := toOrderDto(ord)
+	res.Lines = order.Lines
+
+	return res, nil
+}
+
+func (c *Orders) GetOrder(ctx context.Context, params models.ListParams) (models.Page[models.OrderDto], error) {
+	query := listQuery{}
+
+	sqlStatement, err := query.build(`SELECT `+orderColumns+` FROM public.orders`, params)
+	if err != nil {
+		return models.Page[models.OrderDto]{}, err
+	}
+
+	rows, err := c.db.DB.Query(ctx, sqlStatement, query.args...)
+	if err != nil {
+		return models.Page[models.OrderDto]{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	orderDto := make([]models.OrderDto, 0, params.Limit+1)
+
+	for rows.Next() {
+		ord, err := scanOrder(rows)
+		if err != nil {
+			return models.Page[models.OrderDto]{}, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		orderDto = append(orderDto, toOrderDto(ord))
+	}
+
+	if err = rows.Err(); err != nil {
+		return models.Page[models.OrderDto]{}, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	items, cursor := nextCursor(params, orderDto, func(ord models.OrderDto) (string, string) {
+		return sortValue(params.Sort, "", ord.Created, ord.Updated), ord.ID
+	})
+
+	return models.Page[models.OrderDto]{Items: items, NextCursor: cursor}, nil
+}
+
+func (c *Orders) GetOrderByID(ctx context.Context, id string) (models.OrderDto, error) {
+	sqlStatement := `SELECT ` + orderColumns + ` FROM public.orders WHERE id = $1`
+
+	ord, err := scanOrder(c.db.DB.QueryRow(ctx, sqlStatement, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.OrderDto{}, models.ErrNotFound
+		}
+
+		return models.OrderDto{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	res := toOrderDto(ord)
+
+	res.Lines, err = c.getOrderLines(ctx, id)
+	if err != nil {
+		return models.OrderDto{}, err
+	}
+
+	return res, nil
+}
+
+// SetOrderStatus moves the order from status "from" to "to". It fails with models.ErrConflict when the
+// order is no longer in "from", so a transition validated by the caller cannot race with another one.
+func (c *Orders) SetOrderStatus(ctx context.Context, id string, from string, to string) (models.OrderDto, error) {
+	sqlStatement := `UPDATE public.orders SET status = $3, updated_at = now() WHERE id = $1 AND status = $2
+					RETURNING ` + orderColumns
+
+	ord, err := scanOrder(c.db.DB.QueryRow(ctx, sqlStatement, id, from, to))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err = c.GetOrderByID(ctx, id); err != nil {
+				return models.OrderDto{}, err
+			}
+
+			return models.OrderDto{}, fmt.Errorf("%w: order is no longer %s", models.ErrConflict, from)
+		}
+
+		return models.OrderDto{}, fmt.Errorf("error updating DB %w", err)
+	}
+
+	res := toOrderDto(ord)
+
+	res.Lines, err = c.getOrderLines(ctx, id)
+	if err != nil {
+		return models.OrderDto{}, err
+	}
+
+	return res, nil
+}
+
+func (c *Orders) getOrderLines(ctx context.Context, orderID string) ([]models.OrderLineDto, error) {
+	sqlStatement := `SELECT ` + orderLineColumns + ` FROM public.order_lines WHERE order_id = $1 ORDER BY line_no`
+
+	rows, err := c.db.DB.Query(ctx, sqlStatement, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	var lines []models.OrderLineDto
+
+	for rows.Next() {
+		var line models.OrderLine
+
+		err = rows.Scan(&line.OrderID, &line.LineNo, &line.ProductID, &line.Quantity, &line.UnitPrice, &line.LineTotal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		lines = append(lines, models.OrderLineDto{
+			ProductID: line.ProductID,
+			Quantity:  line.Quantity,
+			UnitPrice: line.UnitPrice,
+			LineTotal: line.LineTotal,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	return lines, nil
+}
+
+func scanOrder(row pgx.Row) (ord models.Order, err error) {
+	err = row.Scan(&ord.ID, &ord.Status, &ord.Currency, &ord.Total, &ord.Created, &ord.Updated)
+
+	return ord, err
+}
+
+func toOrderDto(ord models.Order) models.OrderDto {
+	return models.OrderDto{
+		ID:       ord.ID,
+		Status:   ord.Status,
+		Currency: ord.Currency,
+		Total:    ord.Total,
+		Created:  ord.Created,
+		Updated:  ord.Updated,
+	}
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"tradeservice/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const outboxColumns = `id, event_type, subject, data, attempts, last_error, created_at`
+
+type Outbox struct {
+	db *Storage
+}
+
+func NewOutbox(db *Storage) (*Outbox, error) {
+	return &Outbox{
+		db: db,
+	}, nil
+}
+
+// ClaimEvents pushes the next attempt of the claimed events past the lease; SKIP LOCKED keeps concurrent
+// dispatchers from waiting on each other's claims.
+func (c *Outbox) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	sqlStatement := `WITH claimed AS (
+						UPDATE public.outbox_events SET next_attempt_at = now() + make_interval(secs => $2)
+						WHERE id IN (
+							SELECT id FROM public.outbox_events
+							WHERE delivered_at IS NULL AND next_attempt_at <= now()
+							ORDER BY id LIMIT $1
+							FOR UPDATE SKIP LOCKED
+						)
+						RETURNING ` + outboxColumns + `
+					)
+					SELECT ` + outboxColumns + ` FROM claimed ORDER BY id`
+
+	rows, err := c.db.DB.Query(ctx, sqlStatement, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	events := make([]models.OutboxEvent, 0, limit)
+
+	for rows.Next() {
+		var event models.OutboxEvent
+
+		err = rows.Scan(&event.ID, &event.Type, &event.Subject, &event.Data, &event.Attempts, &event.LastError,
+			&event.Created)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	return events, nil
+}
+
+func (c *Outbox) MarkEventDelivered(ctx context.Context, id string) error {
+	sqlStatement := `UPDATE public.outbox_events SET delivered_at = now() WHERE id = $1`
+
+	result, err := c.db.DB.Exec(ctx, sqlStatement, id)
+	if err != nil {
+		return fmt.Errorf("error updating DB %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func (c *Outbox) MarkEventFailed(ctx context.Context, id string, retryAt time.Time, reason string) error {
+	sqlStatement := `UPDATE public.outbox_events SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+					WHERE id = $1`
+
+	result, err := c.db.DB.Exec(ctx, sqlStatement, id, reason, retryAt)
+	if err != nil {
+		return fmt.Errorf("error updating DB %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func (c *Outbox) DeleteDeliveredEvents(ctx context.Context, before time.Time) (int64, error) {
+	sqlStatement := `DELETE FROM public.outbox_events WHERE delivered_at < $1`
+
+	result, err := c.db.DB.Exec(ctx, sqlStatement, before)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting from DB %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// addEvents records the events of a write in its transaction.
+func addEvents(ctx context.Context, tx pgx.Tx, events ...models.Event) error {
+	sqlStatement := `INSERT INTO public.outbox_events (event_type, subject, data) VALUES ($1, $2, $3)`
+
+	for _, event := range events {
+		if _, err := tx.Exec(ctx, sqlStatement, event.Type, event.Subject, event.Data); err != nil {
+			return fmt.Errorf("error adding event to DB %w", err)
+		}
+	}
+
+	return nil
+}
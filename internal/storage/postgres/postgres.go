@@ -2,16 +2,29 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"tradeservice/internal/config"
 	"tradeservice/internal/models"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+)
+
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
 )
 
 type Storage struct {
 	DB *pgxpool.Pool
+	// sqlDB shares the pool with database/sql users such as goose.
+	sqlDB *sql.DB
 }
 
 func New(dbConfig config.DBConfig) (*Storage, error) {
@@ -30,12 +43,36 @@ func New(dbConfig config.DBConfig) (*Storage, error) {
 	return db, nil
 }
 
+func (store *Storage) Ping(ctx context.Context) error {
+	if err := store.DB.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping DB %w", err)
+	}
+
+	return nil
+}
+
 func (store *Storage) Close() {
+	_ = store.sqlDB.Close()
 	store.DB.Close()
 }
 
+func (store *Storage) Dialect() goose.Dialect {
+	return goose.DialectPostgres
+}
+
+func (store *Storage) SQLDB() *sql.DB {
+	return store.sqlDB
+}
+
 func (store *Storage) connect(connStr string) error {
-	pool, err := pgxpool.New(context.Background(), connStr)
+	poolConfig, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return fmt.Errorf("db.connect parse config: %w", err)
+	}
+
+	poolConfig.ConnConfig.Tracer = queryTracer{}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 
 	if err != nil {
 		return fmt.Errorf("db.connect: %w", err)
@@ -48,6 +85,56 @@ func (store *Storage) connect(connStr string) error {
 	}
 
 	store.DB = pool
+	store.sqlDB = stdlib.OpenDBFromPool(pool)
 
 	return nil
 }
+
+// inTx runs fn in a transaction that is committed when fn succeeds.
+func (store *Storage) inTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := store.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction %w", err)
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode
+}
+
+// violatedReference names the referenced entity of a foreign key violation, e.g. "product" for products_id.
+func violatedReference(err error) string {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return "reference"
+	}
+
+	switch {
+	case strings.Contains(pgErr.ConstraintName, "product_id"):
+		return "product"
+	case strings.Contains(pgErr.ConstraintName, "category_id"), strings.Contains(pgErr.ConstraintName, "parent_id"):
+		return "category"
+	default:
+		return "reference"
+	}
+}
@@ -0,0 +1,148 @@
+package postgres_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+	"tradeservice/internal/config"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/utils"
+	"tradeservice/internal/storage"
+	"tradeservice/internal/storage/postgres"
+	"tradeservice/internal/storage/storagetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openDB needs a disposable database: set STORAGE_TEST_POSTGRES=1 and the DB_* variables of
+// config.DBConfig. Every test truncates all tables.
+func openDB(t *testing.T) *postgres.Storage {
+	t.Helper()
+
+	if os.Getenv("STORAGE_TEST_POSTGRES") == "" {
+		t.Skip("STORAGE_TEST_POSTGRES is not set")
+	}
+
+	cfg, err := config.New()
+	require.NoError(t, err)
+
+	db, err := postgres.New(cfg.DB)
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	require.NoError(t, storage.RunMigration(db, utils.NewTestLogger(), "../../migrations"))
+
+	return db
+}
+
+func TestConformance(t *testing.T) {
+	db := openDB(t)
+
+	storagetest.Run(t, func(t *testing.T) storage.Repositories {
+		t.Helper()
+
+		_, err := db.DB.Exec(context.Background(), `TRUNCATE public.categories, public.products,
+			public.product_categories, public.inventory_levels, public.stock_movements, public.orders,
+			public.order_lines, public.api_keys, public.outbox_events,
+			public.webhooks, public.webhook_deliveries, public.webhook_attempts RESTART IDENTITY CASCADE`)
+		require.NoError(t, err)
+
+		categories, err := postgres.NewCategories(db)
+		require.NoError(t, err)
+
+		products, err := postgres.NewProducts(db)
+		require.NoError(t, err)
+
+		inventory, err := postgres.NewInventory(db)
+		require.NoError(t, err)
+
+		orders, err := postgres.NewOrders(db)
+		require.NoError(t, err)
+
+		apiKeys, err := postgres.NewAPIKeys(db)
+		require.NoError(t, err)
+
+		outbox, err := postgres.NewOutbox(db)
+		require.NoError(t, err)
+
+		webhooks, err := postgres.NewWebhooks(db)
+		require.NoError(t, err)
+
+		return storage.Repositories{
+			Categories: categories,
+			Products:   products,
+			Inventory:  inventory,
+			Orders:     orders,
+			APIKeys:    apiKeys,
+			Outbox:     outbox,
+			Webhooks:   webhooks,
+		}
+	})
+}
+
+func TestListen_NotifiesCatalogWrites(t *testing.T) {
+	db := openDB(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	_, err := db.DB.Exec(ctx, `TRUNCATE public.products RESTART IDENTITY CASCADE`)
+	require.NoError(t, err)
+
+	payloads := make(chan string, 10)
+	listening := make(chan struct{}, 1)
+
+	go db.Listen(ctx, postgres.CatalogChannel, func(payload string) { payloads <- payload },
+		func() { listening <- struct{}{} })
+
+	select {
+	case <-listening:
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener did not start")
+	}
+
+	products, err := postgres.NewProducts(db)
+	require.NoError(t, err)
+
+	_, err = products.AddProduct(ctx, models.ProductDto{Name: "bolt", SKU: "B-1", Currency: "USD"})
+	require.NoError(t, err)
+
+	select {
+	case payload := <-payloads:
+		assert.Equal(t, "products", payload)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification")
+	}
+}
+
+func TestStockMovements_AppendOnly(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	_, err := db.DB.Exec(ctx, `TRUNCATE public.products RESTART IDENTITY CASCADE`)
+	require.NoError(t, err)
+
+	products, err := postgres.NewProducts(db)
+	require.NoError(t, err)
+
+	inventory, err := postgres.NewInventory(db)
+	require.NoError(t, err)
+
+	product, err := products.AddProduct(ctx, models.ProductDto{Name: "bolt", SKU: "B-1", Currency: "USD"})
+	require.NoError(t, err)
+
+	_, err = inventory.RecordMovement(ctx, models.StockMovementDto{
+		ProductID: product.ID, Type: models.MovementReceipt, Quantity: 5,
+	})
+	require.NoError(t, err)
+
+	_, err = db.DB.Exec(ctx, `UPDATE public.stock_movements SET quantity = 6`)
+	require.ErrorContains(t, err, "append-only")
+
+	_, err = db.DB.Exec(ctx, `DELETE FROM public.stock_movements`)
+	require.ErrorContains(t, err, "append-only")
+
+	require.NoError(t, products.DeleteProduct(ctx, product.ID))
+}
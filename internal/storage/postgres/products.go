@@ -2,10 +2,17 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"tradeservice/internal/models"
+
+	"github.com/jackc/pgx/v5"
 )
 
+const productColumns = `id, name, sku, description, unit_price, currency, active, created_at, updated_at`
+
+// Products stores products and records the events of their changes in the outbox. Committed writes notify
+// CatalogChannel through a trigger.
 type Products struct {
 	db *Storage
 }
@@ -16,93 +23,231 @@ func NewProducts(db *Storage) (*Products, error) {
 	}, nil
 }
 
-func (c *Products) GetProduct(ctx context.Context) (productDto []models.ProductDto, err error) {
-	sqlStatement := `SELECT * FROM public.products`
+func (c *Products) GetProduct(ctx context.Context, params models.ListParams) (models.Page[models.ProductDto], error) {
+	if params.CategoryID != "" {
+		var exists bool
 
-	rows, err := c.db.DB.Query(ctx, sqlStatement)
-	if err != nil {
-		return productDto, fmt.Errorf("failed to query DB %w", err)
+		err := c.db.DB.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM public.categories WHERE id = $1)`,
+			params.CategoryID).Scan(&exists)
+		if err != nil {
+			return models.Page[models.ProductDto]{}, fmt.Errorf("failed to query DB %w", err)
+		}
+
+		if !exists {
+			return models.Page[models.ProductDto]{}, fmt.Errorf("category %w", models.ErrNotFound)
+		}
 	}
 
-	defer rows.Close()
+	query := listQuery{}
+
+	switch {
+	case params.CategoryID != "" && params.IncludeSubcategories:
+		query.filter(`id IN (SELECT product_id FROM public.product_categories WHERE category_id IN (
+						WITH RECURSIVE subtree AS (
+							SELECT id FROM public.categories WHERE id = ` + query.arg(params.CategoryID) + `
+							UNION ALL
+							SELECT c.id FROM public.categories c JOIN subtree s ON c.parent_id = s.id
+						)
+						SELECT id FROM subtree))`)
+	case params.CategoryID != "":
+		query.filter(`id IN (SELECT product_id FROM public.product_categories WHERE category_id = ` +
+			query.arg(params.CategoryID) + `)`)
+	}
 
+	sqlStatement, err := query.build(`SELECT `+productColumns+` FROM public.products`, params)
 	if err != nil {
-		return productDto, fmt.Errorf("failed to query DB %w", err)
+		return models.Page[models.ProductDto]{}, err
 	}
 
-	for rows.Next() {
-		prod := models.Product{}
+	rows, err := c.db.DB.Query(ctx, sqlStatement, query.args...)
+	if err != nil {
+		return models.Page[models.ProductDto]{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
 
-		err = rows.Scan(&prod.ID, &prod.Name, &prod.Created, &prod.Updated)
+	productDto := make([]models.ProductDto, 0, params.Limit+1)
 
+	for rows.Next() {
+		prod, err := scanProduct(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse DB %w", err)
+			return models.Page[models.ProductDto]{}, fmt.Errorf("failed to parse DB %w", err)
 		}
 
-		productDto = append(productDto, models.ProductDto{ID: prod.ID, Name: prod.Name})
+		productDto = append(productDto, toProductDto(prod))
+	}
+
+	if err = rows.Err(); err != nil {
+		return models.Page[models.ProductDto]{}, fmt.Errorf("failed to read DB %w", err)
 	}
 
-	return productDto, nil
+	items, cursor := nextCursor(params, productDto, func(prod models.ProductDto) (string, string) {
+		return sortValue(params.Sort, prod.Name, prod.Created, prod.Updated), prod.ID
+	})
+
+	return models.Page[models.ProductDto]{Items: items, NextCursor: cursor}, nil
 }
 
-func (c *Products) AddProduct(ctx context.Context, name string) (id string, err error) {
-	sqlStatement := `INSERT INTO public.products
-					(name,created_at,updated_at) 
-					values ($1,now(),now());`
+func (c *Products) GetProductByID(ctx context.Context, id string) (models.ProductDto, error) {
+	sqlStatement := `SELECT ` + productColumns + ` FROM public.products WHERE id = $1`
 
-	result, err := c.db.DB.Exec(ctx, sqlStatement, name)
+	prod, err := scanProduct(c.db.DB.QueryRow(ctx, sqlStatement, id))
 	if err != nil {
-		if !result.Insert() {
-			return "", models.ErrUnique
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ProductDto{}, models.ErrNotFound
 		}
 
-		return "", fmt.Errorf("error adding to DB %w", err)
+		return models.ProductDto{}, fmt.Errorf("failed to query DB %w", err)
 	}
 
-	sqlStatement = `SELECT id FROM public.products where name = $1`
+	return toProductDto(prod), nil
+}
 
-	rows, err := c.db.DB.Query(ctx, sqlStatement)
-	if err != nil {
-		return "", fmt.Errorf("failed to query DB %w", err)
-	}
+func (c *Products) AddProduct(ctx context.Context, product models.ProductDto) (models.ProductDto, error) {
+	sqlStatement := `INSERT INTO public.products
+					(name,sku,description,unit_price,currency,active,created_at,updated_at)
+					values ($1,$2,$3,$4,$5,$6,now(),now())
+					RETURNING ` + productColumns
 
-	defer rows.Close()
+	var res models.ProductDto
 
-	if err != nil {
-		return "", fmt.Errorf("failed to query DB %w", err)
-	}
+	err := c.db.inTx(ctx, func(tx pgx.Tx) error {
+		prod, err := scanProduct(tx.QueryRow(ctx, sqlStatement,
+			product.Name, product.SKU, product.Description, product.UnitPrice, product.Currency, product.Active))
+		if err != nil {
+			if isUniqueViolation(err) {
+				return models.ErrUnique
+			}
+
+			return fmt.Errorf("error adding to DB %w", err)
+		}
 
-	err = rows.Scan(&id)
+		res = toProductDto(prod)
 
+		event, err := models.NewEvent(models.EventProductCreated, res.ID, res)
+		if err != nil {
+			return err
+		}
+
+		return addEvents(ctx, tx, event)
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to parse DB %w", err)
+		return models.ProductDto{}, err
 	}
 
-	return id, nil
+	return res, nil
 }
 
 func (c *Products) DeleteProduct(ctx context.Context, id string) error {
 	sqlStatement := `DELETE FROM public.products WHERE id = $1;`
 
-	_, err := c.db.DB.Exec(ctx, sqlStatement, id)
-	if err != nil {
-		return fmt.Errorf("error deleting from DB %w", err)
-	}
+	return c.db.inTx(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, sqlStatement, id)
+		if err != nil {
+			if isForeignKeyViolation(err) {
+				return fmt.Errorf("%w: product is referenced by orders", models.ErrConflict)
+			}
+
+			return fmt.Errorf("error deleting from DB %w", err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return models.ErrNotFound
+		}
+
+		event, err := models.NewEvent(models.EventProductDeleted, id, models.EventDeleteData{ID: id})
+		if err != nil {
+			return err
+		}
 
-	return nil
+		return addEvents(ctx, tx, event)
+	})
 }
 
-func (c *Products) SetProduct(ctx context.Context, id string, name string) error {
-	sqlStatement := `UPDATE public.products SET name = $1 WHERE id = $2;`
+func (c *Products) SetProduct(ctx context.Context, id string, product models.ProductDto) (models.ProductDto, error) {
+	sqlStatement := `UPDATE public.products
+					SET name = $1, sku = $2, description = $3, unit_price = $4, currency = $5, active = $6, updated_at = now()
+					WHERE id = $7
+					RETURNING ` + productColumns
+
+	return c.update(ctx, id, sqlStatement,
+		product.Name, product.SKU, product.Description, product.UnitPrice, product.Currency, product.Active, id)
+}
+
+func (c *Products) PatchProduct(ctx context.Context, id string, patch models.ProductPatch) (models.ProductDto, error) {
+	sqlStatement := `UPDATE public.products
+					SET name = COALESCE($1, name),
+						sku = COALESCE($2, sku),
+						description = COALESCE($3, description),
+						unit_price = COALESCE($4, unit_price),
+						currency = COALESCE($5, currency),
+						active = COALESCE($6, active),
+						updated_at = now()
+					WHERE id = $7
+					RETURNING ` + productColumns
+
+	return c.update(ctx, id, sqlStatement,
+		patch.Name, patch.SKU, patch.Description, patch.UnitPrice, patch.Currency, patch.Active, id)
+}
+
+// update runs an UPDATE of product id returning its columns and records the events of the change.
+func (c *Products) update(ctx context.Context, id string, sqlStatement string, args ...any) (models.ProductDto, error) {
+	var res models.ProductDto
+
+	err := c.db.inTx(ctx, func(tx pgx.Tx) error {
+		var oldName string
+
+		err := tx.QueryRow(ctx, `SELECT name FROM public.products WHERE id = $1 FOR UPDATE`, id).Scan(&oldName)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrNotFound
+			}
 
-	result, err := c.db.DB.Exec(ctx, sqlStatement, name, id)
+			return fmt.Errorf("failed to query DB %w", err)
+		}
+
+		prod, err := scanProduct(tx.QueryRow(ctx, sqlStatement, args...))
+		if err != nil {
+			if isUniqueViolation(err) {
+				return models.ErrUnique
+			}
+
+			return fmt.Errorf("error updating DB %w", err)
+		}
+
+		res = toProductDto(prod)
+
+		events, err := models.ProductUpdateEvents(res, oldName)
+		if err != nil {
+			return err
+		}
+
+		return addEvents(ctx, tx, events...)
+	})
 	if err != nil {
-		return fmt.Errorf("error updating DB %w", err)
+		return models.ProductDto{}, err
 	}
 
-	if result.RowsAffected() == 0 {
-		return models.ErrNotFound
-	}
+	return res, nil
+}
 
-	return nil
+func scanProduct(row pgx.Row) (prod models.Product, err error) {
+	err = row.Scan(&prod.ID, &prod.Name, &prod.SKU, &prod.Description,
+		&prod.UnitPrice, &prod.Currency, &prod.Active, &prod.Created, &prod.Updated)
+
+	return prod, err
+}
+
+func toProductDto(prod models.Product) models.ProductDto {
+	return models.ProductDto{
+		ID:          prod.ID,
+		Name:        prod.Name,
+		SKU:         prod.SKU,
+		Description: prod.Description,
+		UnitPrice:   prod.UnitPrice,
+		Currency:    prod.Currency,
+		Active:      prod.Active,
+		Created:     prod.Created,
+		Updated:     prod.Updated,
+	}
 }
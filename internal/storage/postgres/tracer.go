@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"tradeservice/internal/logger"
+	"tradeservice/internal/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type queryKey struct{}
+
+type queryStart struct {
+	statement string
+	at        time.Time
+}
+
+// queryTracer opens a client span for every query and batch sent through the pool and logs the query
+// through the request-scoped logger.
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = tracing.Start(ctx, spanName(data.SQL),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemNamePostgreSQL, semconv.DBQueryText(data.SQL)))
+
+	return context.WithValue(ctx, queryKey{}, queryStart{statement: spanName(data.SQL), at: time.Now()})
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+
+	attrs := []any{"rows", data.CommandTag.RowsAffected()}
+	if start, ok := ctx.Value(queryKey{}).(queryStart); ok {
+		attrs = append(attrs, "statement", start.statement, "duration", time.Since(start.at))
+	}
+
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		attrs = append(attrs, slog.Any("error_details", data.Err))
+	}
+
+	logger.FromContext(ctx).Debug("Query", attrs...)
+
+	endSpan(span, data.Err)
+}
+
+func (queryTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	ctx, _ = tracing.Start(ctx, "batch",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemNamePostgreSQL, attribute.Int("db.batch.size", data.Batch.Len())))
+
+	return ctx
+}
+
+func (queryTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent("query", trace.WithAttributes(semconv.DBQueryText(data.SQL)))
+
+	if data.Err != nil {
+		span.RecordError(data.Err)
+	}
+}
+
+func (queryTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	endSpan(trace.SpanFromContext(ctx), data.Err)
+}
+
+// endSpan doesn't treat pgx.ErrNoRows as a failure; the storage maps it to models.ErrNotFound.
+func endSpan(span trace.Span, err error) {
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+
+	tracing.End(span, &err)
+}
+
+// spanName is the leading keyword of the statement, e.g. "SELECT" or "WITH".
+func spanName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "query"
+	}
+
+	return strings.ToUpper(fields[0])
+}
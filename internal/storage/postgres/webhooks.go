@@ -0,0 +1,346 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"tradeservice/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	webhookColumns  = `id, url, event_types, active, created_at, updated_at`
+	deliveryColumns = `id, webhook_id, event_id, event_type, status, attempts, last_error, next_attempt_at, created_at,
+		updated_at`
+	claimedColumns = `d.id, d.webhook_id, d.event_id, d.event_type, d.status, d.attempts, d.last_error,
+		d.next_attempt_at, d.created_at, d.updated_at`
+)
+
+type Webhooks struct {
+	db *Storage
+}
+
+func NewWebhooks(db *Storage) (*Webhooks, error) {
+	return &Webhooks{
+		db: db,
+	}, nil
+}
+
+func (c *Webhooks) AddWebhook(ctx context.Context, webhook models.WebhookDto,
+	secret string) (models.WebhookDto, error) {
+	sqlStatement := `INSERT INTO public.webhooks (url, event_types, secret, active, created_at, updated_at)
+					VALUES ($1, $2, $3, $4, now(), now())
+					RETURNING ` + webhookColumns
+
+	res, err := scanWebhook(c.db.DB.QueryRow(ctx, sqlStatement, webhook.URL, webhook.EventTypes, secret,
+		webhook.Active))
+	if err != nil {
+		return models.WebhookDto{}, fmt.Errorf("error adding to DB %w", err)
+	}
+
+	return res, nil
+}
+
+func (c *Webhooks) GetWebhooks(ctx context.Context) ([]models.WebhookDto, error) {
+	sqlStatement := `SELECT ` + webhookColumns + ` FROM public.webhooks ORDER BY id`
+
+	rows, err := c.db.DB.Query(ctx, sqlStatement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	webhooks := make([]models.WebhookDto, 0)
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (c *Webhooks) GetWebhookByID(ctx context.Context, id string) (models.WebhookDto, error) {
+	sqlStatement := `SELECT ` + webhookColumns + ` FROM public.webhooks WHERE id = $1`
+
+	webhook, err := scanWebhook(c.db.DB.QueryRow(ctx, sqlStatement, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.WebhookDto{}, fmt.Errorf("webhook %w", models.ErrNotFound)
+		}
+
+		return models.WebhookDto{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	return webhook, nil
+}
+
+func (c *Webhooks) SetWebhook(ctx context.Context, id string, webhook models.WebhookDto) (models.WebhookDto, error) {
+	sqlStatement := `UPDATE public.webhooks SET url = $2, event_types = $3, active = $4, updated_at = now()
+					WHERE id = $1
+					RETURNING ` + webhookColumns
+
+	res, err := scanWebhook(c.db.DB.QueryRow(ctx, sqlStatement, id, webhook.URL, webhook.EventTypes, webhook.Active))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.WebhookDto{}, fmt.Errorf("webhook %w", models.ErrNotFound)
+		}
+
+		return models.WebhookDto{}, fmt.Errorf("error updating DB %w", err)
+	}
+
+	return res, nil
+}
+
+func (c *Webhooks) DeleteWebhook(ctx context.Context, id string) error {
+	result, err := c.db.DB.Exec(ctx, `DELETE FROM public.webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting from DB %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("webhook %w", models.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (c *Webhooks) AddWebhookDeliveries(ctx context.Context, event models.Event, payload []byte) (int64, error) {
+	sqlStatement := `INSERT INTO public.webhook_deliveries (webhook_id, event_id, event_type, payload)
+					SELECT id, $1, $2, $3 FROM public.webhooks WHERE active AND $2 = ANY (event_types)
+					ON CONFLICT (webhook_id, event_id) DO NOTHING`
+
+	result, err := c.db.DB.Exec(ctx, sqlStatement, event.ID, event.Type, payload)
+	if err != nil {
+		return 0, fmt.Errorf("error adding to DB %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (c *Webhooks) GetWebhookDeliveries(ctx context.Context, webhookID string,
+	params models.ListParams) (models.Page[models.WebhookDeliveryDto], error) {
+	query := listQuery{}
+	query.filter(`webhook_id = ` + query.arg(webhookID))
+
+	sqlStatement, err := query.build(`SELECT `+deliveryColumns+` FROM public.webhook_deliveries`, params)
+	if err != nil {
+		return models.Page[models.WebhookDeliveryDto]{}, err
+	}
+
+	rows, err := c.db.DB.Query(ctx, sqlStatement, query.args...)
+	if err != nil {
+		return models.Page[models.WebhookDeliveryDto]{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDeliveryDto, 0, params.Limit+1)
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return models.Page[models.WebhookDeliveryDto]{}, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return models.Page[models.WebhookDeliveryDto]{}, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	items, cursor := nextCursor(params, deliveries, func(delivery models.WebhookDeliveryDto) (string, string) {
+		return sortValue(params.Sort, "", delivery.Created, delivery.Updated), delivery.ID
+	})
+
+	return models.Page[models.WebhookDeliveryDto]{Items: items, NextCursor: cursor}, nil
+}
+
+func (c *Webhooks) GetWebhookDelivery(ctx context.Context, webhookID string,
+	id string) (models.WebhookDeliveryDto, error) {
+	sqlStatement := `SELECT ` + deliveryColumns + ` FROM public.webhook_deliveries WHERE webhook_id = $1 AND id = $2`
+
+	delivery, err := scanDelivery(c.db.DB.QueryRow(ctx, sqlStatement, webhookID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.WebhookDeliveryDto{}, fmt.Errorf("delivery %w", models.ErrNotFound)
+		}
+
+		return models.WebhookDeliveryDto{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	sqlStatement = `SELECT attempt, status_code, error, duration_ms, created_at FROM public.webhook_attempts
+					WHERE delivery_id = $1
+					ORDER BY attempt`
+
+	rows, err := c.db.DB.Query(ctx, sqlStatement, id)
+	if err != nil {
+		return models.WebhookDeliveryDto{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	delivery.History = make([]models.WebhookAttemptDto, 0, delivery.Attempts)
+
+	for rows.Next() {
+		var attempt models.WebhookAttemptDto
+
+		err = rows.Scan(&attempt.Attempt, &attempt.StatusCode, &attempt.Error, &attempt.Duration, &attempt.Created)
+		if err != nil {
+			return models.WebhookDeliveryDto{}, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		delivery.History = append(delivery.History, attempt)
+	}
+
+	if err = rows.Err(); err != nil {
+		return models.WebhookDeliveryDto{}, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	return delivery, nil
+}
+
+func (c *Webhooks) RedeliverWebhookDelivery(ctx context.Context, webhookID string,
+	id string) (models.WebhookDeliveryDto, error) {
+	sqlStatement := `UPDATE public.webhook_deliveries SET status = 'pending', next_attempt_at = now(), updated_at = now()
+					WHERE webhook_id = $1 AND id = $2
+					RETURNING ` + deliveryColumns
+
+	delivery, err := scanDelivery(c.db.DB.QueryRow(ctx, sqlStatement, webhookID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.WebhookDeliveryDto{}, fmt.Errorf("delivery %w", models.ErrNotFound)
+		}
+
+		return models.WebhookDeliveryDto{}, fmt.Errorf("error updating DB %w", err)
+	}
+
+	return delivery, nil
+}
+
+// ClaimWebhookDeliveries pushes the next attempt of the claimed deliveries past the lease; SKIP LOCKED keeps
+// concurrent deliverers from waiting on each other's claims.
+func (c *Webhooks) ClaimWebhookDeliveries(ctx context.Context, limit int,
+	lease time.Duration) ([]models.WebhookDelivery, error) {
+	sqlStatement := `WITH claimed AS (
+						UPDATE public.webhook_deliveries SET next_attempt_at = now() + make_interval(secs => $2)
+						WHERE id IN (
+							SELECT d.id FROM public.webhook_deliveries d
+							JOIN public.webhooks w ON w.id = d.webhook_id
+							WHERE d.status = 'pending' AND d.next_attempt_at <= now() AND w.active
+							ORDER BY d.id LIMIT $1
+							FOR UPDATE OF d SKIP LOCKED
+						)
+						RETURNING ` + deliveryColumns + `, payload
+					)
+					SELECT ` + claimedColumns + `, d.payload, w.url, w.secret FROM claimed d
+					JOIN public.webhooks w ON w.id = d.webhook_id
+					ORDER BY d.id`
+
+	rows, err := c.db.DB.Query(ctx, sqlStatement, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0, limit)
+
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+
+		delivery.WebhookDeliveryDto, err = scanDelivery(rows, &delivery.Payload, &delivery.URL, &delivery.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (c *Webhooks) RecordWebhookAttempt(ctx context.Context, id string, attempt models.WebhookAttemptDto,
+	retryAt *time.Time) error {
+	return c.db.inTx(ctx, func(tx pgx.Tx) error {
+		sqlStatement := `UPDATE public.webhook_deliveries SET attempts = attempts + 1, last_error = $2, status = $3,
+							next_attempt_at = COALESCE($4, next_attempt_at), updated_at = now()
+						WHERE id = $1
+						RETURNING attempts`
+
+		var attempts int
+
+		err := tx.QueryRow(ctx, sqlStatement, id, attempt.Error, attempt.DeliveryStatus(retryAt), retryAt).
+			Scan(&attempts)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("delivery %w", models.ErrNotFound)
+			}
+
+			return fmt.Errorf("error updating DB %w", err)
+		}
+
+		sqlStatement = `INSERT INTO public.webhook_attempts (delivery_id, attempt, status_code, error, duration_ms)
+						VALUES ($1, $2, $3, $4, $5)`
+
+		_, err = tx.Exec(ctx, sqlStatement, id, attempts, attempt.StatusCode, attempt.Error, attempt.Duration)
+		if err != nil {
+			return fmt.Errorf("error adding to DB %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (c *Webhooks) DeleteWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	sqlStatement := `DELETE FROM public.webhook_deliveries WHERE status <> 'pending' AND updated_at < $1`
+
+	result, err := c.db.DB.Exec(ctx, sqlStatement, before)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting from DB %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func scanWebhook(row pgx.Row) (webhook models.WebhookDto, err error) {
+	err = row.Scan(&webhook.ID, &webhook.URL, &webhook.EventTypes, &webhook.Active, &webhook.Created,
+		&webhook.Updated)
+
+	return webhook, err
+}
+
+// scanDelivery scans the delivery columns followed by extra ones; only a pending delivery has a next attempt.
+func scanDelivery(row pgx.Row, extra ...any) (delivery models.WebhookDeliveryDto, err error) {
+	var nextAttempt time.Time
+
+	dest := append([]any{&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType,
+		&delivery.Status, &delivery.Attempts, &delivery.LastError, &nextAttempt, &delivery.Created,
+		&delivery.Updated}, extra...)
+
+	if err = row.Scan(dest...); err != nil {
+		return delivery, err
+	}
+
+	if delivery.Status == models.DeliveryPending {
+		delivery.NextAttempt = &nextAttempt
+	}
+
+	return delivery, nil
+}
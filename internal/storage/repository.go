@@ -2,19 +2,122 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"time"
 	"tradeservice/internal/models"
 )
 
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	// DriverMemory keeps the data in process memory only, for tests and local development.
+	DriverMemory = "memory"
+)
+
+var ErrDriver = errors.New("unknown storage driver")
+
 type CategoryRepository interface {
-	AddCategory(ctx context.Context, name string, productID string) (id string, err error)
-	GetCategory(ctx context.Context) ([]models.CategoryDto, error)
-	SetCategory(ctx context.Context, id string, name string) error
+	AddCategory(ctx context.Context, category models.CategoryDto) (models.CategoryDto, error)
+	GetCategory(ctx context.Context, params models.ListParams) (models.Page[models.CategoryDto], error)
+	GetCategoryByID(ctx context.Context, id string) (models.CategoryDto, error)
+	SetCategory(ctx context.Context, id string, name string) (models.CategoryDto, error)
+	GetCategorySubtree(ctx context.Context, id string) ([]models.CategoryDto, error)
+	GetCategoryAncestors(ctx context.Context, id string) ([]models.CategoryDto, error)
+	MoveCategory(ctx context.Context, id string, parentID *string) (models.CategoryDto, error)
+	AssignProduct(ctx context.Context, categoryID string, productID string) error
+	AddCategoryForProduct(ctx context.Context, name string, productID string) (models.CategoryDto, error)
+	UnassignProduct(ctx context.Context, categoryID string, productID string) error
+	GetProductCategories(ctx context.Context, productID string) ([]models.CategoryDto, error)
 	DeleteCategory(ctx context.Context, id string) error
 }
 
 type ProductRepository interface {
-	AddProduct(ctx context.Context, name string) (id string, err error)
-	GetProduct(ctx context.Context) ([]models.ProductDto, error)
-	SetProduct(ctx context.Context, id string, name string) error
+	AddProduct(ctx context.Context, product models.ProductDto) (models.ProductDto, error)
+	GetProduct(ctx context.Context, params models.ListParams) (models.Page[models.ProductDto], error)
+	GetProductByID(ctx context.Context, id string) (models.ProductDto, error)
+	SetProduct(ctx context.Context, id string, product models.ProductDto) (models.ProductDto, error)
+	PatchProduct(ctx context.Context, id string, patch models.ProductPatch) (models.ProductDto, error)
 	DeleteProduct(ctx context.Context, id string) error
 }
+
+type InventoryRepository interface {
+	GetStockLevel(ctx context.Context, productID string) (models.StockLevelDto, error)
+	RecordMovement(ctx context.Context, movement models.StockMovementDto) (models.StockMovementDto, error)
+	GetStockMovements(ctx context.Context, productID string, params models.ListParams) (models.Page[models.StockMovementDto], error)
+	Reserve(ctx context.Context, productID string, quantity int64) (models.StockLevelDto, error)
+	Release(ctx context.Context, productID string, quantity int64) (models.StockLevelDto, error)
+}
+
+type OrderRepository interface {
+	AddOrder(ctx context.Context, order models.OrderDto) (models.OrderDto, error)
+	GetOrder(ctx context.Context, params models.ListParams) (models.Page[models.OrderDto], error)
+	GetOrderByID(ctx context.Context, id string) (models.OrderDto, error)
+	SetOrderStatus(ctx context.Context, id string, from string, to string) (models.OrderDto, error)
+}
+
+type APIKeyRepository interface {
+	AddAPIKey(ctx context.Context, key models.APIKeyDto, hash []byte) (models.APIKeyDto, error)
+	GetAPIKeys(ctx context.Context) ([]models.APIKeyDto, error)
+	GetAPIKeyByHash(ctx context.Context, hash []byte) (models.APIKeyDto, error)
+	RotateAPIKey(ctx context.Context, id string, prefix string, hash []byte) (models.APIKeyDto, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// OutboxRepository hands the events recorded by the catalog writes over to the dispatcher.
+type OutboxRepository interface {
+	// ClaimEvents leases up to limit due events, oldest first, so that other dispatchers skip them for lease.
+	// An event whose delivery isn't recorded within the lease is claimed again.
+	ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	MarkEventDelivered(ctx context.Context, id string) error
+	// MarkEventFailed counts a failed delivery attempt and makes the event due again at retryAt.
+	MarkEventFailed(ctx context.Context, id string, retryAt time.Time, reason string) error
+	// DeleteDeliveredEvents drops the events delivered before before and returns how many it dropped.
+	DeleteDeliveredEvents(ctx context.Context, before time.Time) (int64, error)
+}
+
+// WebhookRepository keeps the webhook subscriptions and the queue of their deliveries.
+type WebhookRepository interface {
+	AddWebhook(ctx context.Context, webhook models.WebhookDto, secret string) (models.WebhookDto, error)
+	GetWebhooks(ctx context.Context) ([]models.WebhookDto, error)
+	GetWebhookByID(ctx context.Context, id string) (models.WebhookDto, error)
+	// SetWebhook replaces the URL, event types and active flag of a webhook.
+	SetWebhook(ctx context.Context, id string, webhook models.WebhookDto) (models.WebhookDto, error)
+	// DeleteWebhook drops a webhook together with its deliveries.
+	DeleteWebhook(ctx context.Context, id string) error
+	// AddWebhookDeliveries queues a delivery of payload, the JSON of event, to every active webhook subscribed
+	// to its type and returns how many it queued. An event is queued once per webhook however often it is added.
+	AddWebhookDeliveries(ctx context.Context, event models.Event, payload []byte) (int64, error)
+	// GetWebhookDeliveries lists a page of the deliveries of a webhook.
+	GetWebhookDeliveries(ctx context.Context, webhookID string,
+		params models.ListParams) (models.Page[models.WebhookDeliveryDto], error)
+	// GetWebhookDelivery returns a delivery of a webhook with the history of its attempts.
+	GetWebhookDelivery(ctx context.Context, webhookID string, id string) (models.WebhookDeliveryDto, error)
+	// RedeliverWebhookDelivery makes a delivery of a webhook pending and due right away, whatever its status.
+	RedeliverWebhookDelivery(ctx context.Context, webhookID string, id string) (models.WebhookDeliveryDto, error)
+	// ClaimWebhookDeliveries leases up to limit due pending deliveries of active webhooks, oldest first, like
+	// OutboxRepository.ClaimEvents.
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	// RecordWebhookAttempt adds attempt to the history of a delivery. A delivery whose attempt has no error is
+	// delivered; otherwise it is due again at retryAt, or failed for good when retryAt is nil.
+	RecordWebhookAttempt(ctx context.Context, id string, attempt models.WebhookAttemptDto, retryAt *time.Time) error
+	// DeleteWebhookDeliveries drops the delivered and failed deliveries last updated before before and returns
+	// how many it dropped.
+	DeleteWebhookDeliveries(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Repositories are the repositories of one storage backend, selected with DB_DRIVER.
+type Repositories struct {
+	Categories CategoryRepository
+	Products   ProductRepository
+	Inventory  InventoryRepository
+	Orders     OrderRepository
+	APIKeys    APIKeyRepository
+	Outbox     OutboxRepository
+	Webhooks   WebhookRepository
+}
+
+// Closer releases the resources of a storage backend, e.g. its connection pool.
+type Closer interface {
+	Close()
+}
@@ -0,0 +1,181 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"tradeservice/internal/models"
+)
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at, updated_at`
+
+type APIKeys struct {
+	db *Storage
+}
+
+func NewAPIKeys(db *Storage) (*APIKeys, error) {
+	return &APIKeys{
+		db: db,
+	}, nil
+}
+
+func (c *APIKeys) AddAPIKey(ctx context.Context, key models.APIKeyDto, hash []byte) (models.APIKeyDto, error) {
+	scopes, err := marshalList(key.Scopes)
+	if err != nil {
+		return models.APIKeyDto{}, err
+	}
+
+	sqlStatement := `INSERT INTO api_keys (name, prefix, key_hash, scopes, expires_at, created_at, updated_at)
+					VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?6)
+					RETURNING ` + apiKeyColumns
+
+	res, err := scanAPIKey(c.db.DB.QueryRowContext(ctx, sqlStatement,
+		key.Name, key.Prefix, hash, scopes, micros(key.Expires), now().UnixMicro()))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.APIKeyDto{}, models.ErrUnique
+		}
+
+		return models.APIKeyDto{}, fmt.Errorf("error adding to DB %w", err)
+	}
+
+	return toAPIKeyDto(res), nil
+}
+
+func (c *APIKeys) GetAPIKeys(ctx context.Context) ([]models.APIKeyDto, error) {
+	sqlStatement := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id`
+
+	rows, err := c.db.DB.QueryContext(ctx, sqlStatement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	keys := make([]models.APIKeyDto, 0)
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		keys = append(keys, toAPIKeyDto(key))
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	return keys, nil
+}
+
+func (c *APIKeys) GetAPIKeyByHash(ctx context.Context, hash []byte) (models.APIKeyDto, error) {
+	sqlStatement := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ?1`
+
+	key, err := scanAPIKey(c.db.DB.QueryRowContext(ctx, sqlStatement, hash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.APIKeyDto{}, models.ErrNotFound
+		}
+
+		return models.APIKeyDto{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	return toAPIKeyDto(key), nil
+}
+
+// RotateAPIKey replaces the secret of a key that hasn't been revoked; the old secret stops working immediately.
+func (c *APIKeys) RotateAPIKey(ctx context.Context, id string, prefix string, hash []byte) (models.APIKeyDto, error) {
+	sqlStatement := `UPDATE api_keys SET prefix = ?2, key_hash = ?3, last_used_at = NULL, updated_at = ?4
+					WHERE id = ?1 AND revoked_at IS NULL
+					RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(c.db.DB.QueryRowContext(ctx, sqlStatement, id, prefix, hash, now().UnixMicro()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.APIKeyDto{}, fmt.Errorf("api key %w", models.ErrNotFound)
+		}
+
+		if isUniqueViolation(err) {
+			return models.APIKeyDto{}, models.ErrUnique
+		}
+
+		return models.APIKeyDto{}, fmt.Errorf("error updating DB %w", err)
+	}
+
+	return toAPIKeyDto(key), nil
+}
+
+func (c *APIKeys) RevokeAPIKey(ctx context.Context, id string) error {
+	sqlStatement := `UPDATE api_keys SET revoked_at = ?2, updated_at = ?2
+					WHERE id = ?1 AND revoked_at IS NULL`
+
+	result, err := c.db.DB.ExecContext(ctx, sqlStatement, id, now().UnixMicro())
+	if err != nil {
+		return fmt.Errorf("error updating DB %w", err)
+	}
+
+	if err = requireRow(result); err != nil {
+		return fmt.Errorf("api key %w", err)
+	}
+
+	return nil
+}
+
+func (c *APIKeys) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	sqlStatement := `UPDATE api_keys SET last_used_at = ?2 WHERE id = ?1`
+
+	if _, err := c.db.DB.ExecContext(ctx, sqlStatement, id, usedAt.UnixMicro()); err != nil {
+		return fmt.Errorf("error updating DB %w", err)
+	}
+
+	return nil
+}
+
+// marshalList encodes values, e.g. scopes, as the JSON array they are stored as.
+func marshalList(values []string) (string, error) {
+	if values == nil {
+		values = []string{}
+	}
+
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode list %w", err)
+	}
+
+	return string(raw), nil
+}
+
+func scanAPIKey(row row) (key models.APIKey, err error) {
+	var scopes string
+
+	err = row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, &scopes, nullTimestamp{&key.Expires},
+		nullTimestamp{&key.LastUsed}, nullTimestamp{&key.Revoked}, timestamp{&key.Created}, timestamp{&key.Updated})
+	if err != nil {
+		return key, err
+	}
+
+	if err = json.Unmarshal([]byte(scopes), &key.Scopes); err != nil {
+		return key, fmt.Errorf("failed to decode scopes %w", err)
+	}
+
+	return key, nil
+}
+
+func toAPIKeyDto(key models.APIKey) models.APIKeyDto {
+	return models.APIKeyDto{
+		ID:       key.ID,
+		Name:     key.Name,
+		Prefix:   key.Prefix,
+		Scopes:   key.Scopes,
+		Expires:  key.Expires,
+		LastUsed: key.LastUsed,
+		Revoked:  key.Revoked,
+		Created:  key.Created,
+		Updated:  key.Updated,
+	}
+}
@@ -0,0 +1,528 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"tradeservice/internal/models"
+)
+
+const categoryColumns = `id, name, parent_id, created_at, updated_at`
+
+type Categories struct {
+	db *Storage
+}
+
+func NewCategories(db *Storage) (*Categories, error) {
+	return &Categories{
+		db: db,
+	}, nil
+}
+
+func (c *Categories) GetCategory(ctx context.Context, params models.ListParams) (models.Page[models.CategoryDto], error) {
+	query := listQuery{}
+
+	sqlStatement, err := query.build(`SELECT `+categoryColumns+` FROM categories`, params)
+	if err != nil {
+		return models.Page[models.CategoryDto]{}, err
+	}
+
+	categoryDto, err := c.queryCategories(ctx, sqlStatement, query.args...)
+	if err != nil {
+		return models.Page[models.CategoryDto]{}, err
+	}
+
+	items, cursor := nextCursor(params, categoryDto, func(cat models.CategoryDto) (string, string) {
+		return sortValue(params.Sort, cat.Name, cat.Created, cat.Updated), cat.ID
+	})
+
+	return models.Page[models.CategoryDto]{Items: items, NextCursor: cursor}, nil
+}
+
+func (c *Categories) GetCategoryByID(ctx context.Context, id string) (models.CategoryDto, error) {
+	sqlStatement := `SELECT ` + categoryColumns + ` FROM categories WHERE id = ?1`
+
+	cat, err := scanCategory(c.db.DB.QueryRowContext(ctx, sqlStatement, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.CategoryDto{}, models.ErrNotFound
+		}
+
+		return models.CategoryDto{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	return toCategoryDto(cat), nil
+}
+
+func (c *Categories) AddCategory(ctx context.Context, category models.CategoryDto) (models.CategoryDto, error) {
+	sqlStatement := `INSERT INTO categories (name, parent_id, created_at, updated_at)
+					VALUES (?1, ?2, ?3, ?3)
+					RETURNING ` + categoryColumns
+
+	var res models.CategoryDto
+
+	err := c.db.inTx(ctx, func(tx *sql.Tx) error {
+		cat, err := scanCategory(tx.QueryRowContext(ctx, sqlStatement,
+			category.Name, category.ParentID, now().UnixMicro()))
+		if err != nil {
+			if isUniqueViolation(err) {
+				return models.ErrUnique
+			}
+
+			if isForeignKeyViolation(err) {
+				return fmt.Errorf("parent category %w", models.ErrNotFound)
+			}
+
+			return fmt.Errorf("error adding to DB %w", err)
+		}
+
+		res = toCategoryDto(cat)
+
+		return addCategoryEvent(ctx, tx, res)
+	})
+	if err != nil {
+		return models.CategoryDto{}, err
+	}
+
+	return res, nil
+}
+
+// AddCategoryForProduct assigns the product to the top-level category called name in one transaction,
+// creating the category unless it exists.
+func (c *Categories) AddCategoryForProduct(ctx context.Context, name string,
+	productID string) (models.CategoryDto, error) {
+	selectStatement := `SELECT ` + categoryColumns + ` FROM categories WHERE parent_id IS NULL AND name = ?1`
+	insertStatement := `INSERT INTO categories (name, parent_id, created_at, updated_at)
+					VALUES (?1, NULL, ?2, ?2)
+					RETURNING ` + categoryColumns
+
+	var res models.CategoryDto
+
+	err := c.db.inTx(ctx, func(tx *sql.Tx) error {
+		cat, err := scanCategory(tx.QueryRowContext(ctx, selectStatement, name))
+
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if cat, err = scanCategory(tx.QueryRowContext(ctx, insertStatement, name, now().UnixMicro())); err != nil {
+				return fmt.Errorf("error adding to DB %w", err)
+			}
+
+			res = toCategoryDto(cat)
+
+			if err = addCategoryEvent(ctx, tx, res); err != nil {
+				return err
+			}
+		case err != nil:
+			return fmt.Errorf("failed to query DB %w", err)
+		default:
+			res = toCategoryDto(cat)
+		}
+
+		return assignProduct(ctx, tx, res.ID, productID)
+	})
+	if isForeignKeyViolation(err) {
+		return models.CategoryDto{}, fmt.Errorf("product %w", models.ErrNotFound)
+	}
+
+	if err != nil {
+		return models.CategoryDto{}, err
+	}
+
+	return res, nil
+}
+
+func addCategoryEvent(ctx context.Context, tx *sql.Tx, category models.CategoryDto) error {
+	event, err := models.NewEvent(models.EventCategoryCreated, category.ID, category)
+	if err != nil {
+		return err
+	}
+
+	return addEvents(ctx, tx, event)
+}
+
+func (c *Categories) DeleteCategory(ctx context.Context, id string) error {
+	sqlStatement := `DELETE FROM categories WHERE id = ?1`
+
+	return c.db.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, sqlStatement, id)
+		if err != nil {
+			if isForeignKeyViolation(err) {
+				return fmt.Errorf("%w: category has subcategories", models.ErrConflict)
+			}
+
+			return fmt.Errorf("error deleting from DB %w", err)
+		}
+
+		if err = requireRow(result); err != nil {
+			return err
+		}
+
+		event, err := models.NewEvent(models.EventCategoryDeleted, id, models.EventDeleteData{ID: id})
+		if err != nil {
+			return err
+		}
+
+		return addEvents(ctx, tx, event)
+	})
+}
+
+func (c *Categories) SetCategory(ctx context.Context, id string, name string) (models.CategoryDto, error) {
+	sqlStatement := `UPDATE categories SET name = ?1, updated_at = ?2 WHERE id = ?3
+					RETURNING ` + categoryColumns
+
+	var res models.CategoryDto
+
+	err := c.db.inTx(ctx, func(tx *sql.Tx) error {
+		old, err := getCategory(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		cat, err := scanCategory(tx.QueryRowContext(ctx, sqlStatement, name, now().UnixMicro(), id))
+		if err != nil {
+			if isUniqueViolation(err) {
+				return models.ErrUnique
+			}
+
+			return fmt.Errorf("error updating DB %w", err)
+		}
+
+		res = toCategoryDto(cat)
+
+		if res.Name == old.Name {
+			return nil
+		}
+
+		event, err := models.NewEvent(models.EventCategoryRenamed, id,
+			models.EventRenameData{ID: id, OldName: old.Name, Name: res.Name})
+		if err != nil {
+			return err
+		}
+
+		return addEvents(ctx, tx, event)
+	})
+	if err != nil {
+		return models.CategoryDto{}, err
+	}
+
+	return res, nil
+}
+
+// GetCategorySubtree returns the category and all of its descendants, parents before children.
+func (c *Categories) GetCategorySubtree(ctx context.Context, id string) ([]models.CategoryDto, error) {
+	sqlStatement := `WITH RECURSIVE subtree AS (
+						SELECT ` + categoryColumns + `, 0 AS depth FROM categories WHERE id = ?1
+						UNION ALL
+						SELECT c.id, c.name, c.parent_id, c.created_at, c.updated_at, s.depth + 1
+						FROM categories c JOIN subtree s ON c.parent_id = s.id
+					)
+					SELECT ` + categoryColumns + ` FROM subtree ORDER BY depth, name, id`
+
+	return c.queryTree(ctx, sqlStatement, id)
+}
+
+// GetCategoryAncestors returns the breadcrumb from the root category down to and including the category.
+func (c *Categories) GetCategoryAncestors(ctx context.Context, id string) ([]models.CategoryDto, error) {
+	sqlStatement := `WITH RECURSIVE ancestors AS (
+						SELECT ` + categoryColumns + `, 0 AS depth FROM categories WHERE id = ?1
+						UNION ALL
+						SELECT c.id, c.name, c.parent_id, c.created_at, c.updated_at, a.depth + 1
+						FROM categories c JOIN ancestors a ON c.id = a.parent_id
+					)
+					SELECT ` + categoryColumns + ` FROM ancestors ORDER BY depth DESC`
+
+	return c.queryTree(ctx, sqlStatement, id)
+}
+
+// MoveCategory reparents the category. SQLite runs one write transaction at a time, so the cycle
+// check and the update cannot interleave with another move.
+func (c *Categories) MoveCategory(ctx context.Context, id string, parentID *string) (models.CategoryDto, error) {
+	tx, err := c.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.CategoryDto{}, fmt.Errorf("failed to begin transaction %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	if parentID != nil {
+		if err = checkCategoryCycle(ctx, tx, id, *parentID); err != nil {
+			return models.CategoryDto{}, err
+		}
+	}
+
+	old, err := getCategory(ctx, tx, id)
+	if err != nil {
+		return models.CategoryDto{}, err
+	}
+
+	sqlStatement := `UPDATE categories SET parent_id = ?1, updated_at = ?2 WHERE id = ?3
+					RETURNING ` + categoryColumns
+
+	cat, err := scanCategory(tx.QueryRowContext(ctx, sqlStatement, parentID, now().UnixMicro(), id))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.CategoryDto{}, models.ErrUnique
+		}
+
+		return models.CategoryDto{}, fmt.Errorf("error updating DB %w", err)
+	}
+
+	if !sameID(old.ParentID, cat.ParentID) {
+		event, err := models.NewEvent(models.EventCategoryMoved, id,
+			models.EventMoveData{ID: id, OldParentID: old.ParentID, ParentID: cat.ParentID})
+		if err != nil {
+			return models.CategoryDto{}, err
+		}
+
+		if err = addEvents(ctx, tx, event); err != nil {
+			return models.CategoryDto{}, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.CategoryDto{}, fmt.Errorf("failed to commit transaction %w", err)
+	}
+
+	return toCategoryDto(cat), nil
+}
+
+// getCategory reads the category before an update in the transaction of the update.
+func getCategory(ctx context.Context, tx *sql.Tx, id string) (models.Category, error) {
+	sqlStatement := `SELECT ` + categoryColumns + ` FROM categories WHERE id = ?1`
+
+	cat, err := scanCategory(tx.QueryRowContext(ctx, sqlStatement, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Category{}, models.ErrNotFound
+		}
+
+		return models.Category{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	return cat, nil
+}
+
+func sameID(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// checkCategoryCycle walks up from the new parent and fails if it meets the category being moved.
+func checkCategoryCycle(ctx context.Context, tx *sql.Tx, id string, parentID string) error {
+	sqlStatement := `WITH RECURSIVE ancestors AS (
+						SELECT id, parent_id FROM categories WHERE id = ?1
+						UNION ALL
+						SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+					)
+					SELECT count(*), count(*) FILTER (WHERE id = ?2) FROM ancestors`
+
+	var found, cycles int
+
+	if err := tx.QueryRowContext(ctx, sqlStatement, parentID, id).Scan(&found, &cycles); err != nil {
+		return fmt.Errorf("failed to query DB %w", err)
+	}
+
+	if found == 0 {
+		return fmt.Errorf("parent category %w", models.ErrNotFound)
+	}
+
+	if cycles > 0 {
+		return fmt.Errorf("%w: category cannot be moved under itself or its descendant", models.ErrConflict)
+	}
+
+	return nil
+}
+
+func (c *Categories) AssignProduct(ctx context.Context, categoryID string, productID string) error {
+	err := c.db.inTx(ctx, func(tx *sql.Tx) error {
+		return assignProduct(ctx, tx, categoryID, productID)
+	})
+	if isForeignKeyViolation(err) {
+		// Outside of the transaction, which holds the only connection.
+		return c.missingReference(ctx, productID)
+	}
+
+	return err
+}
+
+// assignProduct links the product to the category. A missing product or category fails with the foreign key
+// violation, which the caller resolves outside of the transaction.
+func assignProduct(ctx context.Context, tx *sql.Tx, categoryID string, productID string) error {
+	sqlStatement := `INSERT INTO product_categories (product_id, category_id, created_at) VALUES (?1, ?2, ?3)
+					ON CONFLICT DO NOTHING`
+
+	result, err := tx.ExecContext(ctx, sqlStatement, productID, categoryID, now().UnixMicro())
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return err
+		}
+
+		return fmt.Errorf("error adding to DB %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read DB %w", err)
+	}
+
+	if affected == 0 {
+		return nil
+	}
+
+	return addAssignmentEvent(ctx, tx, models.EventProductAssigned, categoryID, productID)
+}
+
+// missingReference tells which side of a product assignment doesn't exist, as SQLite doesn't name the
+// failed foreign key.
+func (c *Categories) missingReference(ctx context.Context, productID string) error {
+	exists, err := productExists(ctx, c.db.DB, productID)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("product %w", models.ErrNotFound)
+	}
+
+	return fmt.Errorf("category %w", models.ErrNotFound)
+}
+
+func (c *Categories) UnassignProduct(ctx context.Context, categoryID string, productID string) error {
+	sqlStatement := `DELETE FROM product_categories WHERE product_id = ?1 AND category_id = ?2`
+
+	return c.db.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, sqlStatement, productID, categoryID)
+		if err != nil {
+			return fmt.Errorf("error deleting from DB %w", err)
+		}
+
+		if err = requireRow(result); err != nil {
+			return err
+		}
+
+		return addAssignmentEvent(ctx, tx, models.EventProductUnassigned, categoryID, productID)
+	})
+}
+
+func addAssignmentEvent(ctx context.Context, tx *sql.Tx, eventType string, categoryID string, productID string) error {
+	event, err := models.NewEvent(eventType, categoryID,
+		models.EventAssignmentData{CategoryID: categoryID, ProductID: productID})
+	if err != nil {
+		return err
+	}
+
+	return addEvents(ctx, tx, event)
+}
+
+func (c *Categories) GetProductCategories(ctx context.Context, productID string) ([]models.CategoryDto, error) {
+	exists, err := productExists(ctx, c.db.DB, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("product %w", models.ErrNotFound)
+	}
+
+	sqlStatement := `SELECT ` + categoryColumns + ` FROM categories
+					WHERE id IN (SELECT category_id FROM product_categories WHERE product_id = ?1)
+					ORDER BY name, id`
+
+	return c.queryCategories(ctx, sqlStatement, productID)
+}
+
+// queryTree is queryCategories for a category and its relatives, which fails when the category doesn't exist.
+func (c *Categories) queryTree(ctx context.Context, sqlStatement string, id string) ([]models.CategoryDto, error) {
+	categoryDto, err := c.queryCategories(ctx, sqlStatement, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(categoryDto) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return categoryDto, nil
+}
+
+func (c *Categories) queryCategories(ctx context.Context, sqlStatement string, args ...any) ([]models.CategoryDto, error) {
+	rows, err := c.db.DB.QueryContext(ctx, sqlStatement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	categoryDto := make([]models.CategoryDto, 0)
+
+	for rows.Next() {
+		cat, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		categoryDto = append(categoryDto, toCategoryDto(cat))
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	return categoryDto, nil
+}
+
+func productExists(ctx context.Context, db *sql.DB, productID string) (bool, error) {
+	var exists bool
+
+	err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = ?1)`, productID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	return exists, nil
+}
+
+func categoryExists(ctx context.Context, db *sql.DB, categoryID string) (bool, error) {
+	var exists bool
+
+	err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM categories WHERE id = ?1)`, categoryID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	return exists, nil
+}
+
+// requireRow turns a statement that changed no row into models.ErrNotFound.
+func requireRow(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read DB %w", err)
+	}
+
+	if affected == 0 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanCategory(row row) (cat models.Category, err error) {
+	err = row.Scan(&cat.ID, &cat.Name, &cat.ParentID, timestamp{&cat.Created}, timestamp{&cat.Updated})
+
+	return cat, err
+}
+
+func toCategoryDto(cat models.Category) models.CategoryDto {
+	return models.CategoryDto{
+		ID:       cat.ID,
+		Name:     cat.Name,
+		ParentID: cat.ParentID,
+		Created:  cat.Created,
+		Updated:  cat.Updated,
+	}
+}
@@ -0,0 +1,235 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"tradeservice/internal/models"
+)
+
+const (
+	stockLevelColumns    = `product_id, on_hand, reserved, updated_at`
+	stockMovementColumns = `id, product_id, movement_type, quantity, reason, on_hand_after, created_at`
+)
+
+type Inventory struct {
+	db *Storage
+}
+
+func NewInventory(db *Storage) (*Inventory, error) {
+	return &Inventory{
+		db: db,
+	}, nil
+}
+
+func (c *Inventory) GetStockLevel(ctx context.Context, productID string) (models.StockLevelDto, error) {
+	sqlStatement := `SELECT p.id, COALESCE(l.on_hand, 0), COALESCE(l.reserved, 0), COALESCE(l.updated_at, p.created_at)
+					FROM products p LEFT JOIN inventory_levels l ON l.product_id = p.id
+					WHERE p.id = ?1`
+
+	level, err := scanStockLevel(c.db.DB.QueryRowContext(ctx, sqlStatement, productID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.StockLevelDto{}, fmt.Errorf("product %w", models.ErrNotFound)
+		}
+
+		return models.StockLevelDto{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	return toStockLevelDto(level), nil
+}
+
+// RecordMovement applies movement.Quantity to the on-hand stock and appends it to the ledger in one
+// transaction. A movement that would take on-hand stock below the reserved quantity is rejected.
+func (c *Inventory) RecordMovement(ctx context.Context, movement models.StockMovementDto) (models.StockMovementDto, error) {
+	tx, err := c.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.StockMovementDto{}, fmt.Errorf("failed to begin transaction %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	updated := now().UnixMicro()
+
+	if err = ensureStockLevel(ctx, tx, movement.ProductID, updated); err != nil {
+		return models.StockMovementDto{}, err
+	}
+
+	sqlStatement := `UPDATE inventory_levels
+					SET on_hand = on_hand + ?2, reserved = reserved - ?4, updated_at = ?3
+					WHERE product_id = ?1 AND reserved >= ?4 AND on_hand + ?2 >= reserved - ?4
+					RETURNING ` + stockLevelColumns
+
+	level, err := scanStockLevel(tx.QueryRowContext(ctx, sqlStatement, movement.ProductID, movement.Quantity, updated,
+		movement.Released()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.StockMovementDto{}, fmt.Errorf("%w: insufficient stock", models.ErrConflict)
+		}
+
+		return models.StockMovementDto{}, fmt.Errorf("error updating DB %w", err)
+	}
+
+	sqlStatement = `INSERT INTO stock_movements
+					(product_id, movement_type, quantity, reason, on_hand_after, created_at)
+					VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+					RETURNING ` + stockMovementColumns
+
+	recorded, err := scanStockMovement(tx.QueryRowContext(ctx, sqlStatement,
+		movement.ProductID, movement.Type, movement.Quantity, movement.Reason, level.OnHand, updated))
+	if err != nil {
+		return models.StockMovementDto{}, fmt.Errorf("error adding to DB %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.StockMovementDto{}, fmt.Errorf("failed to commit transaction %w", err)
+	}
+
+	return toStockMovementDto(recorded), nil
+}
+
+func (c *Inventory) GetStockMovements(ctx context.Context, productID string,
+	params models.ListParams) (models.Page[models.StockMovementDto], error) {
+	exists, err := productExists(ctx, c.db.DB, productID)
+	if err != nil {
+		return models.Page[models.StockMovementDto]{}, err
+	}
+
+	if !exists {
+		return models.Page[models.StockMovementDto]{}, fmt.Errorf("product %w", models.ErrNotFound)
+	}
+
+	query := listQuery{}
+	query.filter(`product_id = ` + query.arg(productID))
+
+	sqlStatement, err := query.build(`SELECT `+stockMovementColumns+` FROM stock_movements`, params)
+	if err != nil {
+		return models.Page[models.StockMovementDto]{}, err
+	}
+
+	rows, err := c.db.DB.QueryContext(ctx, sqlStatement, query.args...)
+	if err != nil {
+		return models.Page[models.StockMovementDto]{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	movements := make([]models.StockMovementDto, 0, params.Limit+1)
+
+	for rows.Next() {
+		movement, err := scanStockMovement(rows)
+		if err != nil {
+			return models.Page[models.StockMovementDto]{}, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		movements = append(movements, toStockMovementDto(movement))
+	}
+
+	if err = rows.Err(); err != nil {
+		return models.Page[models.StockMovementDto]{}, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	items, cursor := nextCursor(params, movements, func(movement models.StockMovementDto) (string, string) {
+		return sortValue(params.Sort, "", movement.Created, movement.Created), movement.ID
+	})
+
+	return models.Page[models.StockMovementDto]{Items: items, NextCursor: cursor}, nil
+}
+
+func (c *Inventory) Reserve(ctx context.Context, productID string, quantity int64) (models.StockLevelDto, error) {
+	sqlStatement := `UPDATE inventory_levels SET reserved = reserved + ?2, updated_at = ?3
+					WHERE product_id = ?1 AND on_hand - reserved >= ?2
+					RETURNING ` + stockLevelColumns
+
+	return c.updateReserved(ctx, sqlStatement, productID, quantity)
+}
+
+func (c *Inventory) Release(ctx context.Context, productID string, quantity int64) (models.StockLevelDto, error) {
+	sqlStatement := `UPDATE inventory_levels SET reserved = reserved - ?2, updated_at = ?3
+					WHERE product_id = ?1 AND reserved >= ?2
+					RETURNING ` + stockLevelColumns
+
+	return c.updateReserved(ctx, sqlStatement, productID, quantity)
+}
+
+func (c *Inventory) updateReserved(ctx context.Context, sqlStatement string, productID string,
+	quantity int64) (models.StockLevelDto, error) {
+	tx, err := c.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.StockLevelDto{}, fmt.Errorf("failed to begin transaction %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	updated := now().UnixMicro()
+
+	if err = ensureStockLevel(ctx, tx, productID, updated); err != nil {
+		return models.StockLevelDto{}, err
+	}
+
+	level, err := scanStockLevel(tx.QueryRowContext(ctx, sqlStatement, productID, quantity, updated))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.StockLevelDto{}, fmt.Errorf("%w: insufficient stock", models.ErrConflict)
+		}
+
+		return models.StockLevelDto{}, fmt.Errorf("error updating DB %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.StockLevelDto{}, fmt.Errorf("failed to commit transaction %w", err)
+	}
+
+	return toStockLevelDto(level), nil
+}
+
+// ensureStockLevel creates the zero stock level of a product on its first movement.
+func ensureStockLevel(ctx context.Context, tx *sql.Tx, productID string, updated int64) error {
+	sqlStatement := `INSERT INTO inventory_levels (product_id, updated_at) VALUES (?1, ?2) ON CONFLICT DO NOTHING`
+
+	if _, err := tx.ExecContext(ctx, sqlStatement, productID, updated); err != nil {
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("product %w", models.ErrNotFound)
+		}
+
+		return fmt.Errorf("error adding to DB %w", err)
+	}
+
+	return nil
+}
+
+func scanStockLevel(row row) (level models.StockLevel, err error) {
+	err = row.Scan(&level.ProductID, &level.OnHand, &level.Reserved, timestamp{&level.Updated})
+
+	return level, err
+}
+
+func scanStockMovement(row row) (movement models.StockMovement, err error) {
+	err = row.Scan(&movement.ID, &movement.ProductID, &movement.Type, &movement.Quantity,
+		&movement.Reason, &movement.OnHandAfter, timestamp{&movement.Created})
+
+	return movement, err
+}
+
+func toStockLevelDto(level models.StockLevel) models.StockLevelDto {
+	return models.StockLevelDto{
+		ProductID: level.ProductID,
+		OnHand:    level.OnHand,
+		Reserved:  level.Reserved,
+		Available: level.OnHand - level.Reserved,
+		Updated:   level.Updated,
+	}
+}
+
+func toStockMovementDto(movement models.StockMovement) models.StockMovementDto {
+	return models.StockMovementDto{
+		ID:          movement.ID,
+		ProductID:   movement.ProductID,
+		Type:        movement.Type,
+		Quantity:    movement.Quantity,
+		Reason:      movement.Reason,
+		OnHandAfter: movement.OnHandAfter,
+		Created:     movement.Created,
+	}
+}
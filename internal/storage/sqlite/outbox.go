@@ -0,0 +1,132 @@
+package sqlite
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+	"tradeservice/internal/models"
+)
+
+const outboxColumns = `id, event_type, subject, data, attempts, last_error, created_at`
+
+type Outbox struct {
+	db *Storage
+}
+
+func NewOutbox(db *Storage) (*Outbox, error) {
+	return &Outbox{
+		db: db,
+	}, nil
+}
+
+// ClaimEvents pushes the next attempt of the claimed events past the lease.
+func (c *Outbox) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	sqlStatement := `UPDATE outbox_events SET next_attempt_at = ?3
+					WHERE id IN (
+						SELECT id FROM outbox_events
+						WHERE delivered_at IS NULL AND next_attempt_at <= ?1
+						ORDER BY id LIMIT ?2
+					)
+					RETURNING ` + outboxColumns
+
+	claimed := now()
+
+	rows, err := c.db.DB.QueryContext(ctx, sqlStatement, claimed.UnixMicro(), limit, claimed.Add(lease).UnixMicro())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	events := make([]models.OutboxEvent, 0, limit)
+	order := make(map[string]int64, limit)
+
+	for rows.Next() {
+		var (
+			event models.OutboxEvent
+			id    int64
+			data  string
+		)
+
+		err = rows.Scan(&id, &event.Type, &event.Subject, &data, &event.Attempts, &event.LastError,
+			timestamp{&event.Created})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		event.ID = fmt.Sprint(id)
+		event.Data = []byte(data)
+		order[event.ID] = id
+
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	// RETURNING doesn't follow the ORDER BY of the subquery.
+	slices.SortFunc(events, func(a models.OutboxEvent, b models.OutboxEvent) int {
+		return cmp.Compare(order[a.ID], order[b.ID])
+	})
+
+	return events, nil
+}
+
+func (c *Outbox) MarkEventDelivered(ctx context.Context, id string) error {
+	sqlStatement := `UPDATE outbox_events SET delivered_at = ?2 WHERE id = ?1`
+
+	result, err := c.db.DB.ExecContext(ctx, sqlStatement, id, now().UnixMicro())
+	if err != nil {
+		return fmt.Errorf("error updating DB %w", err)
+	}
+
+	return requireRow(result)
+}
+
+func (c *Outbox) MarkEventFailed(ctx context.Context, id string, retryAt time.Time, reason string) error {
+	sqlStatement := `UPDATE outbox_events SET attempts = attempts + 1, last_error = ?2, next_attempt_at = ?3
+					WHERE id = ?1`
+
+	result, err := c.db.DB.ExecContext(ctx, sqlStatement, id, reason, retryAt.UnixMicro())
+	if err != nil {
+		return fmt.Errorf("error updating DB %w", err)
+	}
+
+	return requireRow(result)
+}
+
+func (c *Outbox) DeleteDeliveredEvents(ctx context.Context, before time.Time) (int64, error) {
+	sqlStatement := `DELETE FROM outbox_events WHERE delivered_at < ?1`
+
+	result, err := c.db.DB.ExecContext(ctx, sqlStatement, before.UnixMicro())
+	if err != nil {
+		return 0, fmt.Errorf("error deleting from DB %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	return deleted, nil
+}
+
+// addEvents records the events of a write in its transaction.
+func addEvents(ctx context.Context, tx *sql.Tx, events ...models.Event) error {
+	sqlStatement := `INSERT INTO outbox_events (event_type, subject, data, next_attempt_at, created_at)
+					VALUES (?1, ?2, ?3, ?4, ?4)`
+
+	created := now().UnixMicro()
+
+	for _, event := range events {
+		if _, err := tx.ExecContext(ctx, sqlStatement, event.Type, event.Subject, string(event.Data), created); err != nil {
+			return fmt.Errorf("error adding event to DB %w", err)
+		}
+	}
+
+	return nil
+}
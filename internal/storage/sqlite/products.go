@@ -0,0 +1,247 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"tradeservice/internal/models"
+)
+
+const productColumns = `id, name, sku, description, unit_price, currency, active, created_at, updated_at`
+
+type Products struct {
+	db *Storage
+}
+
+func NewProducts(db *Storage) (*Products, error) {
+	return &Products{
+		db: db,
+	}, nil
+}
+
+func (c *Products) GetProduct(ctx context.Context, params models.ListParams) (models.Page[models.ProductDto], error) {
+	if params.CategoryID != "" {
+		exists, err := categoryExists(ctx, c.db.DB, params.CategoryID)
+		if err != nil {
+			return models.Page[models.ProductDto]{}, err
+		}
+
+		if !exists {
+			return models.Page[models.ProductDto]{}, fmt.Errorf("category %w", models.ErrNotFound)
+		}
+	}
+
+	query := listQuery{}
+
+	switch {
+	case params.CategoryID != "" && params.IncludeSubcategories:
+		query.filter(`id IN (SELECT product_id FROM product_categories WHERE category_id IN (
+						WITH RECURSIVE subtree AS (
+							SELECT id FROM categories WHERE id = ` + query.arg(params.CategoryID) + `
+							UNION ALL
+							SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+						)
+						SELECT id FROM subtree))`)
+	case params.CategoryID != "":
+		query.filter(`id IN (SELECT product_id FROM product_categories WHERE category_id = ` +
+			query.arg(params.CategoryID) + `)`)
+	}
+
+	sqlStatement, err := query.build(`SELECT `+productColumns+` FROM products`, params)
+	if err != nil {
+		return models.Page[models.ProductDto]{}, err
+	}
+
+	rows, err := c.db.DB.QueryContext(ctx, sqlStatement, query.args...)
+	if err != nil {
+		return models.Page[models.ProductDto]{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	productDto := make([]models.ProductDto, 0, params.Limit+1)
+
+	for rows.Next() {
+		prod, err := scanProduct(rows)
+		if err != nil {
+			return models.Page[models.ProductDto]{}, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		productDto = append(productDto, toProductDto(prod))
+	}
+
+	if err = rows.Err(); err != nil {
+		return models.Page[models.ProductDto]{}, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	items, cursor := nextCursor(params, productDto, func(prod models.ProductDto) (string, string) {
+		return sortValue(params.Sort, prod.Name, prod.Created, prod.Updated), prod.ID
+	})
+
+	return models.Page[models.ProductDto]{Items: items, NextCursor: cursor}, nil
+}
+
+func (c *Products) GetProductByID(ctx context.Context, id string) (models.ProductDto, error) {
+	sqlStatement := `SELECT ` + productColumns + ` FROM products WHERE id = ?1`
+
+	prod, err := scanProduct(c.db.DB.QueryRowContext(ctx, sqlStatement, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ProductDto{}, models.ErrNotFound
+		}
+
+		return models.ProductDto{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	return toProductDto(prod), nil
+}
+
+func (c *Products) AddProduct(ctx context.Context, product models.ProductDto) (models.ProductDto, error) {
+	sqlStatement := `INSERT INTO products
+					(name, sku, description, unit_price, currency, active, created_at, updated_at)
+					VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?7)
+					RETURNING ` + productColumns
+
+	var res models.ProductDto
+
+	err := c.db.inTx(ctx, func(tx *sql.Tx) error {
+		prod, err := scanProduct(tx.QueryRowContext(ctx, sqlStatement, product.Name, product.SKU,
+			product.Description, product.UnitPrice, product.Currency, product.Active, now().UnixMicro()))
+		if err != nil {
+			if isUniqueViolation(err) {
+				return models.ErrUnique
+			}
+
+			return fmt.Errorf("error adding to DB %w", err)
+		}
+
+		res = toProductDto(prod)
+
+		event, err := models.NewEvent(models.EventProductCreated, res.ID, res)
+		if err != nil {
+			return err
+		}
+
+		return addEvents(ctx, tx, event)
+	})
+	if err != nil {
+		return models.ProductDto{}, err
+	}
+
+	return res, nil
+}
+
+func (c *Products) DeleteProduct(ctx context.Context, id string) error {
+	sqlStatement := `DELETE FROM products WHERE id = ?1`
+
+	return c.db.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, sqlStatement, id)
+		if err != nil {
+			if isForeignKeyViolation(err) {
+				return fmt.Errorf("%w: product is referenced by orders", models.ErrConflict)
+			}
+
+			return fmt.Errorf("error deleting from DB %w", err)
+		}
+
+		if err = requireRow(result); err != nil {
+			return err
+		}
+
+		event, err := models.NewEvent(models.EventProductDeleted, id, models.EventDeleteData{ID: id})
+		if err != nil {
+			return err
+		}
+
+		return addEvents(ctx, tx, event)
+	})
+}
+
+func (c *Products) SetProduct(ctx context.Context, id string, product models.ProductDto) (models.ProductDto, error) {
+	sqlStatement := `UPDATE products
+					SET name = ?1, sku = ?2, description = ?3, unit_price = ?4, currency = ?5, active = ?6, updated_at = ?7
+					WHERE id = ?8
+					RETURNING ` + productColumns
+
+	return c.update(ctx, id, sqlStatement, product.Name, product.SKU,
+		product.Description, product.UnitPrice, product.Currency, product.Active, now().UnixMicro(), id)
+}
+
+func (c *Products) PatchProduct(ctx context.Context, id string, patch models.ProductPatch) (models.ProductDto, error) {
+	sqlStatement := `UPDATE products
+					SET name = COALESCE(?1, name),
+						sku = COALESCE(?2, sku),
+						description = COALESCE(?3, description),
+						unit_price = COALESCE(?4, unit_price),
+						currency = COALESCE(?5, currency),
+						active = COALESCE(?6, active),
+						updated_at = ?7
+					WHERE id = ?8
+					RETURNING ` + productColumns
+
+	return c.update(ctx, id, sqlStatement, patch.Name, patch.SKU,
+		patch.Description, patch.UnitPrice, patch.Currency, patch.Active, now().UnixMicro(), id)
+}
+
+// update runs an UPDATE of product id returning its columns and records the events of the change.
+func (c *Products) update(ctx context.Context, id string, sqlStatement string, args ...any) (models.ProductDto, error) {
+	var res models.ProductDto
+
+	err := c.db.inTx(ctx, func(tx *sql.Tx) error {
+		var oldName string
+
+		err := tx.QueryRowContext(ctx, `SELECT name FROM products WHERE id = ?1`, id).Scan(&oldName)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return models.ErrNotFound
+			}
+
+			return fmt.Errorf("failed to query DB %w", err)
+		}
+
+		prod, err := scanProduct(tx.QueryRowContext(ctx, sqlStatement, args...))
+		if err != nil {
+			if isUniqueViolation(err) {
+				return models.ErrUnique
+			}
+
+			return fmt.Errorf("error updating DB %w", err)
+		}
+
+		res = toProductDto(prod)
+
+		events, err := models.ProductUpdateEvents(res, oldName)
+		if err != nil {
+			return err
+		}
+
+		return addEvents(ctx, tx, events...)
+	})
+	if err != nil {
+		return models.ProductDto{}, err
+	}
+
+	return res, nil
+}
+
+func scanProduct(row row) (prod models.Product, err error) {
+	err = row.Scan(&prod.ID, &prod.Name, &prod.SKU, &prod.Description, &prod.UnitPrice, &prod.Currency,
+		&prod.Active, timestamp{&prod.Created}, timestamp{&prod.Updated})
+
+	return prod, err
+}
+
+func toProductDto(prod models.Product) models.ProductDto {
+	return models.ProductDto{
+		ID:          prod.ID,
+		Name:        prod.Name,
+		SKU:         prod.SKU,
+		Description: prod.Description,
+		UnitPrice:   prod.UnitPrice,
+		Currency:    prod.Currency,
+		Active:      prod.Active,
+		Created:     prod.Created,
+		Updated:     prod.Updated,
+	}
+}
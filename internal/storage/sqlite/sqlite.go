@@ -0,0 +1,153 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"tradeservice/internal/config"
+	"tradeservice/internal/models"
+
+	"github.com/pressly/goose/v3"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// pragmas enforce the foreign keys the schema relies on; they are off by default in SQLite.
+const pragmas = `_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)`
+
+type Storage struct {
+	DB *sql.DB
+}
+
+func New(dbConfig config.DBConfig) (*Storage, error) {
+	db, err := sql.Open("sqlite", "file:"+dbConfig.SQLitePath+"?"+pragmas)
+	if err != nil {
+		return nil, fmt.Errorf("error creating connection DB %w", models.ErrDBConnectionCreation)
+	}
+
+	// SQLite has a single writer. One connection serialises the transactions instead of failing them with
+	// SQLITE_BUSY, and it must not be closed when idle or an in-memory database would be lost.
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxIdleTime(0)
+	db.SetConnMaxLifetime(0)
+
+	if err = db.PingContext(context.Background()); err != nil {
+		_ = db.Close()
+
+		return nil, fmt.Errorf("error creating connection DB %w", models.ErrDBConnectionCreation)
+	}
+
+	return &Storage{DB: db}, nil
+}
+
+func (store *Storage) Ping(ctx context.Context) error {
+	if err := store.DB.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping DB %w", err)
+	}
+
+	return nil
+}
+
+func (store *Storage) Close() {
+	_ = store.DB.Close()
+}
+
+func (store *Storage) Dialect() goose.Dialect {
+	return goose.DialectSQLite3
+}
+
+func (store *Storage) SQLDB() *sql.DB {
+	return store.DB
+}
+
+// inTx runs fn in a transaction that is committed when fn succeeds.
+func (store *Storage) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := store.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction %w", err)
+	}
+
+	return nil
+}
+
+// row is satisfied by *sql.Row and *sql.Rows.
+type row interface {
+	Scan(dest ...any) error
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// isForeignKeyViolation reports a failed foreign key. Unlike postgres, SQLite doesn't name the constraint.
+func isForeignKeyViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+}
+
+// timestamp scans the microseconds since the Unix epoch that times are stored as.
+type timestamp struct {
+	t *time.Time
+}
+
+func (ts timestamp) Scan(src any) error {
+	micros, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("timestamp of type %T", src)
+	}
+
+	*ts.t = time.UnixMicro(micros)
+
+	return nil
+}
+
+// nullTimestamp is a timestamp that may be NULL.
+type nullTimestamp struct {
+	t **time.Time
+}
+
+func (ts nullTimestamp) Scan(src any) error {
+	if src == nil {
+		*ts.t = nil
+
+		return nil
+	}
+
+	var t time.Time
+	if err := (timestamp{&t}).Scan(src); err != nil {
+		return err
+	}
+
+	*ts.t = &t
+
+	return nil
+}
+
+// micros converts t to the stored representation; nil stays NULL.
+func micros(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+
+	return t.UnixMicro()
+}
+
+// now is truncated to the stored microsecond precision.
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
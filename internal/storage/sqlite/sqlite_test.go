@@ -0,0 +1,116 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"tradeservice/internal/config"
+	"tradeservice/internal/models"
+	"tradeservice/internal/server/utils"
+	"tradeservice/internal/storage"
+	"tradeservice/internal/storage/sqlite"
+	"tradeservice/internal/storage/storagetest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	storagetest.Run(t, func(t *testing.T) storage.Repositories {
+		t.Helper()
+
+		db, err := sqlite.New(config.DBConfig{SQLitePath: filepath.Join(t.TempDir(), "tradeservice.db")})
+		require.NoError(t, err)
+		t.Cleanup(db.Close)
+
+		require.NoError(t, storage.RunMigration(db, utils.NewTestLogger(), "../../migrations/sqlite"))
+
+		categories, err := sqlite.NewCategories(db)
+		require.NoError(t, err)
+
+		products, err := sqlite.NewProducts(db)
+		require.NoError(t, err)
+
+		inventory, err := sqlite.NewInventory(db)
+		require.NoError(t, err)
+
+		orders, err := sqlite.NewOrders(db)
+		require.NoError(t, err)
+
+		apiKeys, err := sqlite.NewAPIKeys(db)
+		require.NoError(t, err)
+
+		outbox, err := sqlite.NewOutbox(db)
+		require.NoError(t, err)
+
+		webhooks, err := sqlite.NewWebhooks(db)
+		require.NoError(t, err)
+
+		return storage.Repositories{
+			Categories: categories,
+			Products:   products,
+			Inventory:  inventory,
+			Orders:     orders,
+			APIKeys:    apiKeys,
+			Outbox:     outbox,
+			Webhooks:   webhooks,
+		}
+	})
+}
+
+func TestStockMovements_AppendOnly(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db, err := sqlite.New(config.DBConfig{SQLitePath: filepath.Join(t.TempDir(), "tradeservice.db")})
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	require.NoError(t, storage.RunMigration(db, utils.NewTestLogger(), "../../migrations/sqlite"))
+
+	products, err := sqlite.NewProducts(db)
+	require.NoError(t, err)
+
+	inventory, err := sqlite.NewInventory(db)
+	require.NoError(t, err)
+
+	product, err := products.AddProduct(ctx, models.ProductDto{Name: "bolt", SKU: "B-1", Currency: "USD"})
+	require.NoError(t, err)
+
+	_, err = inventory.RecordMovement(ctx, models.StockMovementDto{
+		ProductID: product.ID, Type: models.MovementReceipt, Quantity: 5,
+	})
+	require.NoError(t, err)
+
+	_, err = db.DB.ExecContext(ctx, `UPDATE stock_movements SET quantity = 6`)
+	require.ErrorContains(t, err, "append-only")
+
+	_, err = db.DB.ExecContext(ctx, `DELETE FROM stock_movements`)
+	require.ErrorContains(t, err, "append-only")
+
+	require.NoError(t, products.DeleteProduct(ctx, product.ID))
+}
+
+func TestMigrationCheck(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db, err := sqlite.New(config.DBConfig{SQLitePath: filepath.Join(t.TempDir(), "tradeservice.db")})
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	check, err := storage.NewMigrationCheck(db, "../../migrations/sqlite")
+	require.NoError(t, err)
+
+	require.Error(t, check(ctx))
+
+	require.NoError(t, storage.RunMigration(db, utils.NewTestLogger(), "../../migrations/sqlite"))
+	require.NoError(t, check(ctx))
+
+	_, err = db.DB.ExecContext(ctx, `DELETE FROM goose_db_version WHERE version_id = (SELECT max(version_id) FROM goose_db_version)`)
+	require.NoError(t, err)
+	require.ErrorIs(t, check(ctx), storage.ErrMigrationsPending)
+}
@@ -0,0 +1,395 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"tradeservice/internal/models"
+)
+
+const (
+	webhookColumns  = `id, url, event_types, active, created_at, updated_at`
+	deliveryColumns = `id, webhook_id, event_id, event_type, status, attempts, last_error, next_attempt_at, created_at,
+		updated_at`
+	claimedColumns = `d.id, d.webhook_id, d.event_id, d.event_type, d.status, d.attempts, d.last_error,
+		d.next_attempt_at, d.created_at, d.updated_at`
+)
+
+type Webhooks struct {
+	db *Storage
+}
+
+func NewWebhooks(db *Storage) (*Webhooks, error) {
+	return &Webhooks{
+		db: db,
+	}, nil
+}
+
+func (c *Webhooks) AddWebhook(ctx context.Context, webhook models.WebhookDto,
+	secret string) (models.WebhookDto, error) {
+	eventTypes, err := marshalList(webhook.EventTypes)
+	if err != nil {
+		return models.WebhookDto{}, err
+	}
+
+	sqlStatement := `INSERT INTO webhooks (url, event_types, secret, active, created_at, updated_at)
+					VALUES (?1, ?2, ?3, ?4, ?5, ?5)
+					RETURNING ` + webhookColumns
+
+	res, err := scanWebhook(c.db.DB.QueryRowContext(ctx, sqlStatement, webhook.URL, eventTypes, secret,
+		webhook.Active, now().UnixMicro()))
+	if err != nil {
+		return models.WebhookDto{}, fmt.Errorf("error adding to DB %w", err)
+	}
+
+	return res, nil
+}
+
+func (c *Webhooks) GetWebhooks(ctx context.Context) ([]models.WebhookDto, error) {
+	sqlStatement := `SELECT ` + webhookColumns + ` FROM webhooks ORDER BY id`
+
+	rows, err := c.db.DB.QueryContext(ctx, sqlStatement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	webhooks := make([]models.WebhookDto, 0)
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (c *Webhooks) GetWebhookByID(ctx context.Context, id string) (models.WebhookDto, error) {
+	sqlStatement := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ?1`
+
+	webhook, err := scanWebhook(c.db.DB.QueryRowContext(ctx, sqlStatement, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookDto{}, fmt.Errorf("webhook %w", models.ErrNotFound)
+		}
+
+		return models.WebhookDto{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	return webhook, nil
+}
+
+func (c *Webhooks) SetWebhook(ctx context.Context, id string, webhook models.WebhookDto) (models.WebhookDto, error) {
+	eventTypes, err := marshalList(webhook.EventTypes)
+	if err != nil {
+		return models.WebhookDto{}, err
+	}
+
+	sqlStatement := `UPDATE webhooks SET url = ?2, event_types = ?3, active = ?4, updated_at = ?5
+					WHERE id = ?1
+					RETURNING ` + webhookColumns
+
+	res, err := scanWebhook(c.db.DB.QueryRowContext(ctx, sqlStatement, id, webhook.URL, eventTypes, webhook.Active,
+		now().UnixMicro()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookDto{}, fmt.Errorf("webhook %w", models.ErrNotFound)
+		}
+
+		return models.WebhookDto{}, fmt.Errorf("error updating DB %w", err)
+	}
+
+	return res, nil
+}
+
+func (c *Webhooks) DeleteWebhook(ctx context.Context, id string) error {
+	result, err := c.db.DB.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting from DB %w", err)
+	}
+
+	if err = requireRow(result); err != nil {
+		return fmt.Errorf("webhook %w", err)
+	}
+
+	return nil
+}
+
+func (c *Webhooks) AddWebhookDeliveries(ctx context.Context, event models.Event, payload []byte) (int64, error) {
+	sqlStatement := `INSERT INTO webhook_deliveries
+						(webhook_id, event_id, event_type, payload, next_attempt_at, created_at, updated_at)
+					SELECT id, ?1, ?2, ?3, ?4, ?4, ?4 FROM webhooks
+					WHERE active AND EXISTS (SELECT 1 FROM json_each(event_types) WHERE value = ?2)
+					ON CONFLICT (webhook_id, event_id) DO NOTHING`
+
+	result, err := c.db.DB.ExecContext(ctx, sqlStatement, event.ID, event.Type, string(payload), now().UnixMicro())
+	if err != nil {
+		return 0, fmt.Errorf("error adding to DB %w", err)
+	}
+
+	added, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	return added, nil
+}
+
+func (c *Webhooks) GetWebhookDeliveries(ctx context.Context, webhookID string,
+	params models.ListParams) (models.Page[models.WebhookDeliveryDto], error) {
+	query := listQuery{}
+	query.filter(`webhook_id = ` + query.arg(webhookID))
+
+	sqlStatement, err := query.build(`SELECT `+deliveryColumns+` FROM webhook_deliveries`, params)
+	if err != nil {
+		return models.Page[models.WebhookDeliveryDto]{}, err
+	}
+
+	rows, err := c.db.DB.QueryContext(ctx, sqlStatement, query.args...)
+	if err != nil {
+		return models.Page[models.WebhookDeliveryDto]{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDeliveryDto, 0, params.Limit+1)
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return models.Page[models.WebhookDeliveryDto]{}, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return models.Page[models.WebhookDeliveryDto]{}, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	items, cursor := nextCursor(params, deliveries, func(delivery models.WebhookDeliveryDto) (string, string) {
+		return sortValue(params.Sort, "", delivery.Created, delivery.Updated), delivery.ID
+	})
+
+	return models.Page[models.WebhookDeliveryDto]{Items: items, NextCursor: cursor}, nil
+}
+
+func (c *Webhooks) GetWebhookDelivery(ctx context.Context, webhookID string,
+	id string) (models.WebhookDeliveryDto, error) {
+	sqlStatement := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = ?1 AND id = ?2`
+
+	delivery, err := scanDelivery(c.db.DB.QueryRowContext(ctx, sqlStatement, webhookID, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookDeliveryDto{}, fmt.Errorf("delivery %w", models.ErrNotFound)
+		}
+
+		return models.WebhookDeliveryDto{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	sqlStatement = `SELECT attempt, status_code, error, duration_ms, created_at FROM webhook_attempts
+					WHERE delivery_id = ?1
+					ORDER BY attempt`
+
+	rows, err := c.db.DB.QueryContext(ctx, sqlStatement, id)
+	if err != nil {
+		return models.WebhookDeliveryDto{}, fmt.Errorf("failed to query DB %w", err)
+	}
+
+	defer rows.Close()
+
+	delivery.History = make([]models.WebhookAttemptDto, 0, delivery.Attempts)
+
+	for rows.Next() {
+		var attempt models.WebhookAttemptDto
+
+		err = rows.Scan(&attempt.Attempt, &attempt.StatusCode, &attempt.Error, &attempt.Duration,
+			timestamp{&attempt.Created})
+		if err != nil {
+			return models.WebhookDeliveryDto{}, fmt.Errorf("failed to parse DB %w", err)
+		}
+
+		delivery.History = append(delivery.History, attempt)
+	}
+
+	if err = rows.Err(); err != nil {
+		return models.WebhookDeliveryDto{}, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	return delivery, nil
+}
+
+func (c *Webhooks) RedeliverWebhookDelivery(ctx context.Context, webhookID string,
+	id string) (models.WebhookDeliveryDto, error) {
+	sqlStatement := `UPDATE webhook_deliveries SET status = 'pending', next_attempt_at = ?3, updated_at = ?3
+					WHERE webhook_id = ?1 AND id = ?2
+					RETURNING ` + deliveryColumns
+
+	delivery, err := scanDelivery(c.db.DB.QueryRowContext(ctx, sqlStatement, webhookID, id, now().UnixMicro()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookDeliveryDto{}, fmt.Errorf("delivery %w", models.ErrNotFound)
+		}
+
+		return models.WebhookDeliveryDto{}, fmt.Errorf("error updating DB %w", err)
+	}
+
+	return delivery, nil
+}
+
+// ClaimWebhookDeliveries pushes the next attempt of the claimed deliveries past the lease. The single
+// connection serialises the transaction, so no other claim can see the deliveries before they are leased.
+func (c *Webhooks) ClaimWebhookDeliveries(ctx context.Context, limit int,
+	lease time.Duration) ([]models.WebhookDelivery, error) {
+	sqlStatement := `SELECT ` + claimedColumns + `, d.payload, w.url, w.secret FROM webhook_deliveries d
+					JOIN webhooks w ON w.id = d.webhook_id
+					WHERE d.status = 'pending' AND d.next_attempt_at <= ?1 AND w.active
+					ORDER BY d.id LIMIT ?2`
+
+	claimed := now()
+	leased := claimed.Add(lease)
+	deliveries := make([]models.WebhookDelivery, 0, limit)
+
+	err := c.db.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, sqlStatement, claimed.UnixMicro(), limit)
+		if err != nil {
+			return fmt.Errorf("failed to query DB %w", err)
+		}
+
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				delivery models.WebhookDelivery
+				payload  string
+			)
+
+			delivery.WebhookDeliveryDto, err = scanDelivery(rows, &payload, &delivery.URL, &delivery.Secret)
+			if err != nil {
+				return fmt.Errorf("failed to parse DB %w", err)
+			}
+
+			delivery.Payload = json.RawMessage(payload)
+			delivery.NextAttempt = &leased
+
+			deliveries = append(deliveries, delivery)
+		}
+
+		if err = rows.Err(); err != nil {
+			return fmt.Errorf("failed to read DB %w", err)
+		}
+
+		for _, delivery := range deliveries {
+			_, err = tx.ExecContext(ctx, `UPDATE webhook_deliveries SET next_attempt_at = ?2 WHERE id = ?1`,
+				delivery.ID, leased.UnixMicro())
+			if err != nil {
+				return fmt.Errorf("error updating DB %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (c *Webhooks) RecordWebhookAttempt(ctx context.Context, id string, attempt models.WebhookAttemptDto,
+	retryAt *time.Time) error {
+	return c.db.inTx(ctx, func(tx *sql.Tx) error {
+		sqlStatement := `UPDATE webhook_deliveries SET attempts = attempts + 1, last_error = ?2, status = ?3,
+							next_attempt_at = COALESCE(?4, next_attempt_at), updated_at = ?5
+						WHERE id = ?1
+						RETURNING attempts`
+
+		recorded := now().UnixMicro()
+
+		var attempts int
+
+		err := tx.QueryRowContext(ctx, sqlStatement, id, attempt.Error, attempt.DeliveryStatus(retryAt),
+			micros(retryAt), recorded).Scan(&attempts)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("delivery %w", models.ErrNotFound)
+			}
+
+			return fmt.Errorf("error updating DB %w", err)
+		}
+
+		sqlStatement = `INSERT INTO webhook_attempts (delivery_id, attempt, status_code, error, duration_ms, created_at)
+						VALUES (?1, ?2, ?3, ?4, ?5, ?6)`
+
+		_, err = tx.ExecContext(ctx, sqlStatement, id, attempts, attempt.StatusCode, attempt.Error, attempt.Duration,
+			recorded)
+		if err != nil {
+			return fmt.Errorf("error adding to DB %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (c *Webhooks) DeleteWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	sqlStatement := `DELETE FROM webhook_deliveries WHERE status <> 'pending' AND updated_at < ?1`
+
+	result, err := c.db.DB.ExecContext(ctx, sqlStatement, before.UnixMicro())
+	if err != nil {
+		return 0, fmt.Errorf("error deleting from DB %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read DB %w", err)
+	}
+
+	return deleted, nil
+}
+
+func scanWebhook(row row) (webhook models.WebhookDto, err error) {
+	var eventTypes string
+
+	err = row.Scan(&webhook.ID, &webhook.URL, &eventTypes, &webhook.Active, timestamp{&webhook.Created},
+		timestamp{&webhook.Updated})
+	if err != nil {
+		return webhook, err
+	}
+
+	if err = json.Unmarshal([]byte(eventTypes), &webhook.EventTypes); err != nil {
+		return webhook, fmt.Errorf("failed to decode event types %w", err)
+	}
+
+	return webhook, nil
+}
+
+// scanDelivery scans the delivery columns followed by extra ones; only a pending delivery has a next attempt.
+func scanDelivery(row row, extra ...any) (delivery models.WebhookDeliveryDto, err error) {
+	var nextAttempt time.Time
+
+	dest := append([]any{&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType,
+		&delivery.Status, &delivery.Attempts, &delivery.LastError, timestamp{&nextAttempt},
+		timestamp{&delivery.Created}, timestamp{&delivery.Updated}}, extra...)
+
+	if err = row.Scan(dest...); err != nil {
+		return delivery, err
+	}
+
+	if delivery.Status == models.DeliveryPending {
+		delivery.NextAttempt = &nextAttempt
+	}
+
+	return delivery, nil
+}
@@ -0,0 +1,1017 @@
+// Package storagetest is the conformance suite of the storage backends. Every backend must pass Run, so
+// the services behave the same whichever backend DB_DRIVER selects.
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+	"tradeservice/internal/models"
+	"tradeservice/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns the repositories of an empty backend. Run calls it once per test.
+type Factory func(t *testing.T) storage.Repositories
+
+var tests = []struct {
+	name string
+	test func(t *testing.T, repos storage.Repositories)
+}{
+	{"Products", testProducts},
+	{"ProductsUniqueSKU", testProductsUniqueSKU},
+	{"ProductsPagination", testProductsPagination},
+	{"ProductsCategoryFilter", testProductsCategoryFilter},
+	{"ProductsDeleteCascades", testProductsDeleteCascades},
+	{"Categories", testCategories},
+	{"CategoryTree", testCategoryTree},
+	{"ProductCategories", testProductCategories},
+	{"AddCategoryForProduct", testAddCategoryForProduct},
+	{"Inventory", testInventory},
+	{"Orders", testOrders},
+	{"APIKeys", testAPIKeys},
+	{"Events", testEvents},
+	{"Outbox", testOutbox},
+	{"Webhooks", testWebhooks},
+	{"WebhookDeliveries", testWebhookDeliveries},
+}
+
+// Run checks the repositories returned by newRepositories against the semantics of the postgres backend.
+func Run(t *testing.T, newRepositories Factory) {
+	t.Helper()
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.test(t, newRepositories(t))
+		})
+	}
+}
+
+func addProduct(t *testing.T, repos storage.Repositories, name string) models.ProductDto {
+	t.Helper()
+
+	product, err := repos.Products.AddProduct(context.Background(), models.ProductDto{
+		Name:      name,
+		SKU:       "SKU-" + name,
+		UnitPrice: 1250,
+		Currency:  "USD",
+		Active:    true,
+	})
+	require.NoError(t, err)
+
+	return product
+}
+
+func addCategory(t *testing.T, repos storage.Repositories, name string, parentID *string) models.CategoryDto {
+	t.Helper()
+
+	category, err := repos.Categories.AddCategory(context.Background(), models.CategoryDto{Name: name, ParentID: parentID})
+	require.NoError(t, err)
+
+	return category
+}
+
+func params(t *testing.T, raw models.ListParams) models.ListParams {
+	t.Helper()
+
+	res, err := raw.Normalize()
+	require.NoError(t, err)
+
+	return res
+}
+
+// collect follows the cursors from the first page to the last and returns the keys of all items.
+func collect[T any](t *testing.T, raw models.ListParams, list func(models.ListParams) (models.Page[T], error),
+	key func(T) string) []string {
+	t.Helper()
+
+	var keys []string
+
+	for page := 0; ; page++ {
+		require.Less(t, page, 100, "pagination doesn't end")
+
+		res, err := list(params(t, raw))
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(res.Items), raw.Limit)
+
+		for _, item := range res.Items {
+			keys = append(keys, key(item))
+		}
+
+		if res.NextCursor == "" {
+			return keys
+		}
+
+		raw.Cursor = res.NextCursor
+	}
+}
+
+func categoryNames(categories []models.CategoryDto) []string {
+	names := make([]string, 0, len(categories))
+	for _, category := range categories {
+		names = append(names, category.Name)
+	}
+
+	return names
+}
+
+func testProducts(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	added := addProduct(t, repos, "lamp")
+	assert.NotEmpty(t, added.ID)
+	assert.Equal(t, "SKU-lamp", added.SKU)
+	assert.False(t, added.Created.IsZero())
+
+	got, err := repos.Products.GetProductByID(ctx, added.ID)
+	require.NoError(t, err)
+	assert.Equal(t, added, got)
+
+	set, err := repos.Products.SetProduct(ctx, added.ID, models.ProductDto{
+		Name: "desk lamp", SKU: "SKU-desk-lamp", Description: "LED", UnitPrice: 1500, Currency: "EUR",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, added.ID, set.ID)
+	assert.Equal(t, "desk lamp", set.Name)
+	assert.Equal(t, "EUR", set.Currency)
+	assert.False(t, set.Active)
+	assert.True(t, set.Created.Equal(added.Created))
+	assert.False(t, set.Updated.Before(added.Updated))
+
+	name := "floor lamp"
+	patched, err := repos.Products.PatchProduct(ctx, added.ID, models.ProductPatch{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "floor lamp", patched.Name)
+	assert.Equal(t, "SKU-desk-lamp", patched.SKU)
+	assert.Equal(t, int64(1500), patched.UnitPrice)
+
+	require.NoError(t, repos.Products.DeleteProduct(ctx, added.ID))
+
+	_, err = repos.Products.GetProductByID(ctx, added.ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = repos.Products.SetProduct(ctx, added.ID, models.ProductDto{Name: "lamp", SKU: "SKU-lamp", Currency: "USD"})
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = repos.Products.PatchProduct(ctx, added.ID, models.ProductPatch{Name: &name})
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	require.ErrorIs(t, repos.Products.DeleteProduct(ctx, added.ID), models.ErrNotFound)
+}
+
+func testProductsUniqueSKU(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	lamp := addProduct(t, repos, "lamp")
+	chair := addProduct(t, repos, "chair")
+
+	_, err := repos.Products.AddProduct(ctx, models.ProductDto{Name: "other", SKU: lamp.SKU, Currency: "USD"})
+	require.ErrorIs(t, err, models.ErrUnique)
+
+	_, err = repos.Products.SetProduct(ctx, chair.ID, models.ProductDto{Name: "chair", SKU: lamp.SKU, Currency: "USD"})
+	require.ErrorIs(t, err, models.ErrUnique)
+
+	_, err = repos.Products.PatchProduct(ctx, chair.ID, models.ProductPatch{SKU: &lamp.SKU})
+	require.ErrorIs(t, err, models.ErrUnique)
+
+	// A product keeps its own SKU.
+	_, err = repos.Products.PatchProduct(ctx, lamp.ID, models.ProductPatch{SKU: &lamp.SKU})
+	require.NoError(t, err)
+}
+
+func testProductsPagination(t *testing.T, repos storage.Repositories) {
+	var ids []string
+	for _, name := range []string{"cup", "apple", "egg", "bowl", "dish"} {
+		ids = append(ids, addProduct(t, repos, name).ID)
+	}
+
+	list := func(params models.ListParams) (models.Page[models.ProductDto], error) {
+		return repos.Products.GetProduct(context.Background(), params)
+	}
+	name := func(product models.ProductDto) string { return product.Name }
+	id := func(product models.ProductDto) string { return product.ID }
+
+	assert.Equal(t, []string{"apple", "bowl", "cup", "dish", "egg"},
+		collect(t, models.ListParams{Limit: 2, Sort: models.SortByName}, list, name))
+	assert.Equal(t, []string{"egg", "dish", "cup", "bowl", "apple"},
+		collect(t, models.ListParams{Limit: 2, Sort: "-" + models.SortByName}, list, name))
+	assert.Equal(t, ids, collect(t, models.ListParams{Limit: 3}, list, id))
+	assert.Equal(t, []string{"bowl"},
+		collect(t, models.ListParams{Limit: 2, Sort: models.SortByName, NamePrefix: "bo"}, list, name))
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	assert.Len(t, collect(t, models.ListParams{Limit: 10, CreatedAfter: &past}, list, id), len(ids))
+	assert.Empty(t, collect(t, models.ListParams{Limit: 10, CreatedAfter: &future}, list, id))
+
+	page, err := list(params(t, models.ListParams{Limit: 10}))
+	require.NoError(t, err)
+	assert.Empty(t, page.NextCursor)
+
+	malformed := models.EncodeCursor(models.Cursor{Sort: models.SortByCreatedAt, Value: "yesterday", ID: "1"})
+	_, err = list(params(t, models.ListParams{Limit: 10, Cursor: malformed}))
+	require.ErrorIs(t, err, models.ErrValidation)
+}
+
+func testProductsCategoryFilter(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	furniture := addCategory(t, repos, "furniture", nil)
+	chairs := addCategory(t, repos, "chairs", &furniture.ID)
+
+	table := addProduct(t, repos, "table")
+	stool := addProduct(t, repos, "stool")
+	addProduct(t, repos, "lamp")
+
+	require.NoError(t, repos.Categories.AssignProduct(ctx, furniture.ID, table.ID))
+	require.NoError(t, repos.Categories.AssignProduct(ctx, chairs.ID, stool.ID))
+
+	list := func(params models.ListParams) (models.Page[models.ProductDto], error) {
+		return repos.Products.GetProduct(ctx, params)
+	}
+	name := func(product models.ProductDto) string { return product.Name }
+
+	assert.Equal(t, []string{"table"}, collect(t, models.ListParams{
+		Limit: 10, Sort: models.SortByName, CategoryID: furniture.ID,
+	}, list, name))
+	assert.Equal(t, []string{"stool", "table"}, collect(t, models.ListParams{
+		Limit: 10, Sort: models.SortByName, CategoryID: furniture.ID, IncludeSubcategories: true,
+	}, list, name))
+	assert.Equal(t, []string{"stool"}, collect(t, models.ListParams{
+		Limit: 10, Sort: models.SortByName, CategoryID: chairs.ID, IncludeSubcategories: true,
+	}, list, name))
+
+	_, err := list(params(t, models.ListParams{CategoryID: "999999"}))
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func testProductsDeleteCascades(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	ordered := addProduct(t, repos, "ordered")
+	stocked := addProduct(t, repos, "stocked")
+	category := addCategory(t, repos, "misc", nil)
+
+	_, err := repos.Orders.AddOrder(ctx, models.OrderDto{
+		Status: models.OrderDraft, Currency: "USD", Total: 1250,
+		Lines: []models.OrderLineDto{{ProductID: ordered.ID, Quantity: 1, UnitPrice: 1250, LineTotal: 1250}},
+	})
+	require.NoError(t, err)
+
+	err = repos.Products.DeleteProduct(ctx, ordered.ID)
+	require.ErrorIs(t, err, models.ErrConflict)
+
+	_, err = repos.Inventory.RecordMovement(ctx, models.StockMovementDto{
+		ProductID: stocked.ID, Type: models.MovementReceipt, Quantity: 5,
+	})
+	require.NoError(t, err)
+	require.NoError(t, repos.Categories.AssignProduct(ctx, category.ID, stocked.ID))
+
+	require.NoError(t, repos.Products.DeleteProduct(ctx, stocked.ID))
+
+	_, err = repos.Inventory.GetStockLevel(ctx, stocked.ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = repos.Inventory.GetStockMovements(ctx, stocked.ID, params(t, models.ListParams{}))
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	page, err := repos.Products.GetProduct(ctx, params(t, models.ListParams{CategoryID: category.ID}))
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+}
+
+func testCategories(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	books := addCategory(t, repos, "books", nil)
+	assert.NotEmpty(t, books.ID)
+	assert.Nil(t, books.ParentID)
+
+	got, err := repos.Categories.GetCategoryByID(ctx, books.ID)
+	require.NoError(t, err)
+	assert.Equal(t, books, got)
+
+	_, err = repos.Categories.AddCategory(ctx, models.CategoryDto{Name: "books"})
+	require.ErrorIs(t, err, models.ErrUnique)
+
+	// Names are unique among siblings only.
+	fiction := addCategory(t, repos, "fiction", &books.ID)
+	music := addCategory(t, repos, "music", nil)
+	addCategory(t, repos, "fiction", &music.ID)
+
+	missing := "999999"
+	_, err = repos.Categories.AddCategory(ctx, models.CategoryDto{Name: "orphan", ParentID: &missing})
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	renamed, err := repos.Categories.SetCategory(ctx, fiction.ID, "novels")
+	require.NoError(t, err)
+	assert.Equal(t, "novels", renamed.Name)
+	assert.Equal(t, &books.ID, renamed.ParentID)
+
+	_, err = repos.Categories.SetCategory(ctx, music.ID, "books")
+	require.ErrorIs(t, err, models.ErrUnique)
+
+	_, err = repos.Categories.SetCategory(ctx, missing, "anything")
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	page, err := repos.Categories.GetCategory(ctx, params(t, models.ListParams{Sort: models.SortByName}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"books", "fiction", "music", "novels"}, categoryNames(page.Items))
+
+	require.ErrorIs(t, repos.Categories.DeleteCategory(ctx, books.ID), models.ErrConflict)
+	require.NoError(t, repos.Categories.DeleteCategory(ctx, renamed.ID))
+	require.NoError(t, repos.Categories.DeleteCategory(ctx, books.ID))
+	require.ErrorIs(t, repos.Categories.DeleteCategory(ctx, books.ID), models.ErrNotFound)
+
+	_, err = repos.Categories.GetCategoryByID(ctx, books.ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func testCategoryTree(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	root := addCategory(t, repos, "root", nil)
+	zeta := addCategory(t, repos, "zeta", &root.ID)
+	alpha := addCategory(t, repos, "alpha", &root.ID)
+	leaf := addCategory(t, repos, "leaf", &zeta.ID)
+	beta := addCategory(t, repos, "beta", &alpha.ID)
+	other := addCategory(t, repos, "other", nil)
+
+	subtree, err := repos.Categories.GetCategorySubtree(ctx, root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root", "alpha", "zeta", "beta", "leaf"}, categoryNames(subtree))
+
+	ancestors, err := repos.Categories.GetCategoryAncestors(ctx, leaf.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root", "zeta", "leaf"}, categoryNames(ancestors))
+
+	missing := "999999"
+
+	_, err = repos.Categories.GetCategorySubtree(ctx, missing)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = repos.Categories.GetCategoryAncestors(ctx, missing)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	for _, parent := range []string{zeta.ID, leaf.ID} {
+		_, err = repos.Categories.MoveCategory(ctx, zeta.ID, &parent)
+		require.ErrorIs(t, err, models.ErrConflict, "move under %s", parent)
+	}
+
+	_, err = repos.Categories.MoveCategory(ctx, zeta.ID, &missing)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = repos.Categories.MoveCategory(ctx, missing, &root.ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	moved, err := repos.Categories.MoveCategory(ctx, zeta.ID, &other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &other.ID, moved.ParentID)
+
+	ancestors, err = repos.Categories.GetCategoryAncestors(ctx, leaf.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"other", "zeta", "leaf"}, categoryNames(ancestors))
+
+	moved, err = repos.Categories.MoveCategory(ctx, zeta.ID, nil)
+	require.NoError(t, err)
+	assert.Nil(t, moved.ParentID)
+
+	// beta would clash with the beta it is moved next to.
+	addCategory(t, repos, "beta", &root.ID)
+
+	_, err = repos.Categories.MoveCategory(ctx, beta.ID, &root.ID)
+	require.ErrorIs(t, err, models.ErrUnique)
+}
+
+func testProductCategories(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	product := addProduct(t, repos, "kettle")
+	kitchen := addCategory(t, repos, "kitchen", nil)
+	appliances := addCategory(t, repos, "appliances", nil)
+
+	categories, err := repos.Categories.GetProductCategories(ctx, product.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, categories)
+	assert.Empty(t, categories)
+
+	require.NoError(t, repos.Categories.AssignProduct(ctx, kitchen.ID, product.ID))
+	require.NoError(t, repos.Categories.AssignProduct(ctx, kitchen.ID, product.ID))
+	require.NoError(t, repos.Categories.AssignProduct(ctx, appliances.ID, product.ID))
+
+	missing := "999999"
+	require.ErrorIs(t, repos.Categories.AssignProduct(ctx, kitchen.ID, missing), models.ErrNotFound)
+	require.ErrorIs(t, repos.Categories.AssignProduct(ctx, missing, product.ID), models.ErrNotFound)
+
+	categories, err = repos.Categories.GetProductCategories(ctx, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"appliances", "kitchen"}, categoryNames(categories))
+
+	require.NoError(t, repos.Categories.UnassignProduct(ctx, appliances.ID, product.ID))
+	require.ErrorIs(t, repos.Categories.UnassignProduct(ctx, appliances.ID, product.ID), models.ErrNotFound)
+
+	require.NoError(t, repos.Categories.DeleteCategory(ctx, kitchen.ID))
+
+	categories, err = repos.Categories.GetProductCategories(ctx, product.ID)
+	require.NoError(t, err)
+	assert.Empty(t, categories)
+
+	_, err = repos.Categories.GetProductCategories(ctx, missing)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func testAddCategoryForProduct(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	kettle := addProduct(t, repos, "kettle")
+	toaster := addProduct(t, repos, "toaster")
+	home := addCategory(t, repos, "home", nil)
+	addCategory(t, repos, "kitchen", &home.ID)
+
+	// A subcategory of the same name isn't reused.
+	kitchen, err := repos.Categories.AddCategoryForProduct(ctx, "kitchen", kettle.ID)
+	require.NoError(t, err)
+	assert.Nil(t, kitchen.ParentID)
+
+	reused, err := repos.Categories.AddCategoryForProduct(ctx, "kitchen", toaster.ID)
+	require.NoError(t, err)
+	assert.Equal(t, kitchen, reused)
+
+	// A missing product leaves no category behind.
+	_, err = repos.Categories.AddCategoryForProduct(ctx, "garden", "999999")
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	page, err := repos.Products.GetProduct(ctx, params(t, models.ListParams{
+		Sort: models.SortByName, CategoryID: kitchen.ID,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kettle", "toaster"}, []string{page.Items[0].Name, page.Items[1].Name})
+
+	categories, err := repos.Categories.GetCategory(ctx, params(t, models.ListParams{Sort: models.SortByName}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"home", "kitchen", "kitchen"}, categoryNames(categories.Items))
+}
+
+func testInventory(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	product := addProduct(t, repos, "bolt")
+
+	level, err := repos.Inventory.GetStockLevel(ctx, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, product.ID, level.ProductID)
+	assert.Zero(t, level.OnHand)
+	assert.True(t, level.Updated.Equal(product.Created))
+
+	receipt, err := repos.Inventory.RecordMovement(ctx, models.StockMovementDto{
+		ProductID: product.ID, Type: models.MovementReceipt, Quantity: 10, Reason: "delivery",
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, receipt.ID)
+	assert.Equal(t, int64(10), receipt.OnHandAfter)
+	assert.Equal(t, "delivery", receipt.Reason)
+
+	level, err = repos.Inventory.Reserve(ctx, product.ID, 4)
+	require.NoError(t, err)
+	assert.Equal(t, models.StockLevelDto{
+		ProductID: product.ID, OnHand: 10, Reserved: 4, Available: 6, Updated: level.Updated,
+	}, level)
+
+	_, err = repos.Inventory.Reserve(ctx, product.ID, 7)
+	require.ErrorIs(t, err, models.ErrConflict)
+
+	// Stock can't drop below what is reserved.
+	_, err = repos.Inventory.RecordMovement(ctx, models.StockMovementDto{
+		ProductID: product.ID, Type: models.MovementAdjustment, Quantity: -7,
+	})
+	require.ErrorIs(t, err, models.ErrConflict)
+
+	// A sale only takes reserved stock when it fulfils a reservation.
+	_, err = repos.Inventory.RecordMovement(ctx, models.StockMovementDto{
+		ProductID: product.ID, Type: models.MovementSale, Quantity: -7,
+	})
+	require.ErrorIs(t, err, models.ErrConflict)
+
+	sale, err := repos.Inventory.RecordMovement(ctx, models.StockMovementDto{
+		ProductID: product.ID, Type: models.MovementSale, Quantity: -3, FulfilsReservation: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), sale.OnHandAfter)
+
+	level, err = repos.Inventory.GetStockLevel(ctx, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), level.Reserved)
+
+	_, err = repos.Inventory.RecordMovement(ctx, models.StockMovementDto{
+		ProductID: product.ID, Type: models.MovementSale, Quantity: -2, FulfilsReservation: true,
+	})
+	require.ErrorIs(t, err, models.ErrConflict)
+
+	_, err = repos.Inventory.Release(ctx, product.ID, 2)
+	require.ErrorIs(t, err, models.ErrConflict)
+
+	level, err = repos.Inventory.Release(ctx, product.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), level.Available)
+
+	// With all stock reserved, an unrelated sale is refused and the reservations hold.
+	_, err = repos.Inventory.Reserve(ctx, product.ID, 7)
+	require.NoError(t, err)
+
+	_, err = repos.Inventory.RecordMovement(ctx, models.StockMovementDto{
+		ProductID: product.ID, Type: models.MovementSale, Quantity: -1,
+	})
+	require.ErrorIs(t, err, models.ErrConflict)
+
+	level, err = repos.Inventory.GetStockLevel(ctx, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), level.OnHand)
+	assert.Equal(t, int64(7), level.Reserved)
+
+	_, err = repos.Inventory.Release(ctx, product.ID, 7)
+	require.NoError(t, err)
+
+	unreserved, err := repos.Inventory.RecordMovement(ctx, models.StockMovementDto{
+		ProductID: product.ID, Type: models.MovementSale, Quantity: -5,
+	})
+	require.NoError(t, err)
+
+	level, err = repos.Inventory.GetStockLevel(ctx, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), level.OnHand)
+	assert.Zero(t, level.Reserved)
+
+	movements := collect(t, models.ListParams{Limit: 1, Sort: "-" + models.SortByCreatedAt},
+		func(params models.ListParams) (models.Page[models.StockMovementDto], error) {
+			return repos.Inventory.GetStockMovements(ctx, product.ID, params)
+		}, func(movement models.StockMovementDto) string { return movement.ID })
+	assert.Equal(t, []string{unreserved.ID, sale.ID, receipt.ID}, movements)
+
+	missing := "999999"
+
+	_, err = repos.Inventory.GetStockLevel(ctx, missing)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = repos.Inventory.RecordMovement(ctx, models.StockMovementDto{
+		ProductID: missing, Type: models.MovementReceipt, Quantity: 1,
+	})
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = repos.Inventory.Reserve(ctx, missing, 1)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = repos.Inventory.GetStockMovements(ctx, missing, params(t, models.ListParams{}))
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func testOrders(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	product := addProduct(t, repos, "pen")
+	lines := []models.OrderLineDto{{ProductID: product.ID, Quantity: 2, UnitPrice: 1250, LineTotal: 2500}}
+
+	_, err := repos.Orders.AddOrder(ctx, models.OrderDto{
+		Status: models.OrderDraft, Currency: "USD", Total: 2500,
+		Lines: append([]models.OrderLineDto{{ProductID: "999999", Quantity: 1}}, lines...),
+	})
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	page, err := repos.Orders.GetOrder(ctx, params(t, models.ListParams{}))
+	require.NoError(t, err)
+	assert.Empty(t, page.Items, "a rejected order must not be stored")
+
+	added, err := repos.Orders.AddOrder(ctx, models.OrderDto{
+		Status: models.OrderDraft, Currency: "USD", Total: 2500, Lines: lines,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, added.ID)
+	assert.Equal(t, lines, added.Lines)
+
+	got, err := repos.Orders.GetOrderByID(ctx, added.ID)
+	require.NoError(t, err)
+	assert.Equal(t, added, got)
+
+	page, err = repos.Orders.GetOrder(ctx, params(t, models.ListParams{}))
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, added.ID, page.Items[0].ID)
+	assert.Nil(t, page.Items[0].Lines)
+
+	placed, err := repos.Orders.SetOrderStatus(ctx, added.ID, models.OrderDraft, models.OrderPlaced)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderPlaced, placed.Status)
+	assert.Equal(t, lines, placed.Lines)
+
+	_, err = repos.Orders.SetOrderStatus(ctx, added.ID, models.OrderDraft, models.OrderCancelled)
+	require.ErrorIs(t, err, models.ErrConflict)
+
+	_, err = repos.Orders.SetOrderStatus(ctx, "999999", models.OrderDraft, models.OrderPlaced)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	_, err = repos.Orders.GetOrderByID(ctx, "999999")
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func testAPIKeys(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	keys, err := repos.APIKeys.GetAPIKeys(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, keys)
+	assert.Empty(t, keys)
+
+	var added []models.APIKeyDto
+
+	for i := range 3 {
+		key, err := repos.APIKeys.AddAPIKey(ctx, models.APIKeyDto{
+			Name: fmt.Sprintf("key %d", i), Prefix: fmt.Sprintf("pre%d", i), Scopes: []string{"products:read"},
+		}, fmt.Appendf(nil, "hash-%d", i))
+		require.NoError(t, err)
+		assert.Nil(t, key.Revoked)
+
+		added = append(added, key)
+	}
+
+	_, err = repos.APIKeys.AddAPIKey(ctx, models.APIKeyDto{Name: "copy", Prefix: "copy", Scopes: []string{}},
+		[]byte("hash-0"))
+	require.ErrorIs(t, err, models.ErrUnique)
+
+	keys, err = repos.APIKeys.GetAPIKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, added, keys)
+
+	got, err := repos.APIKeys.GetAPIKeyByHash(ctx, []byte("hash-1"))
+	require.NoError(t, err)
+	assert.Equal(t, added[1], got)
+
+	_, err = repos.APIKeys.GetAPIKeyByHash(ctx, []byte("unknown"))
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	usedAt := time.Now().Truncate(time.Second)
+	require.NoError(t, repos.APIKeys.TouchAPIKey(ctx, added[1].ID, usedAt))
+
+	got, err = repos.APIKeys.GetAPIKeyByHash(ctx, []byte("hash-1"))
+	require.NoError(t, err)
+	require.NotNil(t, got.LastUsed)
+	assert.True(t, got.LastUsed.Equal(usedAt))
+
+	_, err = repos.APIKeys.RotateAPIKey(ctx, added[1].ID, "new", []byte("hash-2"))
+	require.ErrorIs(t, err, models.ErrUnique)
+
+	rotated, err := repos.APIKeys.RotateAPIKey(ctx, added[1].ID, "new", []byte("hash-new"))
+	require.NoError(t, err)
+	assert.Equal(t, "new", rotated.Prefix)
+	assert.Nil(t, rotated.LastUsed)
+
+	_, err = repos.APIKeys.GetAPIKeyByHash(ctx, []byte("hash-1"))
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	require.NoError(t, repos.APIKeys.RevokeAPIKey(ctx, added[1].ID))
+	require.ErrorIs(t, repos.APIKeys.RevokeAPIKey(ctx, added[1].ID), models.ErrNotFound)
+	require.ErrorIs(t, repos.APIKeys.RevokeAPIKey(ctx, "999999"), models.ErrNotFound)
+
+	_, err = repos.APIKeys.RotateAPIKey(ctx, added[1].ID, "again", []byte("hash-again"))
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	got, err = repos.APIKeys.GetAPIKeyByHash(ctx, []byte("hash-new"))
+	require.NoError(t, err)
+	assert.NotNil(t, got.Revoked)
+}
+
+// claimAll claims every due event and returns them as "type subject".
+func claimAll(t *testing.T, repos storage.Repositories) ([]string, []models.OutboxEvent) {
+	t.Helper()
+
+	events, err := repos.Outbox.ClaimEvents(context.Background(), 100, time.Minute)
+	require.NoError(t, err)
+
+	described := make([]string, 0, len(events))
+	for _, event := range events {
+		described = append(described, event.Type+" "+event.Subject)
+	}
+
+	return described, events
+}
+
+func testEvents(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	lamp := addProduct(t, repos, "lamp")
+
+	name := "desk lamp"
+	_, err := repos.Products.PatchProduct(ctx, lamp.ID, models.ProductPatch{Name: &name})
+	require.NoError(t, err)
+
+	description := "adjustable"
+	_, err = repos.Products.PatchProduct(ctx, lamp.ID, models.ProductPatch{Description: &description})
+	require.NoError(t, err)
+
+	lighting := addCategory(t, repos, "lighting", nil)
+	home := addCategory(t, repos, "home", nil)
+
+	_, err = repos.Categories.SetCategory(ctx, lighting.ID, "lighting")
+	require.NoError(t, err)
+
+	_, err = repos.Categories.SetCategory(ctx, lighting.ID, "lights")
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err = repos.Categories.MoveCategory(ctx, lighting.ID, &home.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, repos.Categories.AssignProduct(ctx, lighting.ID, lamp.ID))
+	}
+
+	require.NoError(t, repos.Categories.UnassignProduct(ctx, lighting.ID, lamp.ID))
+	require.NoError(t, repos.Products.DeleteProduct(ctx, lamp.ID))
+	require.NoError(t, repos.Categories.DeleteCategory(ctx, lighting.ID))
+
+	// Failed writes record nothing.
+	require.ErrorIs(t, repos.Products.DeleteProduct(ctx, lamp.ID), models.ErrNotFound)
+	_, err = repos.Categories.AddCategory(ctx, models.CategoryDto{Name: "home"})
+	require.ErrorIs(t, err, models.ErrUnique)
+
+	described, events := claimAll(t, repos)
+	assert.Equal(t, []string{
+		models.EventProductCreated + " " + lamp.ID,
+		models.EventProductUpdated + " " + lamp.ID,
+		models.EventProductRenamed + " " + lamp.ID,
+		models.EventProductUpdated + " " + lamp.ID,
+		models.EventCategoryCreated + " " + lighting.ID,
+		models.EventCategoryCreated + " " + home.ID,
+		models.EventCategoryRenamed + " " + lighting.ID,
+		models.EventCategoryMoved + " " + lighting.ID,
+		models.EventProductAssigned + " " + lighting.ID,
+		models.EventProductUnassigned + " " + lighting.ID,
+		models.EventProductDeleted + " " + lamp.ID,
+		models.EventCategoryDeleted + " " + lighting.ID,
+	}, described)
+
+	var created models.ProductDto
+	require.NoError(t, json.Unmarshal(events[0].Data, &created))
+	assert.Equal(t, lamp.SKU, created.SKU)
+	assert.False(t, events[0].Created.IsZero())
+
+	var renamed models.EventRenameData
+	require.NoError(t, json.Unmarshal(events[2].Data, &renamed))
+	assert.Equal(t, models.EventRenameData{ID: lamp.ID, OldName: "lamp", Name: "desk lamp"}, renamed)
+
+	var moved models.EventMoveData
+	require.NoError(t, json.Unmarshal(events[7].Data, &moved))
+	assert.Nil(t, moved.OldParentID)
+	assert.Equal(t, &home.ID, moved.ParentID)
+
+	var assigned models.EventAssignmentData
+	require.NoError(t, json.Unmarshal(events[8].Data, &assigned))
+	assert.Equal(t, models.EventAssignmentData{CategoryID: lighting.ID, ProductID: lamp.ID}, assigned)
+}
+
+func testOutbox(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	first := addProduct(t, repos, "first")
+	second := addProduct(t, repos, "second")
+
+	claimed, err := repos.Outbox.ClaimEvents(ctx, 1, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, first.ID, claimed[0].Subject)
+	assert.Zero(t, claimed[0].Attempts)
+
+	// The first event is leased, so only the second is due.
+	described, events := claimAll(t, repos)
+	assert.Equal(t, []string{models.EventProductCreated + " " + second.ID}, described)
+
+	described, _ = claimAll(t, repos)
+	assert.Empty(t, described)
+
+	retryAt := time.Now().Add(-time.Hour)
+	require.NoError(t, repos.Outbox.MarkEventFailed(ctx, claimed[0].ID, retryAt, "connection refused"))
+	require.NoError(t, repos.Outbox.MarkEventFailed(ctx, events[0].ID, time.Now().Add(time.Hour), "timeout"))
+
+	_, retried := claimAll(t, repos)
+	require.Len(t, retried, 1)
+	assert.Equal(t, claimed[0].ID, retried[0].ID)
+	assert.Equal(t, 1, retried[0].Attempts)
+	assert.Equal(t, "connection refused", retried[0].LastError)
+
+	require.NoError(t, repos.Outbox.MarkEventDelivered(ctx, claimed[0].ID))
+	require.NoError(t, repos.Outbox.MarkEventFailed(ctx, claimed[0].ID, retryAt, "redelivered"))
+
+	described, _ = claimAll(t, repos)
+	assert.Empty(t, described, "a delivered event is never due again")
+
+	require.ErrorIs(t, repos.Outbox.MarkEventDelivered(ctx, "999999"), models.ErrNotFound)
+	require.ErrorIs(t, repos.Outbox.MarkEventFailed(ctx, "999999", retryAt, "lost"), models.ErrNotFound)
+
+	deleted, err := repos.Outbox.DeleteDeliveredEvents(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	deleted, err = repos.Outbox.DeleteDeliveredEvents(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	require.ErrorIs(t, repos.Outbox.MarkEventDelivered(ctx, claimed[0].ID), models.ErrNotFound)
+}
+
+func addWebhook(t *testing.T, repos storage.Repositories, url string, active bool,
+	eventTypes ...string) models.WebhookDto {
+	t.Helper()
+
+	webhook, err := repos.Webhooks.AddWebhook(context.Background(), models.WebhookDto{
+		URL:        url,
+		EventTypes: eventTypes,
+		Active:     active,
+	}, "secret-"+url)
+	require.NoError(t, err)
+
+	return webhook
+}
+
+func testWebhooks(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	first := addWebhook(t, repos, "https://first.example.com/hook", true, models.EventProductCreated)
+	second := addWebhook(t, repos, "https://second.example.com/hook", false, models.EventCategoryCreated,
+		models.EventCategoryDeleted)
+
+	assert.NotEmpty(t, first.ID)
+	assert.Equal(t, []string{models.EventProductCreated}, first.EventTypes)
+	assert.True(t, first.Active)
+	assert.False(t, first.Created.IsZero())
+
+	got, err := repos.Webhooks.GetWebhookByID(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, second.URL, got.URL)
+	assert.Equal(t, []string{models.EventCategoryCreated, models.EventCategoryDeleted}, got.EventTypes)
+	assert.False(t, got.Active)
+
+	webhooks, err := repos.Webhooks.GetWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 2)
+	assert.Equal(t, first.ID, webhooks[0].ID)
+	assert.Equal(t, second.ID, webhooks[1].ID)
+
+	set, err := repos.Webhooks.SetWebhook(ctx, first.ID, models.WebhookDto{
+		URL:        "https://first.example.com/v2",
+		EventTypes: []string{models.EventProductDeleted},
+		Active:     false,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, set.ID)
+	assert.Equal(t, "https://first.example.com/v2", set.URL)
+	assert.Equal(t, []string{models.EventProductDeleted}, set.EventTypes)
+	assert.False(t, set.Active)
+	assert.False(t, set.Updated.Before(first.Updated))
+
+	_, err = repos.Webhooks.SetWebhook(ctx, "999999", set)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	require.NoError(t, repos.Webhooks.DeleteWebhook(ctx, first.ID))
+	require.ErrorIs(t, repos.Webhooks.DeleteWebhook(ctx, first.ID), models.ErrNotFound)
+
+	_, err = repos.Webhooks.GetWebhookByID(ctx, first.ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func testWebhookDeliveries(t *testing.T, repos storage.Repositories) {
+	ctx := context.Background()
+
+	products := addWebhook(t, repos, "https://products.example.com", true, models.EventProductCreated,
+		models.EventProductDeleted)
+	all := addWebhook(t, repos, "https://all.example.com", true, models.EventTypes...)
+	addWebhook(t, repos, "https://inactive.example.com", false, models.EventTypes...)
+
+	lamp := addProduct(t, repos, "lamp")
+	_, events := claimAll(t, repos)
+	require.Len(t, events, 1)
+
+	event := events[0].Event
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	added, err := repos.Webhooks.AddWebhookDeliveries(ctx, event, payload)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), added)
+
+	added, err = repos.Webhooks.AddWebhookDeliveries(ctx, event, payload)
+	require.NoError(t, err)
+	assert.Zero(t, added, "an event is queued once per webhook")
+
+	renamed := models.Event{ID: "999999", Type: models.EventCategoryRenamed, Subject: lamp.ID}
+	added, err = repos.Webhooks.AddWebhookDeliveries(ctx, renamed, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), added)
+
+	claimed, err := repos.Webhooks.ClaimWebhookDeliveries(ctx, 1, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, products.ID, claimed[0].WebhookID)
+	assert.Equal(t, event.ID, claimed[0].EventID)
+	assert.Equal(t, models.EventProductCreated, claimed[0].EventType)
+	assert.Equal(t, models.DeliveryPending, claimed[0].Status)
+	assert.Equal(t, products.URL, claimed[0].URL)
+	assert.Equal(t, "secret-"+products.URL, claimed[0].Secret)
+	assert.JSONEq(t, string(payload), string(claimed[0].Payload))
+
+	// The first delivery is leased, so only the two of the other webhook are due.
+	leased, err := repos.Webhooks.ClaimWebhookDeliveries(ctx, 10, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, leased, 2)
+	assert.Equal(t, all.ID, leased[0].WebhookID)
+	assert.Equal(t, models.EventCategoryRenamed, leased[1].EventType)
+
+	none, err := repos.Webhooks.ClaimWebhookDeliveries(ctx, 10, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	retryAt := time.Now().Add(-time.Minute)
+	require.NoError(t, repos.Webhooks.RecordWebhookAttempt(ctx, claimed[0].ID,
+		models.WebhookAttemptDto{StatusCode: 503, Error: "unexpected status 503", Duration: 12}, &retryAt))
+
+	retried, err := repos.Webhooks.ClaimWebhookDeliveries(ctx, 10, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, retried, 1)
+	assert.Equal(t, claimed[0].ID, retried[0].ID)
+	assert.Equal(t, 1, retried[0].Attempts)
+	assert.Equal(t, "unexpected status 503", retried[0].LastError)
+
+	require.NoError(t, repos.Webhooks.RecordWebhookAttempt(ctx, claimed[0].ID,
+		models.WebhookAttemptDto{StatusCode: 204, Duration: 7}, nil))
+	require.NoError(t, repos.Webhooks.RecordWebhookAttempt(ctx, leased[0].ID,
+		models.WebhookAttemptDto{Error: "connection refused"}, nil))
+	require.ErrorIs(t, repos.Webhooks.RecordWebhookAttempt(ctx, "999999", models.WebhookAttemptDto{}, nil),
+		models.ErrNotFound)
+
+	delivered, err := repos.Webhooks.GetWebhookDelivery(ctx, products.ID, claimed[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryDelivered, delivered.Status)
+	assert.Equal(t, 2, delivered.Attempts)
+	assert.Nil(t, delivered.NextAttempt)
+	require.Len(t, delivered.History, 2)
+	assert.Equal(t, 1, delivered.History[0].Attempt)
+	assert.Equal(t, 503, delivered.History[0].StatusCode)
+	assert.Equal(t, "unexpected status 503", delivered.History[0].Error)
+	assert.Equal(t, int64(12), delivered.History[0].Duration)
+	assert.Equal(t, 2, delivered.History[1].Attempt)
+	assert.Equal(t, 204, delivered.History[1].StatusCode)
+	assert.Empty(t, delivered.History[1].Error)
+
+	_, err = repos.Webhooks.GetWebhookDelivery(ctx, all.ID, claimed[0].ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	newestFirst := models.ListParams{Limit: 10, Sort: "-" + models.SortByCreatedAt}
+
+	deliveries, err := repos.Webhooks.GetWebhookDeliveries(ctx, all.ID, params(t, newestFirst))
+	require.NoError(t, err)
+	require.Len(t, deliveries.Items, 2)
+	assert.Empty(t, deliveries.NextCursor)
+	assert.Equal(t, models.EventCategoryRenamed, deliveries.Items[0].EventType, "newest first")
+	assert.Equal(t, models.DeliveryPending, deliveries.Items[0].Status)
+	assert.NotNil(t, deliveries.Items[0].NextAttempt)
+	assert.Equal(t, models.DeliveryFailed, deliveries.Items[1].Status)
+	assert.Equal(t, "connection refused", deliveries.Items[1].LastError)
+	assert.Nil(t, deliveries.Items[1].History)
+
+	pageByPage := newestFirst
+	pageByPage.Limit = 1
+
+	assert.Equal(t, []string{deliveries.Items[0].ID, deliveries.Items[1].ID}, collect(t, pageByPage,
+		func(p models.ListParams) (models.Page[models.WebhookDeliveryDto], error) {
+			return repos.Webhooks.GetWebhookDeliveries(ctx, all.ID, p)
+		}, func(delivery models.WebhookDeliveryDto) string { return delivery.ID }))
+
+	redelivered, err := repos.Webhooks.RedeliverWebhookDelivery(ctx, products.ID, claimed[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryPending, redelivered.Status)
+	assert.Equal(t, 2, redelivered.Attempts)
+	assert.NotNil(t, redelivered.NextAttempt)
+
+	_, err = repos.Webhooks.RedeliverWebhookDelivery(ctx, all.ID, claimed[0].ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	again, err := repos.Webhooks.ClaimWebhookDeliveries(ctx, 10, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, again, 1)
+	assert.Equal(t, claimed[0].ID, again[0].ID)
+
+	deleted, err := repos.Webhooks.DeleteWebhookDeliveries(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	deleted, err = repos.Webhooks.DeleteWebhookDeliveries(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted, "only the failed delivery is finished")
+
+	require.NoError(t, repos.Webhooks.DeleteWebhook(ctx, products.ID))
+
+	deliveries, err = repos.Webhooks.GetWebhookDeliveries(ctx, products.ID, params(t, newestFirst))
+	require.NoError(t, err)
+	assert.Empty(t, deliveries.Items)
+}
@@ -0,0 +1,51 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tradeservice/internal/config"
+	"tradeservice/internal/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup_RejectsUnknownExporter(t *testing.T) {
+	t.Parallel()
+
+	_, err := tracing.Setup(context.Background(), config.TracingConfig{Exporter: "jaeger"})
+	require.ErrorIs(t, err, tracing.ErrExporter)
+}
+
+// Not parallel: it replaces the global tracer provider.
+func TestStartEnd_NestsSpansAndRecordsErrors(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	failing := func(ctx context.Context) (err error) {
+		_, span := tracing.Start(ctx, "child")
+		defer tracing.End(span, &err)
+
+		return errors.New("boom")
+	}
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	require.Error(t, failing(ctx))
+
+	var err error
+	tracing.End(parent, &err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	child, root := spans[0], spans[1]
+	assert.Equal(t, "child", child.Name())
+	assert.Equal(t, root.SpanContext().SpanID(), child.Parent().SpanID())
+	assert.Equal(t, codes.Error, child.Status().Code)
+	assert.Equal(t, codes.Unset, root.Status().Code)
+}